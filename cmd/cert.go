@@ -0,0 +1,294 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/go-acme/lego/v5/log"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+	"github.com/xhit/go-str2duration/v2"
+
+	"github.com/certimate-go/certimate/internal/certacme"
+	"github.com/certimate-go/certimate/internal/certmgmt"
+	"github.com/certimate-go/certimate/internal/domain"
+)
+
+func NewCertCommand(app core.App) *cobra.Command {
+	command := &cobra.Command{
+		Use:   "cert",
+		Short: "Obtains or deploys certificates without starting the server",
+	}
+
+	command.AddCommand(certObtainCommand(app))
+	command.AddCommand(certDeployCommand(app))
+
+	return command
+}
+
+func certObtainCommand(_ core.App) *cobra.Command {
+	var flagConfig string
+	var flagAccount string
+	var flagCertFile string
+	var flagKeyFile string
+	var flagVerbose bool
+
+	type ObtainConfig struct {
+		Domains               []string       `json:"domains"`
+		IPAddrs               []string       `json:"ipaddrs"`
+		ContactEmail          string         `json:"contactEmail"`
+		ChallengeType         string         `json:"challengeType"`
+		Provider              string         `json:"provider"`
+		ProviderAccess        map[string]any `json:"providerAccess"`
+		ProviderConfig        map[string]any `json:"providerConfig"`
+		CAProvider            string         `json:"caProvider"`
+		CAProviderAccess      map[string]any `json:"caProviderAccess"`
+		CAProviderConfig      map[string]any `json:"caProviderConfig"`
+		KeyAlgorithm          string         `json:"keyAlgorithm"`
+		ValidityLifetime      string         `json:"validityLifetime"`
+		PreferredChain        string         `json:"preferredChain"`
+		ACMEProfile           string         `json:"acmeProfile"`
+		Nameservers           []string       `json:"nameservers"`
+		DnsPropagationWait    int            `json:"dnsPropagationWait"`
+		DnsPropagationTimeout int            `json:"dnsPropagationTimeout"`
+		DnsTTL                int            `json:"dnsTTL"`
+		HttpDelayWait         int            `json:"httpDelayWait"`
+		DisableCommonName     bool           `json:"disableCommonName"`
+		DisableFollowCNAME    bool           `json:"disableFollowCNAME"`
+	}
+
+	command := &cobra.Command{
+		Use:   "obtain",
+		Short: "Obtains a certificate from an ACME CA",
+		Long: "Obtains a certificate from an ACME CA.\n" +
+			"The config file can be written in JSON or YAML, and its fields are the same as the workflow apply node, " +
+			"except that `providerAccess` and `caProviderAccess` contain the access config inline instead of referring to an access record.\n" +
+			"The ACME account is stored in a local file, and will be registered automatically if the file does not exist.",
+		Example:      "cert obtain --config ./obtain.yaml --account ./acme_account.json --certFile ./fullchain.pem --keyFile ./privkey.pem",
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			logger := newStandaloneLogger(flagVerbose)
+			log.SetDefault(logger.With(slog.String("logger", "go-acme/lego")))
+
+			config := &ObtainConfig{}
+			if err := readStandaloneConfigFile(flagConfig, config); err != nil {
+				return err
+			}
+			if len(config.Domains) == 0 && len(config.IPAddrs) == 0 {
+				return fmt.Errorf("missing domains or ipaddrs in config file")
+			}
+			if config.ContactEmail == "" {
+				return fmt.Errorf("missing contactEmail in config file")
+			}
+
+			validityNotAfter := time.Time{}
+			if config.ValidityLifetime != "" {
+				duration, err := str2duration.ParseDuration(config.ValidityLifetime)
+				if err != nil {
+					return fmt.Errorf("failed to parse validityLifetime in config file: %w", err)
+				} else if duration <= 0 {
+					return fmt.Errorf("invalid validityLifetime in config file: '%s'", config.ValidityLifetime)
+				}
+
+				validityNotAfter = time.Now().Add(duration)
+			}
+
+			providerAccessConfig, err := resolveStandaloneAccessConfig(cmd.Context(), config.ProviderAccess)
+			if err != nil {
+				return err
+			}
+
+			caAccessConfig, err := resolveStandaloneAccessConfig(cmd.Context(), config.CAProviderAccess)
+			if err != nil {
+				return err
+			}
+
+			keyAlgorithm := domain.CertificateKeyAlgorithmType(config.KeyAlgorithm)
+			if keyAlgorithm == "" {
+				keyAlgorithm = domain.CertificateKeyAlgorithmTypeRSA2048
+			}
+
+			// 初始化 ACME 配置项
+			// 独立模式下不读取全局设置，未指定 CA 时默认使用 Let's Encrypt
+			acmeCfg, err := certacme.CreateACMEConfig(cmd.Context(), &certacme.ACMEConfigOptions{
+				CAProvider:               lo.CoalesceOrEmpty(domain.CAProviderType(config.CAProvider), domain.CAProviderTypeLetsEncrypt),
+				CAProviderAccessConfig:   caAccessConfig,
+				CAProviderExtendedConfig: config.CAProviderConfig,
+				CertifierKeyAlgorithm:    keyAlgorithm,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to initialize acme config: %w", err)
+			} else {
+				logger.Info("acme config initialized", slog.String("acmeDirUrl", acmeCfg.CADirUrl))
+			}
+
+			// 初始化 ACME 账户
+			acmeAcct, err := loadOrRegisterACMEAccount(cmd, flagAccount, acmeCfg, config.ContactEmail)
+			if err != nil {
+				return fmt.Errorf("failed to initialize acme account: %w", err)
+			} else {
+				logger.Info("acme account initialized", slog.String("acmeAcctUrl", acmeAcct.ACMEAccountUrl))
+			}
+
+			client, err := certacme.NewACMEClientWithAccount(acmeAcct)
+			if err != nil {
+				return fmt.Errorf("failed to initialize acme client: %w", err)
+			}
+
+			resp, err := client.ObtainCertificate(cmd.Context(), &certacme.ObtainCertificateRequest{
+				DomainOrIPs:            lo.Concat(config.Domains, config.IPAddrs),
+				PrivateKeyType:         keyAlgorithm.LegoKeyType(),
+				ValidityNotAfter:       validityNotAfter,
+				NoCommonName:           config.DisableCommonName,
+				ChallengeType:          config.ChallengeType,
+				Provider:               domain.ACMEChallengeProviderType(config.Provider),
+				ProviderAccessConfig:   providerAccessConfig,
+				ProviderExtendedConfig: config.ProviderConfig,
+				DisableFollowCNAME:     config.DisableFollowCNAME,
+				Nameservers:            config.Nameservers,
+				DnsPropagationWait:     config.DnsPropagationWait,
+				DnsPropagationTimeout:  config.DnsPropagationTimeout,
+				DnsTTL:                 config.DnsTTL,
+				HttpDelayWait:          config.HttpDelayWait,
+				PreferredChain:         config.PreferredChain,
+				ACMEProfile:            config.ACMEProfile,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to obtain certificate: %w", err)
+			}
+
+			if err := os.WriteFile(flagCertFile, []byte(resp.FullChainCertificate+"\n"), 0o644); err != nil {
+				return fmt.Errorf("failed to write certificate file: %w", err)
+			}
+			if err := os.WriteFile(flagKeyFile, []byte(resp.PrivateKey+"\n"), 0o600); err != nil {
+				return fmt.Errorf("failed to write private key file: %w", err)
+			}
+
+			logger.Info("certificate obtained", slog.String("certFile", flagCertFile), slog.String("keyFile", flagKeyFile), slog.String("acmeCertUrl", resp.ACMECertificateUrl))
+			return nil
+		},
+	}
+
+	command.Flags().StringVar(&flagConfig, "config", "", "the path to the config file (JSON or YAML)")
+	command.Flags().StringVar(&flagAccount, "account", "./acme_account.json", "the path to the ACME account file")
+	command.Flags().StringVar(&flagCertFile, "certFile", "./fullchain.pem", "the path to save the certificate (full chain, PEM format)")
+	command.Flags().StringVar(&flagKeyFile, "keyFile", "./privkey.pem", "the path to save the private key (PEM format)")
+	command.Flags().BoolVar(&flagVerbose, "verbose", false, "print debug logs")
+
+	return command
+}
+
+func certDeployCommand(_ core.App) *cobra.Command {
+	var flagProvider string
+	var flagConfig string
+	var flagCertFile string
+	var flagKeyFile string
+	var flagVerbose bool
+
+	type DeployConfig struct {
+		Provider       string         `json:"provider"`
+		ProviderAccess map[string]any `json:"providerAccess"`
+		ProviderConfig map[string]any `json:"providerConfig"`
+	}
+
+	command := &cobra.Command{
+		Use:   "deploy",
+		Short: "Deploys a certificate to a host provider",
+		Long: "Deploys a certificate to a host provider.\n" +
+			"The config file can be written in JSON or YAML, it contains the provider name (optional if `--provider` is specified), " +
+			"the access config in `providerAccess` and the extended config in `providerConfig`.",
+		Example:      "cert deploy --provider ssh --config ./deploy.json --certFile ./fullchain.pem --keyFile ./privkey.pem",
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			logger := newStandaloneLogger(flagVerbose)
+
+			config := &DeployConfig{}
+			if err := readStandaloneConfigFile(flagConfig, config); err != nil {
+				return err
+			}
+			if flagProvider != "" {
+				config.Provider = flagProvider
+			}
+			if config.Provider == "" {
+				return fmt.Errorf("missing provider, please specify it via `--provider` or in config file")
+			}
+
+			providerAccessConfig, err := resolveStandaloneAccessConfig(cmd.Context(), config.ProviderAccess)
+			if err != nil {
+				return err
+			}
+
+			certPEM, err := os.ReadFile(flagCertFile)
+			if err != nil {
+				return fmt.Errorf("failed to read certificate file: %w", err)
+			}
+
+			keyPEM, err := os.ReadFile(flagKeyFile)
+			if err != nil {
+				return fmt.Errorf("failed to read private key file: %w", err)
+			}
+
+			client := certmgmt.NewClient(certmgmt.WithLogger(logger))
+			if _, err := client.DeployCertificate(cmd.Context(), &certmgmt.DeployCertificateRequest{
+				Provider:               domain.DeploymentProviderType(config.Provider),
+				ProviderAccessConfig:   providerAccessConfig,
+				ProviderExtendedConfig: config.ProviderConfig,
+				CertificatePEM:         string(certPEM),
+				PrivateKeyPEM:          string(keyPEM),
+			}); err != nil {
+				return fmt.Errorf("failed to deploy certificate: %w", err)
+			}
+
+			logger.Info("certificate deployed", slog.String("provider", config.Provider))
+			return nil
+		},
+	}
+
+	command.Flags().StringVar(&flagProvider, "provider", "", "the deployment provider, overrides the one in config file")
+	command.Flags().StringVar(&flagConfig, "config", "", "the path to the config file (JSON or YAML)")
+	command.Flags().StringVar(&flagCertFile, "certFile", "./fullchain.pem", "the path to the certificate file (PEM format)")
+	command.Flags().StringVar(&flagKeyFile, "keyFile", "./privkey.pem", "the path to the private key file (PEM format)")
+	command.Flags().BoolVar(&flagVerbose, "verbose", false, "print debug logs")
+
+	return command
+}
+
+// 从本地文件中读取 ACME 账户，如果文件不存在则注册新账户并写入文件。
+func loadOrRegisterACMEAccount(cmd *cobra.Command, path string, config *certacme.ACMEConfig, email string) (*certacme.ACMEAccount, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("failed to read acme account file: %w", err)
+		}
+
+		account, err := certacme.RegisterACMEAccount(cmd.Context(), config, email)
+		if err != nil {
+			return nil, err
+		}
+
+		data, err := json.MarshalIndent(account, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(path, data, 0o600); err != nil {
+			return nil, fmt.Errorf("failed to write acme account file: %w", err)
+		}
+
+		return account, nil
+	}
+
+	account := &certacme.ACMEAccount{}
+	if err := json.Unmarshal(data, account); err != nil {
+		return nil, fmt.Errorf("failed to parse acme account file: %w", err)
+	}
+	if account.ACMEDirectoryUrl != config.CADirUrl || account.Email != email {
+		return nil, fmt.Errorf("the acme account in '%s' (email: '%s', acmeDirUrl: '%s') does not match the config, please specify another account file", path, account.Email, account.ACMEDirectoryUrl)
+	}
+
+	return account, nil
+}
@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/spf13/cobra"
+
+	"github.com/certimate-go/certimate/internal/encryption"
+)
+
+func NewEncryptionCommand(app core.App) *cobra.Command {
+	command := &cobra.Command{
+		Use:   "encryption",
+		Short: "Manages encryption of sensitive data at rest",
+	}
+
+	command.AddCommand(encryptionGenkeyCommand(app))
+	command.AddCommand(encryptionRotateCommand(app))
+
+	return command
+}
+
+func encryptionGenkeyCommand(_ core.App) *cobra.Command {
+	command := &cobra.Command{
+		Use:     "genkey",
+		Short:   "Generates a random master key",
+		Example: "encryption genkey",
+		Run: func(cmd *cobra.Command, args []string) {
+			masterKey, err := encryption.GenerateMasterKey()
+			if err != nil {
+				slog.Error(fmt.Sprintf("failed to generate master key: %v", err))
+				return
+			}
+
+			fmt.Println(masterKey)
+		},
+	}
+
+	return command
+}
+
+func encryptionRotateCommand(app core.App) *cobra.Command {
+	var flagNewKey string
+	var flagNewKeyFile string
+
+	command := &cobra.Command{
+		Use:   "rotate",
+		Short: "Re-encrypts sensitive data with a new master key (please stop the server before running it)",
+		Long: "Re-encrypts sensitive data with a new master key.\n" +
+			"The current master key is read from the environment variables `CERTIMATE_ENCRYPTION_KEY` or `CERTIMATE_ENCRYPTION_KEY_FILE`. " +
+			"If it is not configured, the existing plaintext data will be encrypted for the first time.\n" +
+			"After the rotation completes, replace the current master key with the new one before starting the server.",
+		Example:      "encryption rotate --newKeyFile ./new.key",
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			source, err := encryption.GetEnvelope()
+			if err != nil {
+				return err
+			}
+
+			newKey, err := encryption.LoadMasterKey(flagNewKey, flagNewKeyFile)
+			if err != nil {
+				return err
+			} else if newKey == nil {
+				return fmt.Errorf("missing new master key, please specify it via `--newKey` or `--newKeyFile`")
+			}
+
+			target, err := encryption.NewEnvelope(newKey)
+			if err != nil {
+				return err
+			}
+
+			return app.RunInTransaction(func(txApp core.App) error {
+				for _, collectionName := range slices.Sorted(maps.Keys(encryption.EncryptedFields)) {
+					count, err := encryption.ReencryptCollection(txApp, collectionName, encryption.EncryptedFields[collectionName], source, target)
+					if err != nil {
+						return fmt.Errorf("failed to re-encrypt collection '%s': %w", collectionName, err)
+					}
+
+					slog.Info(fmt.Sprintf("collection '%s' re-encrypted, %d record(s) affected", collectionName, count))
+				}
+
+				slog.Info(fmt.Sprintf("master key rotated (key id: %s), please update the environment variables before restarting the server", target.KeyId()))
+				return nil
+			})
+		},
+	}
+
+	command.Flags().StringVar(&flagNewKey, "newKey", "", "the new master key, encoded in base64 or hex")
+	command.Flags().StringVar(&flagNewKeyFile, "newKeyFile", "", "the path to the file containing the new master key")
+
+	return command
+}
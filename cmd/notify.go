@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/spf13/cobra"
+
+	"github.com/certimate-go/certimate/internal/domain"
+	"github.com/certimate-go/certimate/internal/notify"
+)
+
+func NewNotifyCommand(app core.App) *cobra.Command {
+	command := &cobra.Command{
+		Use:   "notify",
+		Short: "Sends notifications without starting the server",
+	}
+
+	command.AddCommand(notifySendCommand(app))
+
+	return command
+}
+
+func notifySendCommand(_ core.App) *cobra.Command {
+	var flagProvider string
+	var flagConfig string
+	var flagSubject string
+	var flagMessage string
+	var flagVerbose bool
+
+	type SendConfig struct {
+		Provider       string         `json:"provider"`
+		ProviderAccess map[string]any `json:"providerAccess"`
+		ProviderConfig map[string]any `json:"providerConfig"`
+		Subject        string         `json:"subject"`
+		Message        string         `json:"message"`
+	}
+
+	command := &cobra.Command{
+		Use:   "send",
+		Short: "Sends a notification via a notification provider",
+		Long: "Sends a notification via a notification provider.\n" +
+			"The config file can be written in JSON or YAML, it contains the provider name (optional if `--provider` is specified), " +
+			"the access config in `providerAccess`, the extended config in `providerConfig`, and the default `subject` and `message`.",
+		Example:      "notify send --provider email --config ./notify.yaml --subject \"Hello\" --message \"Certimate\"",
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			logger := newStandaloneLogger(flagVerbose)
+
+			config := &SendConfig{}
+			if err := readStandaloneConfigFile(flagConfig, config); err != nil {
+				return err
+			}
+			if flagProvider != "" {
+				config.Provider = flagProvider
+			}
+			if flagSubject != "" {
+				config.Subject = flagSubject
+			}
+			if flagMessage != "" {
+				config.Message = flagMessage
+			}
+			if config.Provider == "" {
+				return fmt.Errorf("missing provider, please specify it via `--provider` or in config file")
+			}
+
+			providerAccessConfig, err := resolveStandaloneAccessConfig(cmd.Context(), config.ProviderAccess)
+			if err != nil {
+				return err
+			}
+
+			client := notify.NewClient(notify.WithLogger(logger))
+			if _, err := client.SendNotification(cmd.Context(), &notify.SendNotificationRequest{
+				Provider:               domain.NotificationProviderType(config.Provider),
+				ProviderAccessConfig:   providerAccessConfig,
+				ProviderExtendedConfig: config.ProviderConfig,
+				Subject:                config.Subject,
+				Message:                config.Message,
+			}); err != nil {
+				return fmt.Errorf("failed to send notification: %w", err)
+			}
+
+			logger.Info("notification sent", slog.String("provider", config.Provider))
+			return nil
+		},
+	}
+
+	command.Flags().StringVar(&flagProvider, "provider", "", "the notification provider, overrides the one in config file")
+	command.Flags().StringVar(&flagConfig, "config", "", "the path to the config file (JSON or YAML)")
+	command.Flags().StringVar(&flagSubject, "subject", "", "the notification subject, overrides the one in config file")
+	command.Flags().StringVar(&flagMessage, "message", "", "the notification message, overrides the one in config file")
+	command.Flags().BoolVar(&flagVerbose, "verbose", false, "print debug logs")
+
+	return command
+}
@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/certimate-go/certimate/internal/secrets"
+	xyaml "github.com/certimate-go/certimate/pkg/utils/yaml"
+)
+
+// 无需初始化 PocketBase 即可执行的独立命令，便于在脚本或 CI 中直接调用提供商能力。
+var standaloneCommands = []string{"cert", "notify"}
+
+func IsStandaloneCommand(name string) bool {
+	return slices.Contains(standaloneCommands, name)
+}
+
+// 读取 JSON 或 YAML 格式的配置文件，根据文件扩展名决定解析方式。
+func readStandaloneConfigFile(path string, v any) error {
+	if path == "" {
+		return fmt.Errorf("missing config file, please specify it via `--config`")
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		// 先转为 JSON，以复用结构体上的 JSON 标签
+		data, err = xyaml.ToJSON(data)
+		if err != nil {
+			return fmt.Errorf("failed to parse config file: %w", err)
+		}
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to parse config file: %w", err)
+	}
+
+	return nil
+}
+
+// 解析授权配置中的外部密钥引用。
+func resolveStandaloneAccessConfig(ctx context.Context, config map[string]any) (map[string]any, error) {
+	if config == nil {
+		return make(map[string]any), nil
+	}
+
+	resolved, err := secrets.ResolveMap(ctx, config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve secrets of access config: %w", err)
+	}
+
+	return resolved, nil
+}
+
+func newStandaloneLogger(verbose bool) *slog.Logger {
+	level := slog.LevelInfo
+	if verbose {
+		level = slog.LevelDebug
+	}
+
+	return slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level}))
+}
@@ -0,0 +1,43 @@
+package access
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/certimate-go/certimate/internal/domain"
+	"github.com/certimate-go/certimate/internal/domain/dtos"
+)
+
+type AccessService struct {
+	accessRepo accessRepository
+}
+
+func NewAccessService(accessRepo accessRepository) *AccessService {
+	return &AccessService{
+		accessRepo: accessRepo,
+	}
+}
+
+func (s *AccessService) TestAccess(ctx context.Context, req *dtos.AccessTestReq) (*dtos.AccessTestResp, error) {
+	access, err := s.accessRepo.GetById(ctx, req.AccessId)
+	if err != nil {
+		return nil, err
+	}
+
+	validator, err := newValidator(access)
+	if err != nil {
+		return nil, domain.NewError(400, err.Error())
+	} else if validator == nil {
+		// 不支持校验的提供商不视为错误，而是明确告知未做任何校验
+		return &dtos.AccessTestResp{
+			Supported: false,
+			Message:   fmt.Sprintf("access provider '%s' does not support credential validation, nothing was checked", access.Provider),
+		}, nil
+	}
+
+	if err := validator.Validate(ctx); err != nil {
+		return nil, domain.NewError(400, fmt.Sprintf("credential validation failed: %s", err.Error()))
+	}
+
+	return &dtos.AccessTestResp{Supported: true}, nil
+}
@@ -0,0 +1,11 @@
+package access
+
+import (
+	"context"
+
+	"github.com/certimate-go/certimate/internal/domain"
+)
+
+type accessRepository interface {
+	GetById(ctx context.Context, id string) (*domain.Access, error)
+}
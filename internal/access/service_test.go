@@ -0,0 +1,94 @@
+package access
+
+import (
+	"context"
+	"testing"
+
+	"github.com/certimate-go/certimate/internal/domain"
+	"github.com/certimate-go/certimate/internal/domain/dtos"
+)
+
+type mockAccessRepository struct {
+	access *domain.Access
+}
+
+func (r *mockAccessRepository) GetById(ctx context.Context, id string) (*domain.Access, error) {
+	if r.access.Id != id {
+		return nil, domain.ErrRecordNotFound
+	}
+	return r.access, nil
+}
+
+func TestNewValidator(t *testing.T) {
+	testCases := []struct {
+		name          string
+		access        *domain.Access
+		wantValidator bool
+	}{
+		{
+			name:          "dns provider",
+			access:        &domain.Access{Provider: string(domain.AccessProviderTypeCloudflare), Config: map[string]any{"apiToken": "token"}},
+			wantValidator: true,
+		},
+		{
+			name:          "deployment provider",
+			access:        &domain.Access{Provider: string(domain.AccessProviderTypeSSH), Config: map[string]any{"host": "127.0.0.1", "port": 22}},
+			wantValidator: true,
+		},
+		{
+			name:          "notification provider",
+			access:        &domain.Access{Provider: string(domain.AccessProviderTypeEmail), Reserve: "notif", Config: map[string]any{"smtpHost": "smtp.example.com", "smtpPort": 465, "smtpTls": true}},
+			wantValidator: true,
+		},
+		{
+			name:          "bot notification provider",
+			access:        &domain.Access{Provider: string(domain.AccessProviderTypeTelegramBot), Reserve: "notif", Config: map[string]any{"botToken": "token"}},
+			wantValidator: true,
+		},
+		{
+			name:          "unsupported notification provider",
+			access:        &domain.Access{Provider: string(domain.AccessProviderTypeWebhook), Reserve: "notif", Config: map[string]any{"url": "https://example.com"}},
+			wantValidator: false,
+		},
+		{
+			name:          "ca provider",
+			access:        &domain.Access{Provider: "zerossl", Reserve: "ca", Config: map[string]any{}},
+			wantValidator: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			validator, err := newValidator(tc.access)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if (validator != nil) != tc.wantValidator {
+				t.Errorf("expected validator: %v, got %v", tc.wantValidator, validator != nil)
+			}
+		})
+	}
+}
+
+func TestTestAccessNotSupported(t *testing.T) {
+	service := NewAccessService(&mockAccessRepository{
+		access: &domain.Access{
+			Meta:     domain.Meta{Id: "access1"},
+			Provider: string(domain.AccessProviderTypeWebhook),
+			Reserve:  "notif",
+			Config:   map[string]any{"url": "https://example.com"},
+		},
+	})
+
+	// 不支持校验的提供商应返回中性的结果，而非错误
+	res, err := service.TestAccess(context.Background(), &dtos.AccessTestReq{AccessId: "access1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Supported {
+		t.Errorf("expected the provider not to be supported")
+	}
+	if res.Message == "" {
+		t.Errorf("expected a message explaining that nothing was checked")
+	}
+}
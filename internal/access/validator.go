@@ -0,0 +1,84 @@
+package access
+
+import (
+	"fmt"
+
+	"github.com/certimate-go/certimate/internal/certacme/certifiers"
+	"github.com/certimate-go/certimate/internal/certmgmt/deployers"
+	"github.com/certimate-go/certimate/internal/domain"
+	"github.com/certimate-go/certimate/internal/notify/notifiers"
+	"github.com/certimate-go/certimate/pkg/core"
+)
+
+// 根据授权提供商查找可用于校验凭据的提供商实例。
+// 若未找到实现了 [core.Validator] 接口的提供商，将返回 nil。
+func newValidator(access *domain.Access) (core.Validator, error) {
+	switch access.Reserve {
+	case "notif":
+		// 目前邮件、Telegram、Discord、Slack 等通知提供商支持校验凭据；
+		// Webhook 等提供商无法在不发送消息的前提下校验，因此不支持
+		factory, err := notifiers.Registries.Get(domain.NotificationProviderType(access.Provider))
+		if err != nil {
+			return nil, nil
+		}
+
+		provider, err := factory(&notifiers.ProviderFactoryOptions{
+			ProviderAccessConfig:   access.Config,
+			ProviderExtendedConfig: make(map[string]any),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize notification provider '%s': %w", access.Provider, err)
+		}
+
+		return asValidator(provider), nil
+
+	case "ca":
+		// 目前 CA 提供商均不支持校验凭据（如 EAB 凭据只能在注册账户时校验）
+		return nil, nil
+
+	case "":
+		// 优先作为 DNS 提供商校验。授权提供商与 DNS 提供商可能同名（如 "cloudflare"），也可能带有 "-dns" 后缀（如 "aliyun-dns"）
+		for _, name := range []string{access.Provider, access.Provider + "-dns"} {
+			factory, err := certifiers.ACMEDns01Registries.Get(domain.ACMEDns01ProviderType(name))
+			if err != nil {
+				continue
+			}
+
+			provider, err := factory(&certifiers.ProviderFactoryOptions{
+				ProviderAccessConfig:   access.Config,
+				ProviderExtendedConfig: make(map[string]any),
+			})
+			if err != nil {
+				return nil, fmt.Errorf("failed to initialize dns-01 provider '%s': %w", name, err)
+			}
+
+			if validator := asValidator(provider); validator != nil {
+				return validator, nil
+			}
+			break
+		}
+
+		// 其次作为同名的部署提供商校验（如 "ssh"），此时没有额外配置，因此仅能校验授权凭据
+		if factory, err := deployers.Registries.Get(domain.DeploymentProviderType(access.Provider)); err == nil {
+			provider, err := factory(&deployers.ProviderFactoryOptions{
+				ProviderAccessConfig:   access.Config,
+				ProviderExtendedConfig: make(map[string]any),
+			})
+			if err != nil {
+				return nil, fmt.Errorf("failed to initialize deployment provider '%s': %w", access.Provider, err)
+			}
+
+			return asValidator(provider), nil
+		}
+	}
+
+	return nil, nil
+}
+
+func asValidator(provider any) core.Validator {
+	if validator, ok := provider.(core.Validator); ok {
+		return validator
+	}
+
+	return nil
+}
@@ -0,0 +1,67 @@
+package acmedns
+
+import (
+	"log/slog"
+	"path/filepath"
+
+	"github.com/certimate-go/certimate/internal/app"
+	xenv "github.com/certimate-go/certimate/pkg/utils/env"
+)
+
+var (
+	envListen  = xenv.GetOrDefaultString("CERTIMATE_ACMEDNS_LISTEN", ":53")
+	envZone    = xenv.GetOrDefaultString("CERTIMATE_ACMEDNS_ZONE", "")
+	envNSName  = xenv.GetOrDefaultString("CERTIMATE_ACMEDNS_NSNAME", "")
+	envNSAdmin = xenv.GetOrDefaultString("CERTIMATE_ACMEDNS_NSADMIN", "")
+)
+
+var server *Server
+
+// 启动内置的 DNS 应答服务器。
+// 仅当设置了环境变量 `CERTIMATE_ACMEDNS_ZONE` 时才会启动。
+func Setup() {
+	if envZone == "" {
+		return
+	}
+
+	srv, err := NewServer(&ServerConfig{
+		Listen:      envListen,
+		Zone:        envZone,
+		NSName:      envNSName,
+		NSAdmin:     envNSAdmin,
+		StoragePath: getStoragePath(),
+	})
+	if err != nil {
+		app.GetLogger().Error("failed to initialize acme-dns server", slog.Any("error", err))
+		return
+	}
+
+	srv.SetLogger(app.GetLogger())
+	if err := srv.Start(); err != nil {
+		app.GetLogger().Error("failed to start acme-dns server", slog.Any("error", err))
+		return
+	}
+
+	server = srv
+}
+
+func Teardown() {
+	if server != nil {
+		server.Shutdown()
+		server = nil
+	}
+}
+
+// 创建向内置 DNS 服务器写入记录的 DNS-01 质询提供商。
+// 申请证书可能在子进程中执行，因此这里不依赖服务器实例，而是直接读写共享的记录存储。
+func NewLocalChallenger(dnsPropagationTimeout int) (*Challenger, error) {
+	return NewChallenger(&ChallengerConfig{
+		Zone:                  envZone,
+		StoragePath:           getStoragePath(),
+		DnsPropagationTimeout: dnsPropagationTimeout,
+	})
+}
+
+func getStoragePath() string {
+	return filepath.Join(app.GetApp().DataDir(), "acmedns")
+}
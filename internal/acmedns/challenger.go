@@ -0,0 +1,100 @@
+package acmedns
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-acme/lego/v5/challenge/dns01"
+	"github.com/miekg/dns"
+
+	"github.com/certimate-go/certimate/pkg/core"
+)
+
+type ChallengerConfig struct {
+	// 委派给内置 DNS 服务器的区域。
+	Zone string
+	// 记录存储目录。
+	StoragePath string
+	// DNS 传播超时时间（单位：秒）。
+	DnsPropagationTimeout int
+}
+
+// 向内置 DNS 服务器写入 TXT 记录的 DNS-01 质询提供商。
+type Challenger struct {
+	config *ChallengerConfig
+	store  *recordStore
+}
+
+var _ core.ACMEChallenger = (*Challenger)(nil)
+
+func NewChallenger(config *ChallengerConfig) (*Challenger, error) {
+	if config == nil {
+		return nil, fmt.Errorf("the configuration of the acme challenge provider is nil")
+	}
+	if config.Zone == "" {
+		return nil, fmt.Errorf("the built-in acme-dns server is not enabled, please set the environment variable 'CERTIMATE_ACMEDNS_ZONE'")
+	}
+	if config.StoragePath == "" {
+		return nil, fmt.Errorf("the storage path of the acme-dns server is empty")
+	}
+
+	return &Challenger{
+		config: config,
+		store:  newRecordStore(config.StoragePath),
+	}, nil
+}
+
+func (c *Challenger) Present(ctx context.Context, domain, token, keyAuth string) error {
+	info := dns01.GetChallengeInfo(ctx, domain, keyAuth)
+
+	fqdn, err := c.resolveFQDN(info)
+	if err != nil {
+		return err
+	}
+
+	if err := c.store.Add(fqdn, info.Value); err != nil {
+		return fmt.Errorf("acmedns: failed to add txt record '%s': %w", fqdn, err)
+	}
+
+	return nil
+}
+
+func (c *Challenger) CleanUp(ctx context.Context, domain, token, keyAuth string) error {
+	info := dns01.GetChallengeInfo(ctx, domain, keyAuth)
+
+	fqdn, err := c.resolveFQDN(info)
+	if err != nil {
+		return err
+	}
+
+	if err := c.store.Remove(fqdn, info.Value); err != nil {
+		return fmt.Errorf("acmedns: failed to remove txt record '%s': %w", fqdn, err)
+	}
+
+	return nil
+}
+
+func (c *Challenger) Timeout() (timeout, interval time.Duration) {
+	timeout = 2 * time.Minute
+	if c.config.DnsPropagationTimeout > 0 {
+		timeout = time.Duration(c.config.DnsPropagationTimeout) * time.Second
+	}
+
+	return timeout, 2 * time.Second
+}
+
+func (c *Challenger) resolveFQDN(info dns01.ChallengeInfo) (string, error) {
+	zone := dns.Fqdn(strings.ToLower(c.config.Zone))
+
+	// 域名已通过 CNAME 委派到本区域下，或者本区域即为该域名的 `_acme-challenge` 子域名
+	fqdn := strings.ToLower(info.EffectiveFQDN)
+	if dns.IsSubDomain(zone, fqdn) {
+		return fqdn, nil
+	}
+
+	name := strings.TrimSuffix(info.FQDN, ".")
+	return "", fmt.Errorf("acmedns: '%s' is not delegated to zone '%s', please add a CNAME record for it pointing to '%s.%s'",
+		name, strings.TrimSuffix(zone, "."), strings.TrimPrefix(name, info.Prefix+"."), strings.TrimSuffix(zone, "."))
+}
@@ -0,0 +1,204 @@
+package acmedns
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+const (
+	txtRecordTTL = 1
+	soaRecordTTL = 300
+)
+
+type ServerConfig struct {
+	// 监听地址，如 ":53"。
+	Listen string
+	// 委派给本服务器的区域，如 "acme.example.com"。
+	Zone string
+	// 本服务器的域名，用于应答 NS 及 SOA 记录。
+	// 零值时默认值 "ns.<Zone>"。
+	NSName string
+	// 区域管理员邮箱，用于应答 SOA 记录。
+	// 零值时默认值 "admin.<Zone>"。
+	NSAdmin string
+	// 记录存储目录。
+	StoragePath string
+}
+
+// 内置的权威 DNS 应答服务器，用于应答委派区域内的 `_acme-challenge` TXT 记录查询。
+// 其工作方式与 acme-dns 兼容：只需将待验证域名的 `_acme-challenge` 子域名 CNAME 到该区域下即可。
+type Server struct {
+	config *ServerConfig
+	store  *recordStore
+	logger *slog.Logger
+
+	servers []*dns.Server
+	serial  uint32
+}
+
+func NewServer(config *ServerConfig) (*Server, error) {
+	if config == nil {
+		return nil, fmt.Errorf("the configuration of the acme-dns server is nil")
+	}
+	if config.Listen == "" {
+		return nil, fmt.Errorf("the listen address of the acme-dns server is empty")
+	}
+	if config.Zone == "" {
+		return nil, fmt.Errorf("the zone of the acme-dns server is empty")
+	}
+	if config.StoragePath == "" {
+		return nil, fmt.Errorf("the storage path of the acme-dns server is empty")
+	}
+
+	zone := dns.Fqdn(strings.ToLower(config.Zone))
+	if _, ok := dns.IsDomainName(zone); !ok {
+		return nil, fmt.Errorf("invalid zone '%s'", config.Zone)
+	}
+
+	cfg := *config
+	cfg.Zone = zone
+	if cfg.NSName == "" {
+		cfg.NSName = "ns." + zone
+	}
+	if cfg.NSAdmin == "" {
+		cfg.NSAdmin = "admin." + zone
+	}
+	cfg.NSName = dns.Fqdn(strings.ToLower(cfg.NSName))
+	cfg.NSAdmin = dns.Fqdn(strings.ReplaceAll(strings.ToLower(cfg.NSAdmin), "@", "."))
+
+	return &Server{
+		config: &cfg,
+		store:  newRecordStore(config.StoragePath),
+		logger: slog.Default(),
+		serial: uint32(time.Now().Unix()),
+	}, nil
+}
+
+func (s *Server) SetLogger(logger *slog.Logger) {
+	if logger == nil {
+		s.logger = slog.New(slog.DiscardHandler)
+	} else {
+		s.logger = logger
+	}
+}
+
+func (s *Server) Start() error {
+	mux := dns.NewServeMux()
+	mux.HandleFunc(s.config.Zone, s.handle)
+
+	startedChs := make([]chan error, 0, 2)
+	for _, network := range []string{"udp", "tcp"} {
+		startedCh := make(chan error, 1)
+		startedChs = append(startedChs, startedCh)
+
+		server := &dns.Server{Addr: s.config.Listen, Net: network, Handler: mux}
+		server.NotifyStartedFunc = func() { startedCh <- nil }
+		s.servers = append(s.servers, server)
+
+		go func() {
+			if err := server.ListenAndServe(); err != nil {
+				select {
+				case startedCh <- fmt.Errorf("failed to listen on %s/%s: %w", s.config.Listen, network, err):
+				default:
+				}
+			}
+		}()
+	}
+
+	for _, startedCh := range startedChs {
+		if err := <-startedCh; err != nil {
+			s.Shutdown()
+			return err
+		}
+	}
+
+	s.logger.Info(fmt.Sprintf("acme-dns server is listening on %s, zone: %s", s.config.Listen, s.config.Zone))
+	return nil
+}
+
+func (s *Server) Shutdown() error {
+	errs := make([]error, 0)
+	for _, server := range s.servers {
+		if err := server.Shutdown(); err != nil && !strings.Contains(err.Error(), "not started") {
+			errs = append(errs, err)
+		}
+	}
+	s.servers = nil
+
+	return errors.Join(errs...)
+}
+
+func (s *Server) handle(w dns.ResponseWriter, req *dns.Msg) {
+	msg := new(dns.Msg)
+	msg.SetReply(req)
+	msg.Authoritative = true
+	msg.Compress = true
+
+	if len(req.Question) != 1 || req.Opcode != dns.OpcodeQuery {
+		msg.SetRcode(req, dns.RcodeRefused)
+		w.WriteMsg(msg)
+		return
+	}
+
+	question := req.Question[0]
+	qname := strings.ToLower(question.Name)
+	if !dns.IsSubDomain(s.config.Zone, qname) {
+		msg.Authoritative = false
+		msg.SetRcode(req, dns.RcodeRefused)
+		w.WriteMsg(msg)
+		return
+	}
+
+	switch {
+	case question.Qtype == dns.TypeTXT:
+		values, err := s.store.Get(qname)
+		if err != nil {
+			s.logger.Warn(fmt.Sprintf("failed to read txt records of '%s'", qname), slog.String("error", err.Error()))
+			msg.SetRcode(req, dns.RcodeServerFailure)
+			break
+		}
+
+		for _, value := range values {
+			msg.Answer = append(msg.Answer, &dns.TXT{
+				Hdr: dns.RR_Header{Name: question.Name, Rrtype: dns.TypeTXT, Class: dns.ClassINET, Ttl: txtRecordTTL},
+				Txt: []string{value},
+			})
+		}
+
+	case question.Qtype == dns.TypeSOA && qname == s.config.Zone:
+		msg.Answer = append(msg.Answer, s.soa())
+
+	case question.Qtype == dns.TypeNS && qname == s.config.Zone:
+		msg.Answer = append(msg.Answer, &dns.NS{
+			Hdr: dns.RR_Header{Name: s.config.Zone, Rrtype: dns.TypeNS, Class: dns.ClassINET, Ttl: soaRecordTTL},
+			Ns:  s.config.NSName,
+		})
+	}
+
+	// 无应答记录时返回 NODATA，而非 NXDOMAIN，避免递归解析器缓存否定结果
+	if len(msg.Answer) == 0 && msg.Rcode == dns.RcodeSuccess {
+		msg.Ns = append(msg.Ns, s.soa())
+	}
+
+	if err := w.WriteMsg(msg); err != nil {
+		s.logger.Debug("failed to write dns response", slog.String("error", err.Error()))
+	}
+}
+
+func (s *Server) soa() dns.RR {
+	return &dns.SOA{
+		Hdr:     dns.RR_Header{Name: s.config.Zone, Rrtype: dns.TypeSOA, Class: dns.ClassINET, Ttl: soaRecordTTL},
+		Ns:      s.config.NSName,
+		Mbox:    s.config.NSAdmin,
+		Serial:  s.serial,
+		Refresh: 28800,
+		Retry:   7200,
+		Expire:  604800,
+		Minttl:  txtRecordTTL,
+	}
+}
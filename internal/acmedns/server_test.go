@@ -0,0 +1,145 @@
+package acmedns
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/miekg/dns"
+)
+
+func newTestServer(t *testing.T) (*Server, string) {
+	// 先占用一个 UDP 端口再释放，以便 UDP 和 TCP 监听同一端口
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	addr := conn.LocalAddr().String()
+	conn.Close()
+
+	server, err := NewServer(&ServerConfig{
+		Listen:      addr,
+		Zone:        "acme.example.com",
+		StoragePath: t.TempDir(),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	server.SetLogger(nil)
+	if err := server.Start(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	t.Cleanup(func() { server.Shutdown() })
+
+	return server, addr
+}
+
+func queryTestServer(t *testing.T, addr string, name string, qtype uint16) *dns.Msg {
+	msg := new(dns.Msg)
+	msg.SetQuestion(dns.Fqdn(name), qtype)
+
+	client := &dns.Client{Net: "udp"}
+	resp, _, err := client.Exchange(msg, addr)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	return resp
+}
+
+func TestServer(t *testing.T) {
+	server, addr := newTestServer(t)
+
+	const fqdn = "abc.acme.example.com."
+
+	t.Run("Concurrent add", func(t *testing.T) {
+		// 模拟多个质询提供商各自创建存储实例，并发写入同一域名
+		startCh := make(chan struct{})
+		wg := sync.WaitGroup{}
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+
+				store := newRecordStore(server.config.StoragePath)
+				<-startCh
+				if err := store.Add(fqdn, fmt.Sprintf("value-%02d", i)); err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+			}(i)
+		}
+		close(startCh)
+		wg.Wait()
+
+		resp := queryTestServer(t, addr, fqdn, dns.TypeTXT)
+		if resp.Rcode != dns.RcodeSuccess || !resp.Authoritative {
+			t.Fatalf("expected an authoritative answer, got rcode %s", dns.RcodeToString[resp.Rcode])
+		}
+
+		values := make([]string, 0)
+		for _, rr := range resp.Answer {
+			if txt, ok := rr.(*dns.TXT); ok {
+				values = append(values, txt.Txt...)
+			}
+		}
+		sort.Strings(values)
+		if len(values) != 20 {
+			t.Fatalf("expected 20 txt records, got %d: %v", len(values), values)
+		}
+		for i, value := range values {
+			if want := fmt.Sprintf("value-%02d", i); value != want {
+				t.Errorf("expected '%s', got '%s'", want, value)
+			}
+		}
+	})
+
+	t.Run("Concurrent remove", func(t *testing.T) {
+		startCh := make(chan struct{})
+		wg := sync.WaitGroup{}
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+
+				store := newRecordStore(server.config.StoragePath)
+				<-startCh
+				if err := store.Remove(fqdn, fmt.Sprintf("value-%02d", i)); err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+			}(i)
+		}
+		close(startCh)
+		wg.Wait()
+
+		resp := queryTestServer(t, addr, fqdn, dns.TypeTXT)
+		if resp.Rcode != dns.RcodeSuccess {
+			t.Fatalf("expected NODATA, got rcode %s", dns.RcodeToString[resp.Rcode])
+		}
+		if len(resp.Answer) != 0 {
+			t.Errorf("expected no txt records, got %v", resp.Answer)
+		}
+		if len(resp.Ns) != 1 || resp.Ns[0].Header().Rrtype != dns.TypeSOA {
+			t.Errorf("expected a SOA record in the authority section, got %v", resp.Ns)
+		}
+	})
+
+	t.Run("SOA", func(t *testing.T) {
+		resp := queryTestServer(t, addr, "acme.example.com", dns.TypeSOA)
+		if len(resp.Answer) != 1 {
+			t.Fatalf("expected 1 answer, got %d", len(resp.Answer))
+		}
+		if soa, ok := resp.Answer[0].(*dns.SOA); !ok || soa.Ns != "ns.acme.example.com." {
+			t.Errorf("unexpected SOA record: %v", resp.Answer[0])
+		}
+	})
+
+	t.Run("Out of zone", func(t *testing.T) {
+		resp := queryTestServer(t, addr, "example.org", dns.TypeTXT)
+		if resp.Rcode != dns.RcodeRefused {
+			t.Errorf("expected REFUSED, got rcode %s", dns.RcodeToString[resp.Rcode])
+		}
+	})
+}
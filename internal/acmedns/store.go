@@ -0,0 +1,185 @@
+package acmedns
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofrs/flock"
+	"github.com/miekg/dns"
+)
+
+// 记录的最长保留时间，超过后即使未被清理也不再应答，避免残留记录长期生效。
+const recordMaxAge = 2 * time.Hour
+
+type txtRecord struct {
+	Value     string    `json:"value"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// 同一目录的存储共享同一把进程内锁。
+// 每个质询提供商都会创建各自的存储实例，因此不能使用实例级别的锁。
+var storeMutexes sync.Map
+
+// 基于文件系统的 TXT 记录存储。
+// 由于申请证书可能在子进程中执行，这里通过文件而非内存在进程间共享记录。
+type recordStore struct {
+	dir string
+	mtx *sync.Mutex
+}
+
+func newRecordStore(dir string) *recordStore {
+	mtx, _ := storeMutexes.LoadOrStore(filepath.Clean(dir), &sync.Mutex{})
+	return &recordStore{dir: dir, mtx: mtx.(*sync.Mutex)}
+}
+
+// 加锁以保护“读取-修改-写入”过程。
+// 进程内使用互斥锁，进程间使用文件锁。
+func (s *recordStore) lock() (unlock func(), err error) {
+	s.mtx.Lock()
+
+	if err := os.MkdirAll(s.dir, 0o700); err != nil {
+		s.mtx.Unlock()
+		return nil, fmt.Errorf("failed to create record directory: %w", err)
+	}
+
+	flk := flock.New(filepath.Join(s.dir, ".lock"))
+	if err := flk.Lock(); err != nil {
+		s.mtx.Unlock()
+		return nil, fmt.Errorf("failed to lock record directory: %w", err)
+	}
+
+	return func() {
+		flk.Unlock()
+		s.mtx.Unlock()
+	}, nil
+}
+
+func (s *recordStore) Add(fqdn string, value string) error {
+	unlock, err := s.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	records, err := s.read(fqdn)
+	if err != nil {
+		return err
+	}
+
+	for _, record := range records {
+		if record.Value == value {
+			return nil
+		}
+	}
+
+	records = append(records, txtRecord{Value: value, CreatedAt: time.Now()})
+	return s.write(fqdn, records)
+}
+
+func (s *recordStore) Remove(fqdn string, value string) error {
+	unlock, err := s.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	records, err := s.read(fqdn)
+	if err != nil {
+		return err
+	}
+
+	remains := make([]txtRecord, 0, len(records))
+	for _, record := range records {
+		if record.Value != value {
+			remains = append(remains, record)
+		}
+	}
+
+	return s.write(fqdn, remains)
+}
+
+func (s *recordStore) Get(fqdn string) ([]string, error) {
+	records, err := s.read(fqdn)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]string, 0, len(records))
+	for _, record := range records {
+		values = append(values, record.Value)
+	}
+	return values, nil
+}
+
+func (s *recordStore) read(fqdn string) ([]txtRecord, error) {
+	data, err := os.ReadFile(s.filename(fqdn))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return make([]txtRecord, 0), nil
+		}
+		return nil, fmt.Errorf("failed to read record file: %w", err)
+	}
+
+	var records []txtRecord
+	if err := json.Unmarshal(data, &records); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal record file: %w", err)
+	}
+
+	valid := make([]txtRecord, 0, len(records))
+	for _, record := range records {
+		if time.Since(record.CreatedAt) < recordMaxAge {
+			valid = append(valid, record)
+		}
+	}
+	return valid, nil
+}
+
+func (s *recordStore) write(fqdn string, records []txtRecord) error {
+	filename := s.filename(fqdn)
+	if len(records) == 0 {
+		if err := os.Remove(filename); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to remove record file: %w", err)
+		}
+		return nil
+	}
+
+	data, err := json.Marshal(records)
+	if err != nil {
+		return fmt.Errorf("failed to marshal record file: %w", err)
+	}
+
+	if err := os.MkdirAll(s.dir, 0o700); err != nil {
+		return fmt.Errorf("failed to create record directory: %w", err)
+	}
+
+	// 先写入临时文件再重命名，保证其他进程读到的始终是完整的文件
+	tempfile, err := os.CreateTemp(s.dir, ".tmp_*")
+	if err != nil {
+		return fmt.Errorf("failed to create temp record file: %w", err)
+	}
+	defer os.Remove(tempfile.Name())
+
+	if _, err := tempfile.Write(data); err != nil {
+		tempfile.Close()
+		return fmt.Errorf("failed to write temp record file: %w", err)
+	}
+	tempfile.Close()
+
+	if err := os.Rename(tempfile.Name(), filename); err != nil {
+		return fmt.Errorf("failed to write record file: %w", err)
+	}
+
+	return nil
+}
+
+func (s *recordStore) filename(fqdn string) string {
+	name := strings.TrimSuffix(strings.ToLower(dns.Fqdn(fqdn)), ".")
+	name = strings.NewReplacer("/", "_", "\\", "_", "..", "_").Replace(name)
+	return filepath.Join(s.dir, name+".json")
+}
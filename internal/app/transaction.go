@@ -0,0 +1,25 @@
+package app
+
+import (
+	"context"
+
+	"github.com/pocketbase/pocketbase/core"
+)
+
+type txAppContextKey struct{}
+
+// 返回附加了事务实例的上下文，仓储层将在该事务中读写数据。
+func WithTxApp(ctx context.Context, txApp core.App) context.Context {
+	return context.WithValue(ctx, txAppContextKey{}, txApp)
+}
+
+// 如果上下文中附加了事务实例，则返回该实例；否则返回全局的应用实例。
+func GetAppWithContext(ctx context.Context) core.App {
+	if ctx != nil {
+		if txApp, ok := ctx.Value(txAppContextKey{}).(core.App); ok && txApp != nil {
+			return txApp
+		}
+	}
+
+	return GetApp()
+}
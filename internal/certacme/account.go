@@ -40,68 +40,85 @@ func CreateACMEAccount(ctx context.Context, config *ACMEConfig, email string) (*
 	}
 
 	if account == nil {
-		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		account, err = RegisterACMEAccount(ctx, config, email)
 		if err != nil {
 			return nil, err
 		}
 
-		keyPEM, err := xcert.ConvertECPrivateKeyToPEM(key, false)
-		if err != nil {
-			return nil, err
+		if _, err := accountRepo.Save(ctx, account); err != nil {
+			return nil, fmt.Errorf("failed to save acme account record: %w", err)
 		}
+	}
 
-		account = &ACMEAccount{
-			CA:               config.CAProvider.String(),
-			Email:            email,
-			PrivateKey:       keyPEM,
-			ACMEDirectoryUrl: config.CADirUrl,
-		}
+	return account, nil
+}
 
-		legoCfg := lego.NewConfig(account)
-		legoCfg.UserAgent = app.AppUserAgent
-		legoCfg.CADirURL = config.CADirUrl
-		legoClient, err := lego.NewClient(legoCfg)
-		if err != nil {
-			return nil, err
-		}
+// 向 ACME 服务端注册一个新账户，但不会持久化到数据库中。
+func RegisterACMEAccount(ctx context.Context, config *ACMEConfig, email string) (*ACMEAccount, error) {
+	if config == nil {
+		return nil, fmt.Errorf("the acme config is nil")
+	}
+	if email == "" {
+		return nil, fmt.Errorf("the email is empty")
+	}
 
-		var regres *acme.ExtendedAccount
-		var regerr error
-		if legoClient.GetServerMetadata().ExternalAccountRequired {
-			if config.EABKid == "" {
-				return nil, fmt.Errorf("missing or invalid eab kid")
-			}
-			if config.EABHmacKey == "" {
-				return nil, fmt.Errorf("missing or invalid eab hmac key")
-			}
-
-			// patch, see https://github.com/go-acme/lego/issues/2634
-			keyId := strings.TrimSpace(config.EABKid)
-			keyEncoded := strings.TrimSpace(config.EABHmacKey)
-			keyEncoded = strings.ReplaceAll(strings.ReplaceAll(keyEncoded, "+", "-"), "/", "_")
-			keyEncoded = strings.TrimSuffix(keyEncoded, "=")
-
-			regres, regerr = legoClient.Registration.RegisterWithExternalAccountBinding(ctx, registration.RegisterEABOptions{
-				TermsOfServiceAgreed: true,
-				Kid:                  keyId,
-				HmacEncoded:          keyEncoded,
-			})
-		} else {
-			regres, regerr = legoClient.Registration.Register(ctx, registration.RegisterOptions{
-				TermsOfServiceAgreed: true,
-			})
-		}
-		if regerr != nil {
-			return nil, fmt.Errorf("failed to register acme account: %w", regerr)
-		}
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
 
-		account.ACMEAccountUrl = regres.Location
-		account.ResourceObject = &regres.Account
+	keyPEM, err := xcert.ConvertECPrivateKeyToPEM(key, false)
+	if err != nil {
+		return nil, err
+	}
 
-		if _, err := accountRepo.Save(ctx, account); err != nil {
-			return nil, fmt.Errorf("failed to save acme account record: %w", err)
+	account := &ACMEAccount{
+		CA:               config.CAProvider.String(),
+		Email:            email,
+		PrivateKey:       keyPEM,
+		ACMEDirectoryUrl: config.CADirUrl,
+	}
+
+	legoCfg := lego.NewConfig(account)
+	legoCfg.UserAgent = app.AppUserAgent
+	legoCfg.CADirURL = config.CADirUrl
+	legoClient, err := lego.NewClient(legoCfg)
+	if err != nil {
+		return nil, err
+	}
+
+	var regres *acme.ExtendedAccount
+	var regerr error
+	if legoClient.GetServerMetadata().ExternalAccountRequired {
+		if config.EABKid == "" {
+			return nil, fmt.Errorf("missing or invalid eab kid")
 		}
+		if config.EABHmacKey == "" {
+			return nil, fmt.Errorf("missing or invalid eab hmac key")
+		}
+
+		// patch, see https://github.com/go-acme/lego/issues/2634
+		keyId := strings.TrimSpace(config.EABKid)
+		keyEncoded := strings.TrimSpace(config.EABHmacKey)
+		keyEncoded = strings.ReplaceAll(strings.ReplaceAll(keyEncoded, "+", "-"), "/", "_")
+		keyEncoded = strings.TrimSuffix(keyEncoded, "=")
+
+		regres, regerr = legoClient.Registration.RegisterWithExternalAccountBinding(ctx, registration.RegisterEABOptions{
+			TermsOfServiceAgreed: true,
+			Kid:                  keyId,
+			HmacEncoded:          keyEncoded,
+		})
+	} else {
+		regres, regerr = legoClient.Registration.Register(ctx, registration.RegisterOptions{
+			TermsOfServiceAgreed: true,
+		})
 	}
+	if regerr != nil {
+		return nil, fmt.Errorf("failed to register acme account: %w", regerr)
+	}
+
+	account.ACMEAccountUrl = regres.Location
+	account.ResourceObject = &regres.Account
 
 	return account, nil
 }
@@ -26,6 +26,27 @@ var caDirUrls = map[string]string{
 	domain.CAProviderTypeZeroSSL.String():             "https://acme.zerossl.com/v2/DV90",
 }
 
+// 获取证书颁发机构测试环境的目录地址。
+// 目前仅 Let's Encrypt 内置了测试环境；自定义 ACME CA 可在授权中配置测试环境的服务端点（此时沿用同一授权的 EAB 凭据）。
+func getCAStagingDirUrl(providerType domain.CAProviderType, providerAccessConfig map[string]any) (domain.CAProviderType, string, error) {
+	switch providerType {
+	case domain.CAProviderTypeLetsEncrypt, domain.CAProviderTypeLetsEncryptStaging:
+		return domain.CAProviderTypeLetsEncryptStaging, caDirUrls[domain.CAProviderTypeLetsEncryptStaging.String()], nil
+
+	case domain.CAProviderTypeACMECA:
+		credentials := &domain.AccessConfigForACMECA{}
+		if err := xmaps.Populate(providerAccessConfig, &credentials); err != nil {
+			return "", "", err
+		} else if credentials.StagingEndpoint == "" {
+			return "", "", ErrStagingNotSupported
+		}
+		return providerType, credentials.StagingEndpoint, nil
+
+	default:
+		return "", "", ErrStagingNotSupported
+	}
+}
+
 func getCADirUrl(providerType domain.CAProviderType, providerAccessConfig map[string]any, keyAlgorithm domain.CertificateKeyAlgorithmType) (string, error) {
 	switch providerType {
 	case domain.CAProviderTypeSectigo:
@@ -76,6 +76,7 @@ func newRegistry[T comparable]() Registry[T] {
 }
 
 var (
-	ACMEDns01Registries  = newRegistry[domain.ACMEDns01ProviderType]()
-	ACMEHttp01Registries = newRegistry[domain.ACMEHttp01ProviderType]()
+	ACMEDns01Registries     = newRegistry[domain.ACMEDns01ProviderType]()
+	ACMEHttp01Registries    = newRegistry[domain.ACMEHttp01ProviderType]()
+	ACMETlsAlpn01Registries = newRegistry[domain.ACMETlsAlpn01ProviderType]()
 )
@@ -1,17 +1,31 @@
 package certifiers
 
 import (
+	"github.com/certimate-go/certimate/internal/acmedns"
 	"github.com/certimate-go/certimate/internal/domain"
 	"github.com/certimate-go/certimate/pkg/core"
 	chlgimpl "github.com/certimate-go/certimate/pkg/core/certifier/challengers/http01/local"
+	tlschlgimpl "github.com/certimate-go/certimate/pkg/core/certifier/challengers/tlsalpn01/local"
 	xmaps "github.com/certimate-go/certimate/pkg/utils/maps"
 )
 
 func init() {
+	ACMEDns01Registries.MustRegister(domain.ACMEDns01ProviderTypeLocal, func(options *ProviderFactoryOptions) (core.ACMEChallenger, error) {
+		provider, err := acmedns.NewLocalChallenger(options.DnsPropagationTimeout)
+		return provider, err
+	})
+
 	ACMEHttp01Registries.MustRegister(domain.ACMEHttp01ProviderTypeLocal, func(options *ProviderFactoryOptions) (core.ACMEChallenger, error) {
 		provider, err := chlgimpl.NewChallenger(&chlgimpl.ChallengerConfig{
 			WebRootPath: xmaps.GetString(options.ProviderExtendedConfig, "webRootPath"),
 		})
 		return provider, err
 	})
+
+	ACMETlsAlpn01Registries.MustRegister(domain.ACMETlsAlpn01ProviderTypeLocal, func(options *ProviderFactoryOptions) (core.ACMEChallenger, error) {
+		provider, err := tlschlgimpl.NewChallenger(&tlschlgimpl.ChallengerConfig{
+			ListenAddress: xmaps.GetString(options.ProviderExtendedConfig, "listenAddress"),
+		})
+		return provider, err
+	})
 }
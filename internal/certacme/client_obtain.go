@@ -20,6 +20,7 @@ import (
 
 	"github.com/certimate-go/certimate/internal/certacme/certifiers"
 	"github.com/certimate-go/certimate/internal/domain"
+	"github.com/certimate-go/certimate/pkg/core"
 	xcert "github.com/certimate-go/certimate/pkg/utils/cert"
 )
 
@@ -69,6 +70,69 @@ type ObtainCertificateResponse struct {
 	ARIReplaced          bool
 }
 
+// 校验质询提供商，但不会发起任何质询。
+// 只有请求中的提供商相关字段会被使用。
+// 质询提供商实现了 [core.Validator] 接口时，将校验其授权凭据，此时返回的 checked 为 true；否则仅校验其配置。
+func CheckChallengeProvider(ctx context.Context, request *ObtainCertificateRequest) (checked bool, err error) {
+	if request == nil {
+		return false, fmt.Errorf("the request is nil")
+	}
+
+	options := &certifiers.ProviderFactoryOptions{
+		ProviderAccessConfig:   request.ProviderAccessConfig,
+		ProviderExtendedConfig: request.ProviderExtendedConfig,
+	}
+
+	var provider core.ACMEChallenger
+	switch strings.ToLower(request.ChallengeType) {
+	case "dns-01":
+		providerFactory, err := certifiers.ACMEDns01Registries.Get(domain.ACMEDns01ProviderType(request.Provider))
+		if err != nil {
+			return false, err
+		}
+
+		options.DnsPropagationTimeout = request.DnsPropagationTimeout
+		options.DnsTTL = request.DnsTTL
+		if provider, err = providerFactory(options); err != nil {
+			return false, fmt.Errorf("failed to initialize dns-01 provider '%s': %w", request.Provider, err)
+		}
+
+	case "http-01":
+		providerFactory, err := certifiers.ACMEHttp01Registries.Get(domain.ACMEHttp01ProviderType(request.Provider))
+		if err != nil {
+			return false, err
+		}
+
+		if provider, err = providerFactory(options); err != nil {
+			return false, fmt.Errorf("failed to initialize http-01 provider '%s': %w", request.Provider, err)
+		}
+
+	case "tls-alpn-01":
+		providerFactory, err := certifiers.ACMETlsAlpn01Registries.Get(domain.ACMETlsAlpn01ProviderType(request.Provider))
+		if err != nil {
+			return false, err
+		}
+
+		if provider, err = providerFactory(options); err != nil {
+			return false, fmt.Errorf("failed to initialize tls-alpn-01 provider '%s': %w", request.Provider, err)
+		}
+
+	default:
+		return false, fmt.Errorf("unsupported challenge type: '%s'", request.ChallengeType)
+	}
+
+	validator, ok := provider.(core.Validator)
+	if !ok {
+		return false, nil
+	}
+
+	if err := validator.Validate(ctx); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
 func (c *ACMEClient) ObtainCertificate(ctx context.Context, request *ObtainCertificateRequest) (*ObtainCertificateResponse, error) {
 	if request == nil {
 		return nil, fmt.Errorf("the request is nil")
@@ -78,6 +142,7 @@ func (c *ACMEClient) ObtainCertificate(ctx context.Context, request *ObtainCerti
 
 	const CHALLENGE_TYPE_DNS01 = "dns-01"
 	const CHALLENGE_TYPE_HTTP01 = "http-01"
+	const CHALLENGE_TYPE_TLSALPN01 = "tls-alpn-01"
 	switch strings.ToLower(request.ChallengeType) {
 	case CHALLENGE_TYPE_DNS01:
 		{
@@ -131,6 +196,24 @@ func (c *ACMEClient) ObtainCertificate(ctx context.Context, request *ObtainCerti
 			)
 		}
 
+	case CHALLENGE_TYPE_TLSALPN01:
+		{
+			providerFactory, err := certifiers.ACMETlsAlpn01Registries.Get(domain.ACMETlsAlpn01ProviderType(request.Provider))
+			if err != nil {
+				return nil, err
+			}
+
+			provider, err := providerFactory(&certifiers.ProviderFactoryOptions{
+				ProviderAccessConfig:   request.ProviderAccessConfig,
+				ProviderExtendedConfig: request.ProviderExtendedConfig,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("failed to initialize tls-alpn-01 provider '%s': %w", request.Provider, err)
+			}
+
+			c.client.Challenge.SetTLSALPN01Provider(provider)
+		}
+
 	default:
 		return nil, fmt.Errorf("unsupported challenge type: '%s'", request.ChallengeType)
 	}
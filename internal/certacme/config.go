@@ -2,6 +2,7 @@ package certacme
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/certimate-go/certimate/internal/domain"
@@ -14,8 +15,14 @@ type ACMEConfigOptions struct {
 	CAProviderAccessConfig   map[string]any
 	CAProviderExtendedConfig map[string]any
 	CertifierKeyAlgorithm    domain.CertificateKeyAlgorithmType
+
+	// 是否使用证书颁发机构的测试环境。
+	// 证书颁发机构不提供测试环境时，将返回 [ErrStagingNotSupported]。
+	UseStaging bool
 }
 
+var ErrStagingNotSupported = errors.New("the ca provider does not provide a staging environment")
+
 type ACMEConfig struct {
 	CAProvider domain.CAProviderType
 	CADirUrl   string
@@ -42,9 +49,22 @@ func CreateACMEConfig(ctx context.Context, options *ACMEConfigOptions) (*ACMECon
 		provider = domain.CAProviderTypeLetsEncrypt
 	}
 
-	acmeDirUrl, err := getCADirUrl(provider, providerAccessCfg, options.CertifierKeyAlgorithm)
-	if err != nil {
-		return nil, err
+	var acmeDirUrl string
+	if options.UseStaging {
+		stagingProvider, stagingDirUrl, err := getCAStagingDirUrl(provider, providerAccessCfg)
+		if err != nil {
+			return nil, err
+		}
+
+		provider = stagingProvider
+		acmeDirUrl = stagingDirUrl
+	} else {
+		dirUrl, err := getCADirUrl(provider, providerAccessCfg, options.CertifierKeyAlgorithm)
+		if err != nil {
+			return nil, err
+		}
+
+		acmeDirUrl = dirUrl
 	}
 
 	acmeEab := domain.AccessConfigForACMEExternalAccountBinding{}
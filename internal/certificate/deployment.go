@@ -0,0 +1,52 @@
+package certificate
+
+import (
+	"context"
+	"slices"
+
+	"github.com/pocketbase/dbx"
+
+	"github.com/certimate-go/certimate/internal/domain"
+	"github.com/certimate-go/certimate/internal/domain/dtos"
+)
+
+func (s *CertificateService) ListDeployments(ctx context.Context, req *dtos.CertificateListDeploymentsReq) (*dtos.CertificateListDeploymentsResp, error) {
+	conditions := dbx.HashExp{}
+	if req.CertificateId != "" {
+		if _, err := s.certificateRepo.GetById(ctx, req.CertificateId); err != nil {
+			return nil, err
+		}
+
+		conditions["certificateRef"] = req.CertificateId
+	}
+	if req.SerialNumber != "" {
+		conditions["serialNumber"] = req.SerialNumber
+	}
+	if req.Provider != "" {
+		conditions["provider"] = req.Provider
+	}
+	if req.ProviderAccessId != "" {
+		conditions["providerAccessRef"] = req.ProviderAccessId
+	}
+	if req.WorkflowId != "" {
+		conditions["workflowRef"] = req.WorkflowId
+	}
+	if req.OnlyCurrent {
+		conditions["isCurrent"] = true
+	}
+
+	deployments, err := s.certdeployRepo.ListWithExprs(ctx, conditions)
+	if err != nil {
+		return nil, err
+	}
+
+	slices.SortFunc(deployments, func(a, b *domain.CertificateDeployment) int {
+		return b.CreatedAt.Compare(a.CreatedAt)
+	})
+
+	resp := &dtos.CertificateListDeploymentsResp{
+		Items:      deployments,
+		TotalItems: len(deployments),
+	}
+	return resp, nil
+}
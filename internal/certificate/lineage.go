@@ -0,0 +1,187 @@
+package certificate
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+
+	"github.com/pocketbase/dbx"
+
+	"github.com/certimate-go/certimate/internal/domain"
+	"github.com/certimate-go/certimate/internal/domain/dtos"
+)
+
+func (s *CertificateService) GetCertificateLineage(ctx context.Context, req *dtos.CertificateGetLineageReq) (*dtos.CertificateGetLineageResp, error) {
+	// 确定查询的起点证书
+	seeds := make([]*domain.Certificate, 0)
+	if req.CertificateId != "" {
+		certificate, err := s.certificateRepo.GetById(ctx, req.CertificateId)
+		if err != nil {
+			return nil, err
+		}
+
+		seeds = append(seeds, certificate)
+	} else if req.Domain != "" {
+		certificates, err := s.certificateRepo.ListWithExprs(ctx,
+			dbx.Like("subjectAltNames", req.Domain),
+			dbx.HashExp{"deleted": ""},
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, certificate := range certificates {
+			if slices.ContainsFunc(strings.Split(certificate.SubjectAltNames, ";"), func(s string) bool { return strings.EqualFold(s, req.Domain) }) {
+				seeds = append(seeds, certificate)
+			}
+		}
+	} else {
+		return nil, domain.ErrInvalidParams
+	}
+
+	cache := make(map[string]*domain.Certificate)
+	for _, seed := range seeds {
+		cache[seed.Id] = seed
+	}
+
+	// 从每个起点证书出发，沿替换关系还原完整的续期链，同一条链只返回一次
+	visited := make(map[string]struct{})
+	chains := make([]*dtos.CertificateLineageChain, 0)
+	for _, seed := range seeds {
+		if _, ok := visited[seed.Id]; ok {
+			continue
+		}
+
+		certificates, err := s.resolveLineage(ctx, seed, cache)
+		if err != nil {
+			return nil, err
+		}
+
+		chain := &dtos.CertificateLineageChain{
+			Certificates: make([]*dtos.CertificateLineageNode, 0, len(certificates)),
+		}
+		for _, certificate := range certificates {
+			visited[certificate.Id] = struct{}{}
+
+			deployments, err := s.findDeployments(ctx, certificate)
+			if err != nil {
+				return nil, err
+			}
+
+			chain.Certificates = append(chain.Certificates, &dtos.CertificateLineageNode{
+				Id:                certificate.Id,
+				Source:            certificate.Source,
+				SerialNumber:      certificate.SerialNumber,
+				SubjectAltNames:   certificate.SubjectAltNames,
+				IssuerOrg:         certificate.IssuerOrg,
+				ValidityNotBefore: certificate.ValidityNotBefore,
+				ValidityNotAfter:  certificate.ValidityNotAfter,
+				IsRenewed:         certificate.IsRenewed,
+				IsRevoked:         certificate.IsRevoked,
+				PredecessorId:     certificate.PredecessorId,
+				ReplacedById:      certificate.ReplacedById,
+				WorkflowId:        certificate.WorkflowId,
+				WorkflowRunId:     certificate.WorkflowRunId,
+				WorkflowNodeId:    certificate.WorkflowNodeId,
+				CreatedAt:         certificate.CreatedAt,
+				Deployments:       deployments,
+			})
+		}
+
+		chains = append(chains, chain)
+	}
+
+	resp := &dtos.CertificateGetLineageResp{
+		Chains: chains,
+	}
+	return resp, nil
+}
+
+// 还原指定证书所在的续期链，按从旧到新的顺序返回。
+func (s *CertificateService) resolveLineage(ctx context.Context, certificate *domain.Certificate, cache map[string]*domain.Certificate) ([]*domain.Certificate, error) {
+	getCertificate := func(id string) (*domain.Certificate, error) {
+		if c, ok := cache[id]; ok {
+			return c, nil
+		}
+
+		c, err := s.certificateRepo.GetById(ctx, id)
+		if err != nil {
+			if errors.Is(err, domain.ErrRecordNotFound) {
+				// 已被清理或删除的证书视为链的端点
+				return nil, nil
+			}
+			return nil, err
+		}
+
+		cache[id] = c
+		return c, nil
+	}
+
+	visited := map[string]struct{}{certificate.Id: {}}
+
+	predecessors := make([]*domain.Certificate, 0)
+	for current := certificate; current.PredecessorId != ""; {
+		if _, ok := visited[current.PredecessorId]; ok {
+			break
+		}
+
+		predecessor, err := getCertificate(current.PredecessorId)
+		if err != nil {
+			return nil, err
+		} else if predecessor == nil {
+			break
+		}
+
+		visited[predecessor.Id] = struct{}{}
+		predecessors = append(predecessors, predecessor)
+		current = predecessor
+	}
+	slices.Reverse(predecessors)
+
+	successors := make([]*domain.Certificate, 0)
+	for current := certificate; current.ReplacedById != ""; {
+		if _, ok := visited[current.ReplacedById]; ok {
+			break
+		}
+
+		successor, err := getCertificate(current.ReplacedById)
+		if err != nil {
+			return nil, err
+		} else if successor == nil {
+			break
+		}
+
+		visited[successor.Id] = struct{}{}
+		successors = append(successors, successor)
+		current = successor
+	}
+
+	return slices.Concat(predecessors, []*domain.Certificate{certificate}, successors), nil
+}
+
+func (s *CertificateService) findDeployments(ctx context.Context, certificate *domain.Certificate) ([]*dtos.CertificateLineageNodeDeployment, error) {
+	certdeploys, err := s.certdeployRepo.ListWithExprs(ctx, dbx.HashExp{"certificateRef": certificate.Id})
+	if err != nil {
+		return nil, err
+	}
+
+	deployments := make([]*dtos.CertificateLineageNodeDeployment, 0, len(certdeploys))
+	for _, certdeploy := range certdeploys {
+		deployments = append(deployments, &dtos.CertificateLineageNodeDeployment{
+			Provider:         certdeploy.Provider,
+			ProviderAccessId: certdeploy.ProviderAccessId,
+			IsCurrent:        certdeploy.IsCurrent,
+			WorkflowId:       certdeploy.WorkflowId,
+			WorkflowRunId:    certdeploy.WorkflowRunId,
+			WorkflowNodeId:   certdeploy.WorkflowNodeId,
+			DeployedAt:       certdeploy.CreatedAt,
+		})
+	}
+
+	slices.SortFunc(deployments, func(a, b *dtos.CertificateLineageNodeDeployment) int {
+		return a.DeployedAt.Compare(b.DeployedAt)
+	})
+
+	return deployments, nil
+}
@@ -0,0 +1,140 @@
+package certificate
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pocketbase/dbx"
+
+	"github.com/certimate-go/certimate/internal/domain"
+	"github.com/certimate-go/certimate/internal/domain/dtos"
+)
+
+type mockCertificateRepository struct {
+	certificateRepository
+
+	certificates []*domain.Certificate
+}
+
+func (r *mockCertificateRepository) GetById(ctx context.Context, id string) (*domain.Certificate, error) {
+	for _, certificate := range r.certificates {
+		if certificate.Id == id {
+			return certificate, nil
+		}
+	}
+	return nil, domain.ErrRecordNotFound
+}
+
+func (r *mockCertificateRepository) ListWithExprs(ctx context.Context, exprs ...dbx.Expression) ([]*domain.Certificate, error) {
+	return r.certificates, nil
+}
+
+type mockCertificateDeploymentRepository struct {
+	certdeploys []*domain.CertificateDeployment
+}
+
+func (r *mockCertificateDeploymentRepository) ListWithExprs(ctx context.Context, exprs ...dbx.Expression) ([]*domain.CertificateDeployment, error) {
+	certdeploys := make([]*domain.CertificateDeployment, 0)
+	for _, certdeploy := range r.certdeploys {
+		if hash, ok := exprs[0].(dbx.HashExp); ok && hash["certificateRef"] == certdeploy.CertificateId {
+			certdeploys = append(certdeploys, certdeploy)
+		}
+	}
+	return certdeploys, nil
+}
+
+func TestGetCertificateLineage(t *testing.T) {
+	baseTime := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// c0 已被清理，c1 -> c2 -> c3 为同一条续期链，d1 为另一条独立的链
+	certificates := []*domain.Certificate{
+		{Meta: domain.Meta{Id: "c1", CreatedAt: baseTime}, SubjectAltNames: "example.com;www.example.com", PredecessorId: "c0", ReplacedById: "c2"},
+		{Meta: domain.Meta{Id: "c2", CreatedAt: baseTime.AddDate(0, 2, 0)}, SubjectAltNames: "example.com;www.example.com", PredecessorId: "c1", ReplacedById: "c3"},
+		{Meta: domain.Meta{Id: "c3", CreatedAt: baseTime.AddDate(0, 4, 0)}, SubjectAltNames: "example.com;www.example.com", PredecessorId: "c2"},
+		{Meta: domain.Meta{Id: "d1", CreatedAt: baseTime}, SubjectAltNames: "EXAMPLE.com"},
+		{Meta: domain.Meta{Id: "e1", CreatedAt: baseTime}, SubjectAltNames: "sub.example.com"},
+	}
+	certdeploys := []*domain.CertificateDeployment{
+		{Meta: domain.Meta{Id: "x2", CreatedAt: baseTime.AddDate(0, 2, 1)}, CertificateId: "c2", Provider: "local"},
+		{Meta: domain.Meta{Id: "x1", CreatedAt: baseTime.AddDate(0, 0, 1)}, CertificateId: "c2", Provider: "ssh"},
+		{Meta: domain.Meta{Id: "x3", CreatedAt: baseTime.AddDate(0, 4, 1)}, CertificateId: "c3", Provider: "ssh", IsCurrent: true},
+	}
+
+	srv := &CertificateService{
+		certificateRepo: &mockCertificateRepository{certificates: certificates},
+		certdeployRepo:  &mockCertificateDeploymentRepository{certdeploys: certdeploys},
+	}
+
+	chainIds := func(chain *dtos.CertificateLineageChain) []string {
+		ids := make([]string, 0, len(chain.Certificates))
+		for _, node := range chain.Certificates {
+			ids = append(ids, node.Id)
+		}
+		return ids
+	}
+
+	t.Run("By certificate", func(t *testing.T) {
+		resp, err := srv.GetCertificateLineage(context.Background(), &dtos.CertificateGetLineageReq{CertificateId: "c2"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if len(resp.Chains) != 1 {
+			t.Fatalf("expected 1 chain, got %d", len(resp.Chains))
+		}
+		if ids := chainIds(resp.Chains[0]); len(ids) != 3 || ids[0] != "c1" || ids[1] != "c2" || ids[2] != "c3" {
+			t.Errorf("expected chain [c1 c2 c3], got %v", ids)
+		}
+
+		deployments := resp.Chains[0].Certificates[1].Deployments
+		if len(deployments) != 2 || deployments[0].Provider != "ssh" || deployments[1].Provider != "local" {
+			t.Errorf("expected the deployments of 'c2' to be sorted by time, got %v", deployments)
+		}
+		if deployments := resp.Chains[0].Certificates[2].Deployments; len(deployments) != 1 || !deployments[0].IsCurrent {
+			t.Errorf("expected 'c3' to be currently deployed, got %v", deployments)
+		}
+	})
+
+	t.Run("By domain", func(t *testing.T) {
+		resp, err := srv.GetCertificateLineage(context.Background(), &dtos.CertificateGetLineageReq{Domain: "example.com"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		// 同一条链只返回一次，且不包含仅匹配子域名的证书
+		if len(resp.Chains) != 2 {
+			t.Fatalf("expected 2 chains, got %d", len(resp.Chains))
+		}
+		if ids := chainIds(resp.Chains[0]); len(ids) != 3 {
+			t.Errorf("expected chain [c1 c2 c3], got %v", ids)
+		}
+		if ids := chainIds(resp.Chains[1]); len(ids) != 1 || ids[0] != "d1" {
+			t.Errorf("expected chain [d1], got %v", ids)
+		}
+	})
+
+	t.Run("Circular links", func(t *testing.T) {
+		srv := &CertificateService{
+			certificateRepo: &mockCertificateRepository{certificates: []*domain.Certificate{
+				{Meta: domain.Meta{Id: "a"}, PredecessorId: "b", ReplacedById: "b"},
+				{Meta: domain.Meta{Id: "b"}, PredecessorId: "a", ReplacedById: "a"},
+			}},
+			certdeployRepo: &mockCertificateDeploymentRepository{},
+		}
+
+		resp, err := srv.GetCertificateLineage(context.Background(), &dtos.CertificateGetLineageReq{CertificateId: "a"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if ids := chainIds(resp.Chains[0]); len(ids) != 2 {
+			t.Errorf("expected the circular chain to be cut, got %v", ids)
+		}
+	})
+
+	t.Run("Invalid params", func(t *testing.T) {
+		if _, err := srv.GetCertificateLineage(context.Background(), &dtos.CertificateGetLineageReq{}); err == nil {
+			t.Error("expected error, got nil")
+		}
+	})
+}
@@ -4,30 +4,39 @@ import (
 	"archive/zip"
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"math"
 	"strings"
+	"time"
 
 	"github.com/pocketbase/dbx"
+	"github.com/samber/lo"
 
 	"github.com/certimate-go/certimate/internal/app"
 	"github.com/certimate-go/certimate/internal/certacme"
 	"github.com/certimate-go/certimate/internal/domain"
 	"github.com/certimate-go/certimate/internal/domain/dtos"
+	"github.com/certimate-go/certimate/internal/notify"
 	"github.com/certimate-go/certimate/internal/settings"
 	xcert "github.com/certimate-go/certimate/pkg/utils/cert"
 	xcertpfx "github.com/certimate-go/certimate/pkg/utils/cert/pfx"
 )
 
 type CertificateService struct {
+	accessRepo      accessRepository
 	acmeAccountRepo acmeAccountRepository
 	certificateRepo certificateRepository
+	certdeployRepo  certificateDeploymentRepository
 }
 
-func NewCertificateService(acmeAccountRepo acmeAccountRepository, certificateRepo certificateRepository) *CertificateService {
+func NewCertificateService(accessRepo accessRepository, acmeAccountRepo acmeAccountRepository, certificateRepo certificateRepository, certdeployRepo certificateDeploymentRepository) *CertificateService {
 	return &CertificateService{
+		accessRepo:      accessRepo,
 		acmeAccountRepo: acmeAccountRepo,
 		certificateRepo: certificateRepo,
+		certdeployRepo:  certdeployRepo,
 	}
 }
 
@@ -36,6 +45,10 @@ func (s *CertificateService) InitSchedule(ctx context.Context) error {
 		s.cleanupExpiredCertificates(context.Background())
 	})
 
+	app.GetScheduler().MustAdd("alertCertificateExpiring", "0 * * * *", func() {
+		s.alertExpiringCertificates(context.Background())
+	})
+
 	return nil
 }
 
@@ -274,3 +287,118 @@ func (s *CertificateService) cleanupExpiredCertificates(ctx context.Context) err
 
 	return nil
 }
+
+func (s *CertificateService) alertExpiringCertificates(ctx context.Context) error {
+	globalSettingsForExpiryAlert := settings.GetGlobalSettingsForExpiryAlert()
+	if !globalSettingsForExpiryAlert.Enabled || len(globalSettingsForExpiryAlert.Channels) == 0 {
+		return nil
+	}
+
+	thresholds := globalSettingsForExpiryAlert.Thresholds
+	if len(thresholds) == 0 {
+		globalSettingsForPersistence := settings.GetGlobalSettingsForPersistence()
+		thresholds = []int{globalSettingsForPersistence.CertificatesWarningDaysBeforeExpire}
+	}
+
+	// 到期提醒无需私钥，不解密私钥，以免个别记录无法解密时导致所有证书都无法提醒
+	certificates, err := s.certificateRepo.ListWithExprsWithoutPrivateKey(ctx,
+		dbx.NewExp(fmt.Sprintf("validityNotAfter<DATETIME('now', '+%d days')", thresholds[len(thresholds)-1]+1)),
+		dbx.NewExp("validityNotAfter>DATETIME('now')"),
+		dbx.HashExp{"isRenewed": false, "isRevoked": false, "replacedByRef": "", "deleted": ""},
+	)
+	if err != nil {
+		app.GetLogger().Error("failed to list expiring certificates", slog.Any("error", err))
+		return err
+	}
+
+	for _, certificate := range certificates {
+		daysLeft := int(math.Floor(time.Until(certificate.ValidityNotAfter).Hours() / 24))
+
+		// 取不小于剩余天数的最小阈值，每张证书在每个阈值上只提醒一次
+		threshold, ok := lo.Find(thresholds, func(t int) bool { return daysLeft <= t })
+		if !ok {
+			continue
+		}
+		if certificate.ExpiryAlertedDays > 0 && int(certificate.ExpiryAlertedDays) <= threshold {
+			continue
+		}
+
+		if err := s.sendExpiryAlert(ctx, globalSettingsForExpiryAlert, certificate, daysLeft); err != nil {
+			app.GetLogger().Warn(fmt.Sprintf("failed to send expiry alert for certificate #%s", certificate.Id), slog.Any("error", err))
+			continue
+		}
+
+		// 仅更新提醒状态，避免覆盖提醒期间其他地方对证书的修改
+		if err := s.certificateRepo.UpdateExpiryAlertedDays(ctx, certificate.Id, int32(threshold)); err != nil {
+			app.GetLogger().Error(fmt.Sprintf("failed to update certificate #%s", certificate.Id), slog.Any("error", err))
+		}
+	}
+
+	return nil
+}
+
+func (s *CertificateService) sendExpiryAlert(ctx context.Context, config domain.SettingsContentForExpiryAlert, certificate *domain.Certificate, daysLeft int) error {
+	tmplData := map[string]any{
+		"certificate": map[string]any{
+			"id":              certificate.Id,
+			"source":          certificate.Source.String(),
+			"commonName":      certificate.SubjectName,
+			"subjectAltNames": certificate.SubjectAltNames,
+			"serialNumber":    certificate.SerialNumber,
+			"issuerOrg":       certificate.IssuerOrg,
+			"notBefore":       certificate.ValidityNotBefore,
+			"notAfter":        certificate.ValidityNotAfter,
+			"daysLeft":        daysLeft,
+		},
+		"now": time.Now(),
+	}
+
+	subject, err := notify.RenderTemplate(config.Subject, notify.MessageFormatPlain, tmplData)
+	if err != nil {
+		return fmt.Errorf("failed to render subject template: %w", err)
+	}
+
+	// 只要有任一渠道发送成功，即视为已提醒
+	sent := 0
+	errs := make([]error, 0)
+	for _, channel := range config.Channels {
+		providerAccessConfig := make(map[string]any)
+		if channel.ProviderAccessId != "" {
+			if access, err := s.accessRepo.GetById(ctx, channel.ProviderAccessId); err != nil {
+				errs = append(errs, fmt.Errorf("failed to get access #%s record: %w", channel.ProviderAccessId, err))
+				continue
+			} else {
+				providerAccessConfig = access.Config
+			}
+		}
+
+		message, err := notify.RenderTemplate(config.Message, notify.GetMessageFormat(domain.NotificationProviderType(channel.Provider), channel.ProviderConfig), tmplData)
+		if err != nil {
+			return fmt.Errorf("failed to render message template: %w", err)
+		}
+
+		notifier := notify.NewClient(notify.WithLogger(app.GetLogger()))
+		notifyReq := &notify.SendNotificationRequest{
+			Provider:               domain.NotificationProviderType(channel.Provider),
+			ProviderAccessConfig:   providerAccessConfig,
+			ProviderExtendedConfig: channel.ProviderConfig,
+			Subject:                subject,
+			Message:                message,
+		}
+		if _, err := notifier.SendNotification(ctx, notifyReq); err != nil {
+			errs = append(errs, fmt.Errorf("failed to send notification via '%s': %w", channel.Provider, err))
+			continue
+		}
+
+		sent++
+	}
+
+	if sent == 0 {
+		return errors.Join(errs...)
+	}
+	if len(errs) > 0 {
+		app.GetLogger().Warn(fmt.Sprintf("failed to send expiry alert for certificate #%s via some channels", certificate.Id), slog.Any("error", errors.Join(errs...)))
+	}
+
+	return nil
+}
@@ -8,6 +8,10 @@ import (
 	"github.com/certimate-go/certimate/internal/domain"
 )
 
+type accessRepository interface {
+	GetById(ctx context.Context, id string) (*domain.Access, error)
+}
+
 type acmeAccountRepository interface {
 	GetByCAAndAcctUrl(ctx context.Context, ca string, acctUrl string) (*domain.ACMEAccount, error)
 }
@@ -15,5 +19,12 @@ type acmeAccountRepository interface {
 type certificateRepository interface {
 	GetById(ctx context.Context, id string) (*domain.Certificate, error)
 	Save(ctx context.Context, certificate *domain.Certificate) (*domain.Certificate, error)
+	ListWithExprs(ctx context.Context, exprs ...dbx.Expression) ([]*domain.Certificate, error)
+	ListWithExprsWithoutPrivateKey(ctx context.Context, exprs ...dbx.Expression) ([]*domain.Certificate, error)
+	UpdateExpiryAlertedDays(ctx context.Context, id string, days int32) error
 	DeleteWithExprs(ctx context.Context, exprs ...dbx.Expression) (int, error)
 }
+
+type certificateDeploymentRepository interface {
+	ListWithExprs(ctx context.Context, exprs ...dbx.Expression) ([]*domain.CertificateDeployment, error)
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/certimate-go/certimate/internal/certmgmt/deployers"
 	"github.com/certimate-go/certimate/internal/domain"
+	"github.com/certimate-go/certimate/pkg/core"
 )
 
 type DeployCertificateRequest struct {
@@ -46,3 +47,48 @@ func (c *Client) DeployCertificate(ctx context.Context, request *DeployCertifica
 
 	return &DeployCertificateResponse{}, nil
 }
+
+type CheckDeploymentRequest struct {
+	// 提供商相关
+	Provider               domain.DeploymentProviderType
+	ProviderAccessConfig   map[string]any
+	ProviderExtendedConfig map[string]any
+}
+
+type CheckDeploymentResponse struct {
+	// 部署提供商是否支持预检。
+	// 不支持时仅校验了提供商配置，未访问部署目标。
+	Checked bool
+}
+
+// 预检部署，不会对部署目标做出任何变更。
+func (c *Client) CheckDeployment(ctx context.Context, request *CheckDeploymentRequest) (*CheckDeploymentResponse, error) {
+	if request == nil {
+		return nil, fmt.Errorf("the request is nil")
+	}
+
+	providerFactory, err := deployers.Registries.Get(request.Provider)
+	if err != nil {
+		return nil, err
+	}
+
+	provider, err := providerFactory(&deployers.ProviderFactoryOptions{
+		ProviderAccessConfig:   request.ProviderAccessConfig,
+		ProviderExtendedConfig: request.ProviderExtendedConfig,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize deployment provider '%s': %w", request.Provider, err)
+	}
+
+	validator, ok := provider.(core.Validator)
+	if !ok {
+		return &CheckDeploymentResponse{Checked: false}, nil
+	}
+
+	provider.SetLogger(c.logger)
+	if err := validator.Validate(ctx); err != nil {
+		return nil, err
+	}
+
+	return &CheckDeploymentResponse{Checked: true}, nil
+}
@@ -0,0 +1,180 @@
+package discovery
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/certimate-go/certimate/internal/domain"
+)
+
+// 单次扫描中 CIDR 网段展开后允许的最大地址数量。
+const maxAddressesPerCIDR = 65536
+
+type endpoint struct {
+	Host        string
+	Port        int32
+	Protocol    domain.DiscoveryProtocolType
+	ServerNames []string
+}
+
+type portSpec struct {
+	Port     int32
+	Protocol domain.DiscoveryProtocolType
+}
+
+// 常见的需要通过 STARTTLS 升级连接的端口。
+var wellKnownStartTLSPorts = map[int32]domain.DiscoveryProtocolType{
+	21:  domain.DiscoveryProtocolTypeFTP,
+	25:  domain.DiscoveryProtocolTypeSMTP,
+	110: domain.DiscoveryProtocolTypePOP3,
+	143: domain.DiscoveryProtocolTypeIMAP,
+	587: domain.DiscoveryProtocolTypeSMTP,
+}
+
+// 将扫描目标、端口和 SNI 主机名展开为待扫描的端点列表。
+//
+// 入参：
+//   - targets: 主机名、IP 地址或 CIDR 网段。
+//   - ports: 端口，可通过 "端口/协议" 的形式指定协议，如 "25/smtp"。
+//   - serverNames: 额外尝试的 SNI 主机名。
+//
+// 出参：
+//   - 端点列表。
+//   - 错误。
+func expandEndpoints(targets []string, ports []string, serverNames []string) ([]endpoint, error) {
+	portSpecs := make([]portSpec, 0, len(ports))
+	for _, port := range ports {
+		port = strings.TrimSpace(port)
+		if port == "" {
+			continue
+		}
+
+		spec, err := parsePortSpec(port)
+		if err != nil {
+			return nil, err
+		}
+		portSpecs = append(portSpecs, spec)
+	}
+
+	hosts := make([]string, 0, len(targets))
+	for _, target := range targets {
+		target = strings.TrimSpace(target)
+		if target == "" {
+			continue
+		}
+
+		if strings.Contains(target, "/") {
+			addrs, err := expandCIDR(target)
+			if err != nil {
+				return nil, err
+			}
+			hosts = append(hosts, addrs...)
+		} else {
+			hosts = append(hosts, target)
+		}
+	}
+
+	endpoints := make([]endpoint, 0, len(hosts)*len(portSpecs))
+	visited := make(map[string]struct{})
+	for _, host := range hosts {
+		// 对于主机名，以其自身作为 SNI；对于 IP 地址，则先以不带 SNI 的方式获取默认证书
+		sniList := make([]string, 0, len(serverNames)+1)
+		if net.ParseIP(host) == nil {
+			sniList = append(sniList, strings.ToLower(host))
+		} else {
+			sniList = append(sniList, "")
+		}
+		for _, serverName := range serverNames {
+			serverName = strings.ToLower(strings.TrimSpace(serverName))
+			if serverName != "" && serverName != sniList[0] {
+				sniList = append(sniList, serverName)
+			}
+		}
+
+		for _, spec := range portSpecs {
+			key := fmt.Sprintf("%s|%d", host, spec.Port)
+			if _, ok := visited[key]; ok {
+				continue
+			}
+			visited[key] = struct{}{}
+
+			endpoints = append(endpoints, endpoint{
+				Host:        host,
+				Port:        spec.Port,
+				Protocol:    spec.Protocol,
+				ServerNames: sniList,
+			})
+		}
+	}
+
+	return endpoints, nil
+}
+
+func parsePortSpec(s string) (portSpec, error) {
+	portStr, protocolStr, _ := strings.Cut(s, "/")
+
+	port, err := strconv.ParseInt(strings.TrimSpace(portStr), 10, 32)
+	if err != nil || port <= 0 || port > 65535 {
+		return portSpec{}, fmt.Errorf("invalid port '%s'", s)
+	}
+
+	spec := portSpec{Port: int32(port)}
+	switch protocol := domain.DiscoveryProtocolType(strings.ToLower(strings.TrimSpace(protocolStr))); protocol {
+	case "":
+		if p, ok := wellKnownStartTLSPorts[spec.Port]; ok {
+			spec.Protocol = p
+		} else {
+			spec.Protocol = domain.DiscoveryProtocolTypeTLS
+		}
+
+	case domain.DiscoveryProtocolTypeTLS,
+		domain.DiscoveryProtocolTypeSMTP,
+		domain.DiscoveryProtocolTypeIMAP,
+		domain.DiscoveryProtocolTypePOP3,
+		domain.DiscoveryProtocolTypeFTP:
+		spec.Protocol = protocol
+
+	default:
+		return portSpec{}, fmt.Errorf("invalid port '%s': unsupported protocol '%s'", s, protocolStr)
+	}
+
+	return spec, nil
+}
+
+func expandCIDR(cidr string) ([]string, error) {
+	ip, ipnet, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cidr '%s': %w", cidr, err)
+	}
+
+	ones, bits := ipnet.Mask.Size()
+	if bits-ones > 16 {
+		return nil, fmt.Errorf("invalid cidr '%s': too many addresses (max %d)", cidr, maxAddressesPerCIDR)
+	}
+
+	addrs := make([]string, 0, 1<<(bits-ones))
+	for cur := ip.Mask(ipnet.Mask); ipnet.Contains(cur); cur = nextIP(cur) {
+		addrs = append(addrs, cur.String())
+	}
+
+	// 排除 IPv4 网段的网络地址和广播地址
+	if ip.To4() != nil && bits-ones >= 2 && len(addrs) > 2 {
+		addrs = addrs[1 : len(addrs)-1]
+	}
+
+	return addrs, nil
+}
+
+func nextIP(ip net.IP) net.IP {
+	next := make(net.IP, len(ip))
+	copy(next, ip)
+	for i := len(next) - 1; i >= 0; i-- {
+		next[i]++
+		if next[i] != 0 {
+			break
+		}
+	}
+	return next
+}
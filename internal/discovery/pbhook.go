@@ -0,0 +1,33 @@
+package discovery
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+
+	"github.com/certimate-go/certimate/internal/app"
+	"github.com/certimate-go/certimate/internal/domain"
+)
+
+func registerSettingsRecordEvents(svc *DiscoveryService) {
+	pb := app.GetApp()
+	pb.OnRecordAfterCreateSuccess(domain.CollectionNameSettings).BindFunc(func(e *core.RecordEvent) error {
+		onSettingsRecordCreateOrUpdate(svc, e.Record)
+		return e.Next()
+	})
+	pb.OnRecordAfterUpdateSuccess(domain.CollectionNameSettings).BindFunc(func(e *core.RecordEvent) error {
+		onSettingsRecordCreateOrUpdate(svc, e.Record)
+		return e.Next()
+	})
+}
+
+func onSettingsRecordCreateOrUpdate(svc *DiscoveryService, record *core.Record) {
+	if record.GetString("name") != domain.SettingsNameDiscovery {
+		return
+	}
+
+	// 设置变更时，同时更新定时任务
+	content := make(domain.SettingsContent)
+	record.UnmarshalJSONField("content", &content)
+	if err := svc.reloadSchedule(*content.AsDiscovery()); err != nil {
+		app.GetLogger().Error(err.Error())
+	}
+}
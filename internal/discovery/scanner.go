@@ -0,0 +1,182 @@
+package discovery
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/certimate-go/certimate/internal/domain"
+	xtls "github.com/certimate-go/certimate/pkg/utils/tls"
+)
+
+// 连接到指定端点，完成 TLS 握手（必要时先通过 STARTTLS 升级连接），并返回服务端的完整证书链。
+func scanEndpoint(ctx context.Context, ep endpoint, serverName string, timeout time.Duration) ([]*x509.Certificate, string, error) {
+	dialer := &net.Dialer{Timeout: timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(ep.Host, fmt.Sprintf("%d", ep.Port)))
+	if err != nil {
+		return nil, "", &dialError{err}
+	}
+	defer conn.Close()
+
+	remoteAddr := ""
+	if addr, ok := conn.RemoteAddr().(*net.TCPAddr); ok {
+		remoteAddr = addr.IP.String()
+	}
+
+	if err := conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+		return nil, remoteAddr, err
+	}
+
+	conn, err = startTLS(conn, ep.Protocol)
+	if err != nil {
+		return nil, remoteAddr, fmt.Errorf("failed to negotiate starttls: %w", err)
+	}
+
+	tlsConfig := xtls.NewInsecureConfig()
+	tlsConfig.ServerName = serverName
+	tlsConn := tls.Client(conn, tlsConfig)
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		return nil, remoteAddr, fmt.Errorf("failed to perform tls handshake: %w", err)
+	}
+
+	return tlsConn.ConnectionState().PeerCertificates, remoteAddr, nil
+}
+
+// 表示无法建立 TCP 连接的错误，此时无需再以其他 SNI 重试同一端点。
+type dialError struct {
+	err error
+}
+
+func (e *dialError) Error() string {
+	return e.err.Error()
+}
+
+func (e *dialError) Unwrap() error {
+	return e.err
+}
+
+// 在明文连接上协商 STARTTLS，返回可用于后续 TLS 握手的连接。
+// 协商过程中读取应答时可能会预读服务端紧随其后发送的数据，因此返回的连接会先重放这部分缓冲数据。
+func startTLS(conn net.Conn, protocol domain.DiscoveryProtocolType) (net.Conn, error) {
+	if protocol == domain.DiscoveryProtocolTypeTLS || protocol == "" {
+		return conn, nil
+	}
+
+	reader := bufio.NewReader(conn)
+	if err := negotiateStartTLS(conn, reader, protocol); err != nil {
+		return nil, err
+	}
+
+	return &bufferedConn{Conn: conn, reader: reader}, nil
+}
+
+func negotiateStartTLS(conn net.Conn, reader *bufio.Reader, protocol domain.DiscoveryProtocolType) error {
+	switch protocol {
+
+	case domain.DiscoveryProtocolTypeSMTP:
+		if err := readReplyCode(reader, "220"); err != nil {
+			return err
+		}
+		if err := writeLine(conn, "EHLO certimate"); err != nil {
+			return err
+		}
+		if err := readReplyCode(reader, "250"); err != nil {
+			return err
+		}
+		if err := writeLine(conn, "STARTTLS"); err != nil {
+			return err
+		}
+		return readReplyCode(reader, "220")
+
+	case domain.DiscoveryProtocolTypeIMAP:
+		if err := readLinePrefix(reader, "* OK"); err != nil {
+			return err
+		}
+		if err := writeLine(conn, "a001 STARTTLS"); err != nil {
+			return err
+		}
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return err
+			}
+			if strings.HasPrefix(line, "a001 ") {
+				if !strings.HasPrefix(strings.ToUpper(line), "A001 OK") {
+					return fmt.Errorf("unexpected response: %s", strings.TrimSpace(line))
+				}
+				return nil
+			}
+		}
+
+	case domain.DiscoveryProtocolTypePOP3:
+		if err := readLinePrefix(reader, "+OK"); err != nil {
+			return err
+		}
+		if err := writeLine(conn, "STLS"); err != nil {
+			return err
+		}
+		return readLinePrefix(reader, "+OK")
+
+	case domain.DiscoveryProtocolTypeFTP:
+		if err := readReplyCode(reader, "220"); err != nil {
+			return err
+		}
+		if err := writeLine(conn, "AUTH TLS"); err != nil {
+			return err
+		}
+		return readReplyCode(reader, "234")
+
+	default:
+		return fmt.Errorf("unsupported protocol '%s'", protocol)
+	}
+}
+
+// 优先从缓冲区读取数据的连接，避免丢失 STARTTLS 协商期间被预读的数据。
+type bufferedConn struct {
+	net.Conn
+	reader *bufio.Reader
+}
+
+func (c *bufferedConn) Read(b []byte) (int, error) {
+	return c.reader.Read(b)
+}
+
+func writeLine(conn net.Conn, line string) error {
+	_, err := conn.Write([]byte(line + "\r\n"))
+	return err
+}
+
+// 读取 SMTP/FTP 风格的应答（可能为多行，如 "250-..." 直至 "250 ..."），并校验应答码。
+func readReplyCode(reader *bufio.Reader, code string) error {
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return err
+		}
+
+		line = strings.TrimRight(line, "\r\n")
+		if len(line) < 3 || line[:3] != code {
+			return fmt.Errorf("unexpected response: %s", line)
+		}
+		if len(line) == 3 || line[3] == ' ' {
+			return nil
+		}
+	}
+}
+
+func readLinePrefix(reader *bufio.Reader, prefix string) error {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return err
+	}
+
+	if !strings.HasPrefix(strings.ToUpper(line), strings.ToUpper(prefix)) {
+		return fmt.Errorf("unexpected response: %s", strings.TrimSpace(line))
+	}
+	return nil
+}
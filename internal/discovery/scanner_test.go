@@ -0,0 +1,151 @@
+package discovery
+
+import (
+	"bufio"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"io"
+	"math/big"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/certimate-go/certimate/internal/domain"
+)
+
+func newTestTLSCertificate(t *testing.T, commonName string) tls.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: commonName},
+		DNSNames:     []string{commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(24 * time.Hour),
+	}
+	certDER, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	return tls.Certificate{Certificate: [][]byte{certDER}, PrivateKey: key}
+}
+
+// 模拟一个支持 STARTTLS 的 SMTP 服务端。
+func serveTestSMTP(t *testing.T, listener net.Listener, cert tls.Certificate) {
+	conn, err := listener.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	reader := bufio.NewReader(conn)
+	expect := func(want string) bool {
+		line, err := reader.ReadString('\n')
+		if err != nil || !strings.EqualFold(strings.TrimSpace(line), want) {
+			t.Errorf("expected command '%s', got '%s' (error: %v)", want, strings.TrimSpace(line), err)
+			return false
+		}
+		return true
+	}
+
+	io.WriteString(conn, "220 mail.example.com ESMTP\r\n")
+	if !expect("EHLO certimate") {
+		return
+	}
+	io.WriteString(conn, "250-mail.example.com\r\n250-SIZE 10240000\r\n250 STARTTLS\r\n")
+	if !expect("STARTTLS") {
+		return
+	}
+	io.WriteString(conn, "220 Ready to start TLS\r\n")
+
+	tlsConn := tls.Server(conn, &tls.Config{Certificates: []tls.Certificate{cert}})
+	if err := tlsConn.Handshake(); err != nil {
+		t.Errorf("unexpected handshake error: %v", err)
+	}
+}
+
+func TestScanEndpoint(t *testing.T) {
+	t.Run("SMTP STARTTLS", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		defer listener.Close()
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			serveTestSMTP(t, listener, newTestTLSCertificate(t, "mail.example.com"))
+		}()
+
+		addr := listener.Addr().(*net.TCPAddr)
+		ep := endpoint{Host: addr.IP.String(), Port: int32(addr.Port), Protocol: domain.DiscoveryProtocolTypeSMTP}
+		certs, remoteAddr, err := scanEndpoint(context.Background(), ep, "mail.example.com", 5*time.Second)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		<-done
+
+		if remoteAddr != "127.0.0.1" {
+			t.Errorf("expected remote address '127.0.0.1', got '%s'", remoteAddr)
+		}
+		if len(certs) != 1 || certs[0].Subject.CommonName != "mail.example.com" {
+			t.Errorf("expected the certificate of 'mail.example.com', got %v", certs)
+		}
+	})
+
+	t.Run("Replays buffered data", func(t *testing.T) {
+		client, server := net.Pipe()
+		defer client.Close()
+		defer server.Close()
+
+		// 服务端在应答 AUTH TLS 的同时发送了后续数据，这部分数据会被预读进缓冲区
+		go func() {
+			io.WriteString(server, "220 FTP server ready\r\n")
+			bufio.NewReader(server).ReadString('\n')
+			io.WriteString(server, "234 AUTH TLS successful\r\nHELLO")
+		}()
+
+		conn, err := startTLS(client, domain.DiscoveryProtocolTypeFTP)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		buf := make([]byte, 5)
+		if _, err := io.ReadFull(conn, buf); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if string(buf) != "HELLO" {
+			t.Errorf("expected the buffered data 'HELLO', got '%s'", string(buf))
+		}
+	})
+
+	t.Run("Unexpected reply", func(t *testing.T) {
+		client, server := net.Pipe()
+		defer client.Close()
+		defer server.Close()
+
+		go func() {
+			io.WriteString(server, "220 mail.example.com ESMTP\r\n")
+			reader := bufio.NewReader(server)
+			reader.ReadString('\n')
+			io.WriteString(server, "250 mail.example.com\r\n")
+			reader.ReadString('\n')
+			io.WriteString(server, "454 TLS not available\r\n")
+		}()
+
+		if _, err := startTLS(client, domain.DiscoveryProtocolTypeSMTP); err == nil {
+			t.Error("expected error, got nil")
+		}
+	})
+}
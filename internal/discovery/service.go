@@ -0,0 +1,199 @@
+package discovery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pocketbase/pocketbase/tools/cron"
+	"github.com/samber/lo"
+
+	"github.com/certimate-go/certimate/internal/app"
+	"github.com/certimate-go/certimate/internal/domain"
+	"github.com/certimate-go/certimate/internal/settings"
+)
+
+const pbJobKey = "discoverCertificates"
+
+type DiscoveryService struct {
+	certificateRepo           certificateRepository
+	discoveredCertificateRepo discoveredCertificateRepository
+
+	scanning atomic.Bool
+}
+
+func NewDiscoveryService(certificateRepo certificateRepository, discoveredCertificateRepo discoveredCertificateRepository) *DiscoveryService {
+	return &DiscoveryService{
+		certificateRepo:           certificateRepo,
+		discoveredCertificateRepo: discoveredCertificateRepo,
+	}
+}
+
+func (s *DiscoveryService) InitSchedule(ctx context.Context) error {
+	registerSettingsRecordEvents(s)
+
+	return s.reloadSchedule(settings.GetGlobalSettingsForDiscovery())
+}
+
+// 扫描所有已配置的目标，并记录发现的证书。
+func (s *DiscoveryService) Scan(ctx context.Context) error {
+	if !s.scanning.CompareAndSwap(false, true) {
+		return errors.New("another discovery scan is still running")
+	}
+	defer s.scanning.Store(false)
+
+	config := settings.GetGlobalSettingsForDiscovery()
+	endpoints, err := expandEndpoints(config.Targets, config.Ports, config.ServerNames)
+	if err != nil {
+		return err
+	}
+
+	app.GetLogger().Info(fmt.Sprintf("discovery scan started, %d endpoint(s) to scan", len(endpoints)))
+
+	timeout := time.Duration(config.Timeout) * time.Second
+	endpointsCh := make(chan endpoint)
+	resultsCh := make(chan *domain.DiscoveredCertificate)
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < config.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for ep := range endpointsCh {
+				for _, discovered := range s.scanEndpoint(ctx, ep, timeout) {
+					resultsCh <- discovered
+				}
+			}
+		}()
+	}
+
+	go func() {
+		defer close(endpointsCh)
+
+		for _, ep := range endpoints {
+			select {
+			case <-ctx.Done():
+				return
+			case endpointsCh <- ep:
+			}
+		}
+	}()
+
+	go func() {
+		wg.Wait()
+		close(resultsCh)
+	}()
+
+	scannedAt := time.Now()
+	found := 0
+	for discovered := range resultsCh {
+		discovered.LastSeenAt = scannedAt
+		if err := s.saveDiscoveredCertificate(ctx, discovered); err != nil {
+			app.GetLogger().Warn(fmt.Sprintf("failed to save discovered certificate of %s:%d", discovered.Host, discovered.Port), slog.Any("error", err))
+			continue
+		}
+
+		found++
+	}
+
+	app.GetLogger().Info(fmt.Sprintf("discovery scan completed, %d certificate(s) found", found))
+	return ctx.Err()
+}
+
+func (s *DiscoveryService) scanEndpoint(ctx context.Context, ep endpoint, timeout time.Duration) []*domain.DiscoveredCertificate {
+	discovereds := make([]*domain.DiscoveredCertificate, 0, len(ep.ServerNames))
+
+	// 同一端点可能根据 SNI 返回不同的证书，因此需逐个尝试
+	for _, serverName := range ep.ServerNames {
+		if ctx.Err() != nil {
+			break
+		}
+
+		certs, remoteAddr, err := scanEndpoint(ctx, ep, serverName, timeout)
+		if err != nil {
+			var dialErr *dialError
+			if errors.As(err, &dialErr) {
+				// 端口不可达，无需再尝试其他 SNI
+				break
+			}
+
+			app.GetLogger().Debug(fmt.Sprintf("failed to retrieve certificates from %s:%d (sni='%s')", ep.Host, ep.Port, serverName), slog.Any("error", err))
+			continue
+		}
+
+		if len(certs) == 0 {
+			continue
+		}
+
+		discovered := &domain.DiscoveredCertificate{
+			Host:       ep.Host,
+			Address:    remoteAddr,
+			Port:       ep.Port,
+			Protocol:   ep.Protocol,
+			ServerName: serverName,
+		}
+		discovered.PopulateFromX509Chain(certs)
+		discovereds = append(discovereds, discovered)
+	}
+
+	return discovereds
+}
+
+func (s *DiscoveryService) saveDiscoveredCertificate(ctx context.Context, discovered *domain.DiscoveredCertificate) error {
+	existing, err := s.discoveredCertificateRepo.GetByEndpointAndSerialNumber(ctx, discovered.Host, discovered.Port, discovered.ServerName, discovered.SerialNumber)
+	if err != nil && !errors.Is(err, domain.ErrRecordNotFound) {
+		return err
+	}
+
+	if existing != nil {
+		discovered.Id = existing.Id
+		discovered.FirstSeenAt = existing.FirstSeenAt
+	} else {
+		discovered.FirstSeenAt = discovered.LastSeenAt
+	}
+
+	// 通过序列号关联到由 Certimate 管理的证书
+	certificate, err := s.certificateRepo.GetBySerialNumber(ctx, discovered.SerialNumber)
+	if err != nil && !errors.Is(err, domain.ErrRecordNotFound) {
+		return err
+	}
+	if certificate != nil {
+		discovered.CertificateId = certificate.Id
+	} else {
+		discovered.CertificateId = ""
+	}
+
+	_, err = s.discoveredCertificateRepo.Save(ctx, discovered)
+	return err
+}
+
+func (s *DiscoveryService) reloadSchedule(config domain.SettingsContentForDiscovery) error {
+	scheduler := app.GetScheduler()
+
+	if !config.Enabled || len(config.Targets) == 0 {
+		scheduler.Remove(pbJobKey)
+		return nil
+	}
+
+	job, _ := lo.Find(scheduler.Jobs(), func(j *cron.Job) bool { return j.Id() == pbJobKey })
+	if job != nil && job.Expression() == config.Cron {
+		return nil
+	}
+
+	err := scheduler.Add(pbJobKey, config.Cron, func() {
+		if err := s.Scan(context.Background()); err != nil {
+			app.GetLogger().Warn("failed to scan certificates", slog.Any("error", err))
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("failed to add cron job: %w", err)
+	}
+
+	app.GetLogger().Info("registered cron job for certificate discovery", slog.String("cron", config.Cron))
+	return nil
+}
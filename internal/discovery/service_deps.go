@@ -0,0 +1,16 @@
+package discovery
+
+import (
+	"context"
+
+	"github.com/certimate-go/certimate/internal/domain"
+)
+
+type certificateRepository interface {
+	GetBySerialNumber(ctx context.Context, serialNumber string) (*domain.Certificate, error)
+}
+
+type discoveredCertificateRepository interface {
+	GetByEndpointAndSerialNumber(ctx context.Context, host string, port int32, serverName string, serialNumber string) (*domain.DiscoveredCertificate, error)
+	Save(ctx context.Context, discoveredCertificate *domain.DiscoveredCertificate) (*domain.DiscoveredCertificate, error)
+}
@@ -12,6 +12,7 @@ type Access struct {
 	Provider  string         `db:"provider" json:"provider"`
 	Config    map[string]any `db:"config"   json:"config"`
 	Reserve   string         `db:"reserve"  json:"reserve,omitempty"`
+	ManagedBy string         `db:"managedBy" json:"managedBy,omitempty"`
 	DeletedAt *time.Time     `db:"deleted" json:"deleted"`
 }
 
@@ -39,7 +40,8 @@ type AccessConfigForACMEExternalAccountBinding struct {
 
 type AccessConfigForACMECA struct {
 	AccessConfigForACMEExternalAccountBinding
-	Endpoint string `json:"endpoint"`
+	Endpoint        string `json:"endpoint"`
+	StagingEndpoint string `json:"stagingEndpoint,omitempty"` // 测试环境的服务端点，仅试运行时使用
 }
 
 type AccessConfigForACMEDNS struct {
@@ -0,0 +1,32 @@
+package domain
+
+import (
+	"fmt"
+)
+
+// 可移植的授权清单。
+// 授权配置中的敏感信息应使用外部密钥引用，形如 "${secret:<后端>:<引用>}"，而非明文。
+type AccessManifest struct {
+	Version  string         `json:"version"`
+	Kind     string         `json:"kind"`
+	Name     string         `json:"name"`
+	Provider string         `json:"provider"`
+	Config   map[string]any `json:"config"`
+}
+
+func (m *AccessManifest) Verify() error {
+	if m.Version != ManifestVersionV1 {
+		return fmt.Errorf("unsupported manifest version '%s'", m.Version)
+	}
+	if m.Kind != ManifestKindAccess {
+		return fmt.Errorf("unexpected manifest kind '%s'", m.Kind)
+	}
+	if m.Name == "" {
+		return fmt.Errorf("missing name")
+	}
+	if m.Provider == "" {
+		return fmt.Errorf("missing provider")
+	}
+
+	return nil
+}
@@ -3,6 +3,7 @@ package domain
 import (
 	"crypto/x509"
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 
@@ -36,6 +37,9 @@ type Certificate struct {
 	ACMECertificateUrl string                          `db:"acmeCertUrl"       json:"acmeCertUrl"`
 	IsRenewed          bool                            `db:"isRenewed"         json:"isRenewed"`
 	IsRevoked          bool                            `db:"isRevoked"         json:"isRevoked"`
+	ExpiryAlertedDays  int32                           `db:"expiryAlertedDays" json:"expiryAlertedDays"`
+	PredecessorId      string                          `db:"predecessorRef"    json:"predecessorId"`
+	ReplacedById       string                          `db:"replacedByRef"     json:"replacedBy"`
 	WorkflowId         string                          `db:"workflowRef"       json:"workflowId"`
 	WorkflowRunId      string                          `db:"workflowRunRef"    json:"workflowRunId"`
 	WorkflowNodeId     string                          `db:"workflowNodeId"    json:"workflowNodeId"`
@@ -96,6 +100,58 @@ func (c *Certificate) PopulateFromPEM(certPEM, privkeyPEM string) *Certificate {
 	return c
 }
 
+// 为缺失替换关系的证书补全续期链。
+// 同一工作流节点先后生成的证书，按生成时间依次建立替换关系；已存在的替换关系保持不变。
+//
+// 入参：
+//   - certificates: 待处理的证书列表。
+//
+// 出参：
+//   - 替换关系发生变化的证书列表。
+func LinkCertificateLineage(certificates []*Certificate) []*Certificate {
+	groupKeys := make([]string, 0)
+	groups := make(map[string][]*Certificate)
+	for _, certificate := range certificates {
+		if certificate.WorkflowId == "" || certificate.WorkflowNodeId == "" {
+			continue
+		}
+
+		groupKey := certificate.WorkflowId + "/" + certificate.WorkflowNodeId
+		if _, ok := groups[groupKey]; !ok {
+			groupKeys = append(groupKeys, groupKey)
+		}
+		groups[groupKey] = append(groups[groupKey], certificate)
+	}
+
+	changed := make([]*Certificate, 0)
+	markChanged := func(certificate *Certificate) {
+		if !slices.Contains(changed, certificate) {
+			changed = append(changed, certificate)
+		}
+	}
+
+	for _, groupKey := range groupKeys {
+		group := groups[groupKey]
+		slices.SortStableFunc(group, func(a, b *Certificate) int {
+			return a.CreatedAt.Compare(b.CreatedAt)
+		})
+
+		for i := 1; i < len(group); i++ {
+			prev, curr := group[i-1], group[i]
+			if curr.PredecessorId == "" {
+				curr.PredecessorId = prev.Id
+				markChanged(curr)
+			}
+			if prev.ReplacedById == "" {
+				prev.ReplacedById = curr.Id
+				markChanged(prev)
+			}
+		}
+	}
+
+	return changed
+}
+
 type CertificateSourceType string
 
 func (t CertificateSourceType) String() string {
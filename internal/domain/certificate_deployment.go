@@ -0,0 +1,38 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+)
+
+const CollectionNameCertificateDeployment = "certificate_deployment"
+
+type CertificateDeployment struct {
+	Meta
+	CertificateId    string         `db:"certificateRef"    json:"certificateId"`
+	SerialNumber     string         `db:"serialNumber"      json:"serialNumber"`
+	Provider         string         `db:"provider"          json:"provider"`
+	ProviderAccessId string         `db:"providerAccessRef" json:"providerAccessId"`
+	ProviderConfig   map[string]any `db:"providerConfig"    json:"providerConfig"`
+	TargetKey        string         `db:"targetKey"         json:"targetKey"` // 部署目标标识，由提供商、授权及额外配置计算得出
+	IsCurrent        bool           `db:"isCurrent"         json:"isCurrent"` // 是否为该部署目标当前持有的证书
+	WorkflowId       string         `db:"workflowRef"       json:"workflowId"`
+	WorkflowRunId    string         `db:"workflowRunRef"    json:"workflowRunId"`
+	WorkflowNodeId   string         `db:"workflowNodeId"    json:"workflowNodeId"`
+}
+
+// 根据部署提供商、授权及额外配置计算部署目标标识。
+// 相同标识的部署记录指向同一个部署目标，后部署的证书将替换先部署的证书。
+func (d *CertificateDeployment) ComputeTargetKey() string {
+	// 字典序列化时键已有序，可保证结果稳定
+	config, _ := json.Marshal(d.ProviderConfig)
+
+	digest := sha256.New()
+	digest.Write([]byte(d.Provider))
+	digest.Write([]byte{0})
+	digest.Write([]byte(d.ProviderAccessId))
+	digest.Write([]byte{0})
+	digest.Write(config)
+	return hex.EncodeToString(digest.Sum(nil)[:16])
+}
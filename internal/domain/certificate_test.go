@@ -0,0 +1,73 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLinkCertificateLineage(t *testing.T) {
+	baseTime := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	newCertificate := func(id string, workflowId string, nodeId string, days int) *Certificate {
+		return &Certificate{
+			Meta:           Meta{Id: id, CreatedAt: baseTime.AddDate(0, 0, days)},
+			WorkflowId:     workflowId,
+			WorkflowNodeId: nodeId,
+		}
+	}
+
+	// 乱序传入，应按生成时间排序
+	certificates := []*Certificate{
+		newCertificate("c3", "wf1", "apply", 120),
+		newCertificate("c1", "wf1", "apply", 0),
+		newCertificate("c2", "wf1", "apply", 60),
+		newCertificate("d1", "wf1", "upload", 30),
+		newCertificate("e1", "wf2", "apply", 10),
+		newCertificate("e2", "wf2", "apply", 70),
+		newCertificate("m1", "", "", 0),
+		newCertificate("m2", "", "", 1),
+	}
+	changed := LinkCertificateLineage(certificates)
+
+	want := map[string][2]string{
+		"c1": {"", "c2"},
+		"c2": {"c1", "c3"},
+		"c3": {"c2", ""},
+		"d1": {"", ""},
+		"e1": {"", "e2"},
+		"e2": {"e1", ""},
+		"m1": {"", ""},
+		"m2": {"", ""},
+	}
+	for _, certificate := range certificates {
+		if got := [2]string{certificate.PredecessorId, certificate.ReplacedById}; got != want[certificate.Id] {
+			t.Errorf("expected certificate '%s' to be linked as %v, got %v", certificate.Id, want[certificate.Id], got)
+		}
+	}
+	if len(changed) != 5 {
+		t.Errorf("expected 5 certificates to be changed, got %d", len(changed))
+	}
+
+	t.Run("Keep existing links", func(t *testing.T) {
+		c1 := newCertificate("c1", "wf1", "apply", 0)
+		c2 := newCertificate("c2", "wf1", "apply", 60)
+		c3 := newCertificate("c3", "wf1", "apply", 120)
+		c1.ReplacedById = "c2"
+		c2.PredecessorId = "c1"
+		c3.PredecessorId = "x1"
+
+		changed := LinkCertificateLineage([]*Certificate{c1, c2, c3})
+		if len(changed) != 1 || changed[0] != c2 {
+			t.Fatalf("expected only certificate 'c2' to be changed, got %v", changed)
+		}
+		if c2.ReplacedById != "c3" {
+			t.Errorf("expected certificate 'c2' to be replaced by 'c3', got '%s'", c2.ReplacedById)
+		}
+		if c3.PredecessorId != "x1" {
+			t.Errorf("expected the existing predecessor of certificate 'c3' to be kept, got '%s'", c3.PredecessorId)
+		}
+
+		if changed := LinkCertificateLineage([]*Certificate{c1, c2, c3}); len(changed) != 0 {
+			t.Errorf("expected nothing to be changed when linking again, got %d", len(changed))
+		}
+	})
+}
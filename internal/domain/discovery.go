@@ -0,0 +1,78 @@
+package domain
+
+import (
+	"crypto/x509"
+	"strings"
+	"time"
+
+	xcert "github.com/certimate-go/certimate/pkg/utils/cert"
+)
+
+const CollectionNameDiscoveredCertificate = "discovered_certificate"
+
+type DiscoveredCertificate struct {
+	Meta
+	Host              string                      `db:"host"              json:"host"`
+	Address           string                      `db:"address"           json:"address"`
+	Port              int32                       `db:"port"              json:"port"`
+	Protocol          DiscoveryProtocolType       `db:"protocol"          json:"protocol"`
+	ServerName        string                      `db:"serverName"        json:"serverName"`
+	SerialNumber      string                      `db:"serialNumber"      json:"serialNumber"`
+	SubjectName       string                      `db:"subjectName"       json:"subjectName"`
+	SubjectAltNames   string                      `db:"subjectAltNames"   json:"subjectAltNames"`
+	IssuerName        string                      `db:"issuerName"        json:"issuerName"`
+	IssuerOrg         string                      `db:"issuerOrg"         json:"issuerOrg"`
+	KeyAlgorithm      CertificateKeyAlgorithmType `db:"keyAlgorithm"      json:"keyAlgorithm"`
+	ValidityNotBefore time.Time                   `db:"validityNotBefore" json:"validityNotBefore"`
+	ValidityNotAfter  time.Time                   `db:"validityNotAfter"  json:"validityNotAfter"`
+	Certificate       string                      `db:"certificate"       json:"certificate"`
+	ChainLength       int32                       `db:"chainLength"       json:"chainLength"`
+	CertificateId     string                      `db:"certificateRef"    json:"certificateId"`
+	FirstSeenAt       time.Time                   `db:"firstSeenAt"       json:"firstSeenAt"`
+	LastSeenAt        time.Time                   `db:"lastSeenAt"        json:"lastSeenAt"`
+}
+
+// 从服务端返回的证书链中填充证书信息。
+// 证书链中的第一个证书为服务器证书，完整的证书链将以 PEM 格式保存。
+func (c *DiscoveredCertificate) PopulateFromX509Chain(certX509s []*x509.Certificate) *DiscoveredCertificate {
+	if len(certX509s) == 0 {
+		return c
+	}
+
+	leaf := (&Certificate{}).PopulateFromX509(certX509s[0])
+	c.SerialNumber = leaf.SerialNumber
+	c.SubjectName = leaf.SubjectName
+	c.SubjectAltNames = leaf.SubjectAltNames
+	c.IssuerName = leaf.IssuerName
+	c.IssuerOrg = leaf.IssuerOrg
+	c.KeyAlgorithm = leaf.KeyAlgorithm
+	c.ValidityNotBefore = leaf.ValidityNotBefore
+	c.ValidityNotAfter = leaf.ValidityNotAfter
+
+	var chainPEM strings.Builder
+	for _, certX509 := range certX509s {
+		certPEM, err := xcert.ConvertCertificateToPEM(certX509)
+		if err != nil {
+			continue
+		}
+		chainPEM.WriteString(certPEM)
+	}
+	c.Certificate = chainPEM.String()
+	c.ChainLength = int32(len(certX509s))
+
+	return c
+}
+
+type DiscoveryProtocolType string
+
+const (
+	DiscoveryProtocolTypeTLS  = DiscoveryProtocolType("tls")
+	DiscoveryProtocolTypeSMTP = DiscoveryProtocolType("smtp")
+	DiscoveryProtocolTypeIMAP = DiscoveryProtocolType("imap")
+	DiscoveryProtocolTypePOP3 = DiscoveryProtocolType("pop3")
+	DiscoveryProtocolTypeFTP  = DiscoveryProtocolType("ftp")
+)
+
+func (t DiscoveryProtocolType) String() string {
+	return string(t)
+}
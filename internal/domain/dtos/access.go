@@ -0,0 +1,10 @@
+package dtos
+
+type AccessTestReq struct {
+	AccessId string `json:"-"`
+}
+
+type AccessTestResp struct {
+	Supported bool   `json:"supported"`         // 授权提供商是否支持校验凭据。不支持时未做任何校验，不代表凭据有效
+	Message   string `json:"message,omitempty"` // 提示信息
+}
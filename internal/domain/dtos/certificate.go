@@ -1,6 +1,8 @@
 package dtos
 
 import (
+	"time"
+
 	"github.com/certimate-go/certimate/internal/domain"
 )
 
@@ -23,3 +25,59 @@ type CertificateRevokeReq struct {
 }
 
 type CertificateRevokeResp struct{}
+
+type CertificateGetLineageReq struct {
+	CertificateId string `json:"-"`
+	Domain        string `json:"-"`
+}
+
+type CertificateGetLineageResp struct {
+	Chains []*CertificateLineageChain `json:"chains"`
+}
+
+type CertificateLineageChain struct {
+	Certificates []*CertificateLineageNode `json:"certificates"`
+}
+
+type CertificateLineageNode struct {
+	Id                string                              `json:"id"`
+	Source            domain.CertificateSourceType        `json:"source"`
+	SerialNumber      string                              `json:"serialNumber"`
+	SubjectAltNames   string                              `json:"subjectAltNames"`
+	IssuerOrg         string                              `json:"issuerOrg"`
+	ValidityNotBefore time.Time                           `json:"validityNotBefore"`
+	ValidityNotAfter  time.Time                           `json:"validityNotAfter"`
+	IsRenewed         bool                                `json:"isRenewed"`
+	IsRevoked         bool                                `json:"isRevoked"`
+	PredecessorId     string                              `json:"predecessorId"`
+	ReplacedById      string                              `json:"replacedBy"`
+	WorkflowId        string                              `json:"workflowId"`
+	WorkflowRunId     string                              `json:"workflowRunId"`
+	WorkflowNodeId    string                              `json:"workflowNodeId"`
+	CreatedAt         time.Time                           `json:"created"`
+	Deployments       []*CertificateLineageNodeDeployment `json:"deployments"`
+}
+
+type CertificateLineageNodeDeployment struct {
+	Provider         string    `json:"provider"`
+	ProviderAccessId string    `json:"providerAccessId"`
+	IsCurrent        bool      `json:"isCurrent"`
+	WorkflowId       string    `json:"workflowId"`
+	WorkflowRunId    string    `json:"workflowRunId"`
+	WorkflowNodeId   string    `json:"workflowNodeId"`
+	DeployedAt       time.Time `json:"deployedAt"`
+}
+
+type CertificateListDeploymentsReq struct {
+	CertificateId    string `json:"-"`
+	SerialNumber     string `json:"-"`
+	Provider         string `json:"-"`
+	ProviderAccessId string `json:"-"`
+	WorkflowId       string `json:"-"`
+	OnlyCurrent      bool   `json:"-"`
+}
+
+type CertificateListDeploymentsResp struct {
+	Items      []*domain.CertificateDeployment `json:"items"`
+	TotalItems int                             `json:"totalItems"`
+}
@@ -0,0 +1,18 @@
+package dtos
+
+type GitOpsSyncReq struct {
+	DryRun bool `json:"dryRun"`
+}
+
+type GitOpsSyncResp struct {
+	DryRun  bool            `json:"dryRun"`
+	Changes []*GitOpsChange `json:"changes"`
+}
+
+type GitOpsChange struct {
+	Kind   string   `json:"kind"`
+	Name   string   `json:"name"`
+	Action string   `json:"action"`
+	Fields []string `json:"fields,omitempty"`
+	Source string   `json:"source,omitempty"`
+}
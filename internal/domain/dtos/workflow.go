@@ -7,6 +7,8 @@ import (
 type WorkflowStartRunReq struct {
 	WorkflowId string                     `json:"-"`
 	RunTrigger domain.WorkflowTriggerType `json:"trigger"`
+	RunPayload map[string]any             `json:"-"`
+	DryRun     bool                       `json:"dryRun,omitempty"`
 }
 
 type WorkflowStartRunResp struct {
@@ -20,8 +22,59 @@ type WorkflowCancelRunReq struct {
 
 type WorkflowCancelRunResp struct{}
 
+type WorkflowResumeRunReq struct {
+	WorkflowId string `json:"-"`
+	RunId      string `json:"-"`
+}
+
+type WorkflowResumeRunResp struct {
+	RunId string `json:"runId"`
+}
+
+type WorkflowApproveRunReq struct {
+	WorkflowId string `json:"-"`
+	RunId      string `json:"-"`
+	Token      string `json:"-"`
+	Approved   bool   `json:"-"`
+	Authorized bool   `json:"-"` // 是否已通过管理员鉴权，否则需校验审批令牌
+	Comment    string `json:"comment,omitempty"`
+}
+
+type WorkflowApproveRunResp struct{}
+
+type WorkflowTriggerByWebhookReq struct {
+	WorkflowId string `json:"-"`
+	Timestamp  string `json:"-"`
+	Signature  string `json:"-"`
+	Payload    []byte `json:"-"`
+}
+
+type WorkflowTriggerByWebhookResp struct {
+	RunId string `json:"runId"`
+}
+
 type WorkflowStatisticsResp struct {
 	Concurrency      int      `json:"concurrency"`
 	PendingRunIds    []string `json:"pendingRunIds"`
 	ProcessingRunIds []string `json:"processingRunIds"`
 }
+
+type WorkflowExportReq struct {
+	WorkflowId string `json:"-"`
+	Format     string `json:"-"`
+}
+
+type WorkflowExportResp struct {
+	Format  string `json:"format"`
+	Content string `json:"content"`
+}
+
+type WorkflowImportReq struct {
+	WorkflowId string            `json:"workflowId,omitempty"`
+	Content    string            `json:"content"`
+	Inputs     map[string]string `json:"inputs,omitempty"`
+}
+
+type WorkflowImportResp struct {
+	WorkflowId string `json:"workflowId"`
+}
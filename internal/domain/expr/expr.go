@@ -1,15 +1,22 @@
 package expr
 
 import (
+	"bytes"
+	"cmp"
 	"encoding/json"
 	"fmt"
+	"math"
+	"reflect"
 	"strconv"
+	"strings"
+	"time"
 )
 
 type (
 	ExprType               string
 	ExprComparisonOperator string
 	ExprLogicalOperator    string
+	ExprArithmeticOperator string
 	ExprValueType          string
 )
 
@@ -20,20 +27,33 @@ const (
 	LessOrEqual    ExprComparisonOperator = "lte"
 	Equal          ExprComparisonOperator = "eq"
 	NotEqual       ExprComparisonOperator = "neq"
+	Is             ExprComparisonOperator = "is" // 等同于 "eq"，仅为兼容旧版而保留
+	In             ExprComparisonOperator = "in"
 
 	And ExprLogicalOperator = "and"
 	Or  ExprLogicalOperator = "or"
 	Not ExprLogicalOperator = "not"
 
-	Number  ExprValueType = "number"
-	String  ExprValueType = "string"
-	Boolean ExprValueType = "boolean"
+	Add      ExprArithmeticOperator = "add"
+	Subtract ExprArithmeticOperator = "sub"
+	Multiply ExprArithmeticOperator = "mul"
+	Divide   ExprArithmeticOperator = "div"
+	Modulo   ExprArithmeticOperator = "mod"
+
+	Number   ExprValueType = "number"
+	String   ExprValueType = "string"
+	Boolean  ExprValueType = "boolean"
+	DateTime ExprValueType = "datetime"
+	List     ExprValueType = "list"
 
 	ConstantExprType   ExprType = "const"
 	VariantExprType    ExprType = "var"
 	ComparisonExprType ExprType = "comparison"
 	LogicalExprType    ExprType = "logical"
 	NotExprType        ExprType = "not"
+	ArithmeticExprType ExprType = "arithmetic"
+	ListExprType       ExprType = "list"
+	CallExprType       ExprType = "call"
 )
 
 type EvalResult struct {
@@ -46,16 +66,25 @@ func (e *EvalResult) GetFloat64() (float64, error) {
 		return 0, fmt.Errorf("type mismatch: %s", e.Type)
 	}
 
-	stringValue, ok := e.Value.(string)
-	if !ok {
-		return 0, fmt.Errorf("value is not a string: %v", e.Value)
+	if stringValue, ok := e.Value.(string); ok {
+		floatValue, err := strconv.ParseFloat(stringValue, 64)
+		if err != nil {
+			return 0, fmt.Errorf("failed to parse float64: %w", err)
+		}
+		return floatValue, nil
 	}
 
-	floatValue, err := strconv.ParseFloat(stringValue, 64)
-	if err != nil {
-		return 0, fmt.Errorf("failed to parse float64: %w", err)
+	rv := reflect.ValueOf(e.Value)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), nil
 	}
-	return floatValue, nil
+
+	return 0, fmt.Errorf("value is not a number: %v", e.Value)
 }
 
 func (e *EvalResult) GetBool() (bool, error) {
@@ -81,98 +110,195 @@ func (e *EvalResult) GetBool() (bool, error) {
 	return boolValue, nil
 }
 
-func (e *EvalResult) GreaterThan(other *EvalResult) (*EvalResult, error) {
-	if e.Type != other.Type {
-		return nil, fmt.Errorf("type mismatch: %s vs %s", e.Type, other.Type)
+func (e *EvalResult) GetString() (string, error) {
+	if e.Type != String {
+		return "", fmt.Errorf("type mismatch: %s", e.Type)
 	}
 
-	switch e.Type {
-	case String:
-		return &EvalResult{
-			Type:  Boolean,
-			Value: e.Value.(string) > other.Value.(string),
-		}, nil
+	switch value := e.Value.(type) {
+	case string:
+		return value, nil
+	case time.Time:
+		return value.Format(time.RFC3339), nil
+	case fmt.Stringer:
+		return value.String(), nil
+	default:
+		return fmt.Sprintf("%v", value), nil
+	}
+}
 
-	case Number:
-		left, err := e.GetFloat64()
-		if err != nil {
-			return nil, err
-		}
+func (e *EvalResult) GetTime() (time.Time, error) {
+	if e.Type != DateTime {
+		return time.Time{}, fmt.Errorf("type mismatch: %s", e.Type)
+	}
 
-		right, err := other.GetFloat64()
-		if err != nil {
-			return nil, err
+	switch value := e.Value.(type) {
+	case time.Time:
+		return value, nil
+	case *time.Time:
+		if value == nil {
+			return time.Time{}, nil
 		}
+		return *value, nil
+	case string:
+		return parseTime(value)
+	default:
+		return time.Time{}, fmt.Errorf("value is not a datetime: %v", e.Value)
+	}
+}
 
-		return &EvalResult{
-			Type:  Boolean,
-			Value: left > right,
-		}, nil
+func (e *EvalResult) GetList() ([]*EvalResult, error) {
+	if e.Type != List {
+		return nil, fmt.Errorf("type mismatch: %s", e.Type)
+	}
 
-	default:
-		return nil, fmt.Errorf("unsupported value type: %s", e.Type)
+	if items, ok := e.Value.([]*EvalResult); ok {
+		return items, nil
+	}
+
+	rv := reflect.ValueOf(e.Value)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return nil, fmt.Errorf("value is not a list: %v", e.Value)
+	}
+
+	items := make([]*EvalResult, 0, rv.Len())
+	for i := 0; i < rv.Len(); i++ {
+		item := rv.Index(i).Interface()
+		items = append(items, &EvalResult{Type: inferValueType(item), Value: item})
+	}
+	return items, nil
+}
+
+func (e *EvalResult) GreaterThan(other *EvalResult) (*EvalResult, error) {
+	res, err := e.compare(other)
+	if err != nil {
+		return nil, err
 	}
+
+	return &EvalResult{
+		Type:  Boolean,
+		Value: res > 0,
+	}, nil
 }
 
 func (e *EvalResult) GreaterOrEqual(other *EvalResult) (*EvalResult, error) {
-	if e.Type != other.Type {
-		return nil, fmt.Errorf("type mismatch: %s vs %s", e.Type, other.Type)
+	res, err := e.compare(other)
+	if err != nil {
+		return nil, err
 	}
 
-	switch e.Type {
-	case String:
-		return &EvalResult{
-			Type:  Boolean,
-			Value: e.Value.(string) >= other.Value.(string),
-		}, nil
+	return &EvalResult{
+		Type:  Boolean,
+		Value: res >= 0,
+	}, nil
+}
 
-	case Number:
-		left, err := e.GetFloat64()
+func (e *EvalResult) LessThan(other *EvalResult) (*EvalResult, error) {
+	res, err := e.compare(other)
+	if err != nil {
+		return nil, err
+	}
+
+	return &EvalResult{
+		Type:  Boolean,
+		Value: res < 0,
+	}, nil
+}
+
+func (e *EvalResult) LessOrEqual(other *EvalResult) (*EvalResult, error) {
+	res, err := e.compare(other)
+	if err != nil {
+		return nil, err
+	}
+
+	return &EvalResult{
+		Type:  Boolean,
+		Value: res <= 0,
+	}, nil
+}
+
+func (e *EvalResult) Equal(other *EvalResult) (*EvalResult, error) {
+	res, err := e.equals(other)
+	if err != nil {
+		return nil, err
+	}
+
+	return &EvalResult{
+		Type:  Boolean,
+		Value: res,
+	}, nil
+}
+
+func (e *EvalResult) NotEqual(other *EvalResult) (*EvalResult, error) {
+	res, err := e.equals(other)
+	if err != nil {
+		return nil, err
+	}
+
+	return &EvalResult{
+		Type:  Boolean,
+		Value: !res,
+	}, nil
+}
+
+func (e *EvalResult) In(other *EvalResult) (*EvalResult, error) {
+	switch other.Type {
+	case List:
+		items, err := other.GetList()
 		if err != nil {
 			return nil, err
 		}
 
-		right, err := other.GetFloat64()
+		for _, item := range items {
+			// 列表中的元素类型不一致时，视为不相等
+			if res, err := e.equals(item); err == nil && res {
+				return &EvalResult{Type: Boolean, Value: true}, nil
+			}
+		}
+
+		return &EvalResult{Type: Boolean, Value: false}, nil
+
+	case String:
+		left, err := e.GetString()
+		if err != nil {
+			return nil, err
+		}
+
+		right, err := other.GetString()
 		if err != nil {
 			return nil, err
 		}
 
 		return &EvalResult{
 			Type:  Boolean,
-			Value: left >= right,
+			Value: strings.Contains(right, left),
 		}, nil
 
 	default:
-		return nil, fmt.Errorf("unsupported value type: %s", e.Type)
+		return nil, fmt.Errorf("unsupported value type: %s", other.Type)
 	}
 }
 
-func (e *EvalResult) LessThan(other *EvalResult) (*EvalResult, error) {
+func (e *EvalResult) And(other *EvalResult) (*EvalResult, error) {
 	if e.Type != other.Type {
 		return nil, fmt.Errorf("type mismatch: %s vs %s", e.Type, other.Type)
 	}
 
 	switch e.Type {
-	case String:
-		return &EvalResult{
-			Type:  Boolean,
-			Value: e.Value.(string) < other.Value.(string),
-		}, nil
-
-	case Number:
-		left, err := e.GetFloat64()
+	case Boolean:
+		left, err := e.GetBool()
 		if err != nil {
 			return nil, err
 		}
 
-		right, err := other.GetFloat64()
+		right, err := other.GetBool()
 		if err != nil {
 			return nil, err
 		}
 
 		return &EvalResult{
 			Type:  Boolean,
-			Value: left < right,
+			Value: left && right,
 		}, nil
 
 	default:
@@ -180,52 +306,54 @@ func (e *EvalResult) LessThan(other *EvalResult) (*EvalResult, error) {
 	}
 }
 
-func (e *EvalResult) LessOrEqual(other *EvalResult) (*EvalResult, error) {
+func (e *EvalResult) Or(other *EvalResult) (*EvalResult, error) {
 	if e.Type != other.Type {
 		return nil, fmt.Errorf("type mismatch: %s vs %s", e.Type, other.Type)
 	}
 
 	switch e.Type {
-	case String:
-		return &EvalResult{
-			Type:  Boolean,
-			Value: e.Value.(string) <= other.Value.(string),
-		}, nil
-
-	case Number:
-		left, err := e.GetFloat64()
+	case Boolean:
+		left, err := e.GetBool()
 		if err != nil {
 			return nil, err
 		}
 
-		right, err := other.GetFloat64()
+		right, err := other.GetBool()
 		if err != nil {
 			return nil, err
 		}
 
 		return &EvalResult{
 			Type:  Boolean,
-			Value: left <= right,
+			Value: left || right,
 		}, nil
-
 	default:
 		return nil, fmt.Errorf("unsupported value type: %s", e.Type)
 	}
 }
 
-func (e *EvalResult) Equal(other *EvalResult) (*EvalResult, error) {
-	if e.Type != other.Type {
-		return nil, fmt.Errorf("type mismatch: %s vs %s", e.Type, other.Type)
+func (e *EvalResult) Not() (*EvalResult, error) {
+	if e.Type != Boolean {
+		return nil, fmt.Errorf("type mismatch: %s", e.Type)
 	}
 
-	switch e.Type {
-	case String:
-		return &EvalResult{
-			Type:  Boolean,
-			Value: e.Value.(string) == other.Value.(string),
-		}, nil
+	boolValue, err := e.GetBool()
+	if err != nil {
+		return nil, err
+	}
 
-	case Number:
+	return &EvalResult{
+		Type:  Boolean,
+		Value: !boolValue,
+	}, nil
+}
+
+// 算术运算。
+// 数值之间支持加减乘除取余；字符串之间支持拼接；
+// 日期时间可加减数值（单位：秒），两个日期时间相减得到相差的秒数。
+func (e *EvalResult) Arithmetic(operator ExprArithmeticOperator, other *EvalResult) (*EvalResult, error) {
+	switch {
+	case e.Type == Number && other.Type == Number:
 		left, err := e.GetFloat64()
 		if err != nil {
 			return nil, err
@@ -236,46 +364,59 @@ func (e *EvalResult) Equal(other *EvalResult) (*EvalResult, error) {
 			return nil, err
 		}
 
+		var value float64
+		switch operator {
+		case Add:
+			value = left + right
+		case Subtract:
+			value = left - right
+		case Multiply:
+			value = left * right
+		case Divide:
+			if right == 0 {
+				return nil, fmt.Errorf("division by zero")
+			}
+			value = left / right
+		case Modulo:
+			if right == 0 {
+				return nil, fmt.Errorf("division by zero")
+			}
+			value = math.Mod(left, right)
+		default:
+			return nil, fmt.Errorf("unknown expression operator: %s", operator)
+		}
+
 		return &EvalResult{
-			Type:  Boolean,
-			Value: left == right,
+			Type:  Number,
+			Value: value,
 		}, nil
 
-	case Boolean:
-		left, err := e.GetBool()
+	case e.Type == String && other.Type == String:
+		if operator != Add {
+			return nil, fmt.Errorf("unsupported operator '%s' for value type: %s", operator, e.Type)
+		}
+
+		left, err := e.GetString()
 		if err != nil {
 			return nil, err
 		}
 
-		right, err := other.GetBool()
+		right, err := other.GetString()
 		if err != nil {
 			return nil, err
 		}
 
 		return &EvalResult{
-			Type:  Boolean,
-			Value: left == right,
+			Type:  String,
+			Value: left + right,
 		}, nil
 
-	default:
-		return nil, fmt.Errorf("unsupported value type: %s", e.Type)
-	}
-}
-
-func (e *EvalResult) NotEqual(other *EvalResult) (*EvalResult, error) {
-	if e.Type != other.Type {
-		return nil, fmt.Errorf("type mismatch: %s vs %s", e.Type, other.Type)
-	}
-
-	switch e.Type {
-	case String:
-		return &EvalResult{
-			Type:  Boolean,
-			Value: e.Value.(string) != other.Value.(string),
-		}, nil
+	case e.Type == DateTime && other.Type == Number:
+		if operator != Add && operator != Subtract {
+			return nil, fmt.Errorf("unsupported operator '%s' for value type: %s", operator, e.Type)
+		}
 
-	case Number:
-		left, err := e.GetFloat64()
+		left, err := e.GetTime()
 		if err != nil {
 			return nil, err
 		}
@@ -285,99 +426,138 @@ func (e *EvalResult) NotEqual(other *EvalResult) (*EvalResult, error) {
 			return nil, err
 		}
 
+		duration := time.Duration(right * float64(time.Second))
+		if operator == Subtract {
+			duration = -duration
+		}
+
 		return &EvalResult{
-			Type:  Boolean,
-			Value: left != right,
+			Type:  DateTime,
+			Value: left.Add(duration),
 		}, nil
 
-	case Boolean:
-		left, err := e.GetBool()
+	case e.Type == DateTime && other.Type == DateTime:
+		if operator != Subtract {
+			return nil, fmt.Errorf("unsupported operator '%s' for value type: %s", operator, e.Type)
+		}
+
+		left, err := e.GetTime()
 		if err != nil {
 			return nil, err
 		}
 
-		right, err := other.GetBool()
+		right, err := other.GetTime()
 		if err != nil {
 			return nil, err
 		}
 
 		return &EvalResult{
-			Type:  Boolean,
-			Value: left != right,
+			Type:  Number,
+			Value: left.Sub(right).Seconds(),
 		}, nil
 
 	default:
-		return nil, fmt.Errorf("unsupported value type: %s", e.Type)
+		return nil, fmt.Errorf("type mismatch: %s vs %s", e.Type, other.Type)
 	}
 }
 
-func (e *EvalResult) And(other *EvalResult) (*EvalResult, error) {
-	if e.Type != other.Type {
-		return nil, fmt.Errorf("type mismatch: %s vs %s", e.Type, other.Type)
+func (e *EvalResult) compare(other *EvalResult) (int, error) {
+	left, right, err := coerceEvalResults(e, other)
+	if err != nil {
+		return 0, err
 	}
 
-	switch e.Type {
-	case Boolean:
-		left, err := e.GetBool()
+	switch left.Type {
+	case String:
+		l, err := left.GetString()
 		if err != nil {
-			return nil, err
+			return 0, err
 		}
 
-		right, err := other.GetBool()
+		r, err := right.GetString()
 		if err != nil {
-			return nil, err
+			return 0, err
 		}
 
-		return &EvalResult{
-			Type:  Boolean,
-			Value: left && right,
-		}, nil
+		return strings.Compare(l, r), nil
+
+	case Number:
+		l, err := left.GetFloat64()
+		if err != nil {
+			return 0, err
+		}
+
+		r, err := right.GetFloat64()
+		if err != nil {
+			return 0, err
+		}
+
+		return cmp.Compare(l, r), nil
+
+	case DateTime:
+		l, err := left.GetTime()
+		if err != nil {
+			return 0, err
+		}
+
+		r, err := right.GetTime()
+		if err != nil {
+			return 0, err
+		}
+
+		return l.Compare(r), nil
 
 	default:
-		return nil, fmt.Errorf("unsupported value type: %s", e.Type)
+		return 0, fmt.Errorf("unsupported value type: %s", left.Type)
 	}
 }
 
-func (e *EvalResult) Or(other *EvalResult) (*EvalResult, error) {
-	if e.Type != other.Type {
-		return nil, fmt.Errorf("type mismatch: %s vs %s", e.Type, other.Type)
+func (e *EvalResult) equals(other *EvalResult) (bool, error) {
+	left, right, err := coerceEvalResults(e, other)
+	if err != nil {
+		return false, err
 	}
 
-	switch e.Type {
-	case Boolean:
-		left, err := e.GetBool()
+	if left.Type == Boolean {
+		l, err := left.GetBool()
 		if err != nil {
-			return nil, err
+			return false, err
 		}
 
-		right, err := other.GetBool()
+		r, err := right.GetBool()
 		if err != nil {
-			return nil, err
+			return false, err
 		}
 
-		return &EvalResult{
-			Type:  Boolean,
-			Value: left || right,
-		}, nil
-	default:
-		return nil, fmt.Errorf("unsupported value type: %s", e.Type)
+		return l == r, nil
+	}
+
+	res, err := left.compare(right)
+	if err != nil {
+		return false, err
 	}
+
+	return res == 0, nil
 }
 
-func (e *EvalResult) Not() (*EvalResult, error) {
-	if e.Type != Boolean {
-		return nil, fmt.Errorf("type mismatch: %s", e.Type)
+func coerceEvalResults(left, right *EvalResult) (*EvalResult, *EvalResult, error) {
+	if left.Type == right.Type {
+		return left, right, nil
 	}
 
-	boolValue, err := e.GetBool()
-	if err != nil {
-		return nil, err
+	// 日期时间可与字符串进行比较，字符串将被解析为日期时间
+	if left.Type == DateTime && right.Type == String {
+		if s, ok := right.Value.(string); ok {
+			if t, err := parseTime(s); err == nil {
+				return left, &EvalResult{Type: DateTime, Value: t}, nil
+			}
+		}
+	} else if left.Type == String && right.Type == DateTime {
+		r, l, err := coerceEvalResults(right, left)
+		return l, r, err
 	}
 
-	return &EvalResult{
-		Type:  Boolean,
-		Value: !boolValue,
-	}, nil
+	return nil, nil, fmt.Errorf("type mismatch: %s vs %s", left.Type, right.Type)
 }
 
 type Expr interface {
@@ -386,9 +566,9 @@ type Expr interface {
 }
 
 type ExprValueSelector struct {
-	Id   string        `json:"id"`
+	Id   string        `json:"id"` // 零值时表示全局变量，否则表示指定节点的变量
 	Name string        `json:"name"`
-	Type ExprValueType `json:"type"`
+	Type ExprValueType `json:"type,omitempty"` // 零值时将根据变量值自动推断
 }
 
 type ConstantExpr struct {
@@ -406,6 +586,39 @@ func (c ConstantExpr) Eval(variables map[string]map[string]any) (*EvalResult, er
 	}, nil
 }
 
+func (c *ConstantExpr) UnmarshalJSON(data []byte) error {
+	type rawConstantExpr struct {
+		Type      ExprType      `json:"type"`
+		Value     any           `json:"value"`
+		ValueType ExprValueType `json:"valueType"`
+	}
+
+	var raw rawConstantExpr
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&raw); err != nil {
+		return err
+	}
+
+	// 常量值统一以字符串形式存储，但允许反序列化时传入数值或布尔值
+	c.Type = raw.Type
+	c.ValueType = raw.ValueType
+	switch value := raw.Value.(type) {
+	case nil:
+		c.Value = ""
+	case string:
+		c.Value = value
+	case json.Number:
+		c.Value = value.String()
+	case bool:
+		c.Value = strconv.FormatBool(value)
+	default:
+		return fmt.Errorf("unsupported constant value: %v", raw.Value)
+	}
+
+	return nil
+}
+
 type VariantExpr struct {
 	Type     ExprType          `json:"type"`
 	Selector ExprValueSelector `json:"selector"`
@@ -414,23 +627,34 @@ type VariantExpr struct {
 func (v VariantExpr) GetType() ExprType { return v.Type }
 
 func (v VariantExpr) Eval(variables map[string]map[string]any) (*EvalResult, error) {
-	if v.Selector.Id == "" {
-		return nil, fmt.Errorf("node id is empty")
-	}
 	if v.Selector.Name == "" {
 		return nil, fmt.Errorf("name is empty")
 	}
 
-	if _, ok := variables[v.Selector.Id]; !ok {
+	scopedVariables, ok := variables[v.Selector.Id]
+	if !ok {
+		if v.Selector.Id == "" {
+			return nil, fmt.Errorf("variable %s not found", v.Selector.Name)
+		}
 		return nil, fmt.Errorf("node %s not found", v.Selector.Id)
 	}
 
-	if _, ok := variables[v.Selector.Id][v.Selector.Name]; !ok {
+	value, ok := scopedVariables[v.Selector.Name]
+	if !ok {
+		if v.Selector.Id == "" {
+			return nil, fmt.Errorf("variable %s not found", v.Selector.Name)
+		}
 		return nil, fmt.Errorf("variable %s not found in node %s", v.Selector.Name, v.Selector.Id)
 	}
+
+	valueType := v.Selector.Type
+	if valueType == "" {
+		valueType = inferValueType(value)
+	}
+
 	return &EvalResult{
-		Type:  v.Selector.Type,
-		Value: variables[v.Selector.Id][v.Selector.Name],
+		Type:  valueType,
+		Value: value,
 	}, nil
 }
 
@@ -462,10 +686,12 @@ func (c ComparisonExpr) Eval(variables map[string]map[string]any) (*EvalResult,
 		return left.GreaterOrEqual(right)
 	case LessOrEqual:
 		return left.LessOrEqual(right)
-	case Equal:
+	case Equal, Is:
 		return left.Equal(right)
 	case NotEqual:
 		return left.NotEqual(right)
+	case In:
+		return left.In(right)
 	default:
 		return nil, fmt.Errorf("unknown expression operator: %s", c.Operator)
 	}
@@ -485,6 +711,16 @@ func (l LogicalExpr) Eval(variables map[string]map[string]any) (*EvalResult, err
 	if err != nil {
 		return nil, err
 	}
+
+	// 短路求值，避免右侧引用了未执行节点的变量时报错
+	if left.Type == Boolean {
+		if leftValue, err := left.GetBool(); err == nil {
+			if (l.Operator == And && !leftValue) || (l.Operator == Or && leftValue) {
+				return &EvalResult{Type: Boolean, Value: leftValue}, nil
+			}
+		}
+	}
+
 	right, err := l.Right.Eval(variables)
 	if err != nil {
 		return nil, err
@@ -515,6 +751,82 @@ func (n NotExpr) Eval(variables map[string]map[string]any) (*EvalResult, error)
 	return inner.Not()
 }
 
+type ArithmeticExpr struct {
+	Type     ExprType               `json:"type"` // arithmetic
+	Operator ExprArithmeticOperator `json:"operator"`
+	Left     Expr                   `json:"left"`
+	Right    Expr                   `json:"right"`
+}
+
+func (a ArithmeticExpr) GetType() ExprType { return a.Type }
+
+func (a ArithmeticExpr) Eval(variables map[string]map[string]any) (*EvalResult, error) {
+	left, err := a.Left.Eval(variables)
+	if err != nil {
+		return nil, err
+	}
+	right, err := a.Right.Eval(variables)
+	if err != nil {
+		return nil, err
+	}
+
+	return left.Arithmetic(a.Operator, right)
+}
+
+type ListExpr struct {
+	Type  ExprType `json:"type"` // list
+	Items []Expr   `json:"items"`
+}
+
+func (l ListExpr) GetType() ExprType { return l.Type }
+
+func (l ListExpr) Eval(variables map[string]map[string]any) (*EvalResult, error) {
+	items := make([]*EvalResult, 0, len(l.Items))
+	for _, item := range l.Items {
+		res, err := item.Eval(variables)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, res)
+	}
+
+	return &EvalResult{
+		Type:  List,
+		Value: items,
+	}, nil
+}
+
+type CallExpr struct {
+	Type ExprType `json:"type"` // call
+	Name string   `json:"name"`
+	Args []Expr   `json:"args"`
+}
+
+func (c CallExpr) GetType() ExprType { return c.Type }
+
+func (c CallExpr) Eval(variables map[string]map[string]any) (*EvalResult, error) {
+	fn, err := lookupFunction(c.Name, len(c.Args))
+	if err != nil {
+		return nil, err
+	}
+
+	args := make([]*EvalResult, 0, len(c.Args))
+	for _, arg := range c.Args {
+		res, err := arg.Eval(variables)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, res)
+	}
+
+	res, err := fn.call(args)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call function '%s': %w", c.Name, err)
+	}
+
+	return res, nil
+}
+
 type rawExpr struct {
 	Type ExprType `json:"type"`
 }
@@ -560,6 +872,24 @@ func UnmarshalExpr(data []byte) (Expr, error) {
 			return nil, err
 		}
 		return e.ToNotExpr()
+	case ArithmeticExprType:
+		var e ArithmeticExprRaw
+		if err := json.Unmarshal(data, &e); err != nil {
+			return nil, err
+		}
+		return e.ToArithmeticExpr()
+	case ListExprType:
+		var e ListExprRaw
+		if err := json.Unmarshal(data, &e); err != nil {
+			return nil, err
+		}
+		return e.ToListExpr()
+	case CallExprType:
+		var e CallExprRaw
+		if err := json.Unmarshal(data, &e); err != nil {
+			return nil, err
+		}
+		return e.ToCallExpr()
 	default:
 		return nil, fmt.Errorf("unknown expression type: %s", typ.Type)
 	}
@@ -628,3 +958,113 @@ func (r NotExprRaw) ToNotExpr() (NotExpr, error) {
 		Expr: inner,
 	}, nil
 }
+
+type ArithmeticExprRaw struct {
+	Type     ExprType               `json:"type"`
+	Operator ExprArithmeticOperator `json:"operator"`
+	Left     json.RawMessage        `json:"left"`
+	Right    json.RawMessage        `json:"right"`
+}
+
+func (r ArithmeticExprRaw) ToArithmeticExpr() (ArithmeticExpr, error) {
+	left, err := UnmarshalExpr(r.Left)
+	if err != nil {
+		return ArithmeticExpr{}, err
+	}
+	right, err := UnmarshalExpr(r.Right)
+	if err != nil {
+		return ArithmeticExpr{}, err
+	}
+	return ArithmeticExpr{
+		Type:     r.Type,
+		Operator: r.Operator,
+		Left:     left,
+		Right:    right,
+	}, nil
+}
+
+type ListExprRaw struct {
+	Type  ExprType          `json:"type"`
+	Items []json.RawMessage `json:"items"`
+}
+
+func (r ListExprRaw) ToListExpr() (ListExpr, error) {
+	items := make([]Expr, 0, len(r.Items))
+	for _, itemRaw := range r.Items {
+		item, err := UnmarshalExpr(itemRaw)
+		if err != nil {
+			return ListExpr{}, err
+		}
+		items = append(items, item)
+	}
+	return ListExpr{
+		Type:  r.Type,
+		Items: items,
+	}, nil
+}
+
+type CallExprRaw struct {
+	Type ExprType          `json:"type"`
+	Name string            `json:"name"`
+	Args []json.RawMessage `json:"args"`
+}
+
+func (r CallExprRaw) ToCallExpr() (CallExpr, error) {
+	if _, err := lookupFunction(r.Name, len(r.Args)); err != nil {
+		return CallExpr{}, err
+	}
+
+	args := make([]Expr, 0, len(r.Args))
+	for _, argRaw := range r.Args {
+		arg, err := UnmarshalExpr(argRaw)
+		if err != nil {
+			return CallExpr{}, err
+		}
+		args = append(args, arg)
+	}
+	return CallExpr{
+		Type: r.Type,
+		Name: r.Name,
+		Args: args,
+	}, nil
+}
+
+func inferValueType(value any) ExprValueType {
+	switch value.(type) {
+	case bool:
+		return Boolean
+	case string:
+		return String
+	case time.Time, *time.Time:
+		return DateTime
+	case []*EvalResult:
+		return List
+	}
+
+	switch reflect.ValueOf(value).Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return Number
+	case reflect.Slice, reflect.Array:
+		return List
+	}
+
+	return String
+}
+
+func parseTime(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" || s == "-" {
+		return time.Time{}, nil
+	}
+
+	layouts := []string{time.RFC3339Nano, time.DateTime, time.DateOnly}
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("failed to parse datetime: %s", s)
+}
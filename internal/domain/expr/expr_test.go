@@ -2,6 +2,7 @@ package expr
 
 import (
 	"testing"
+	"time"
 )
 
 func TestLogicalEval(t *testing.T) {
@@ -125,3 +126,77 @@ func TestExpr_Eval(t *testing.T) {
 		})
 	}
 }
+
+func TestParse(t *testing.T) {
+	variables := map[string]map[string]any{
+		"": {
+			"certificate.commonName":      "api.example.com",
+			"certificate.subjectAltNames": "api.example.com;www.example.com",
+			"certificate.notAfter":        time.Now().Add(5 * 24 * time.Hour),
+			"certificate.daysLeft":        int32(5),
+			"certificate.validity":        true,
+			"node.skipped":                false,
+			"run.trigger":                 "webhook",
+		},
+		"ODnYSOXB6HQP2_vz6JcZE": {
+			"node.skipped": true,
+		},
+	}
+
+	tests := []struct {
+		name    string
+		input   string
+		want    bool
+		wantErr bool
+	}{
+		{name: "comparison", input: `certificate.daysLeft < 10 && node.skipped == false`, want: true},
+		{name: "logical keywords", input: `certificate.daysLeft >= 10 or not certificate.validity`, want: false},
+		{name: "node variable", input: `$nodes.ODnYSOXB6HQP2_vz6JcZE.node.skipped`, want: true},
+		{name: "string functions", input: `contains(certificate.subjectAltNames, "www.example.com") && startsWith(certificate.commonName, 'api.')`, want: true},
+		{name: "regex", input: `matches(certificate.commonName, "^api\\.example\\.(com|net)$")`, want: true},
+		{name: "in list", input: `run.trigger in ["manual", "webhook"]`, want: true},
+		{name: "in split", input: `"www.example.com" in split(certificate.subjectAltNames, ";")`, want: true},
+		{name: "arithmetic", input: `(certificate.daysLeft + 1) * 2 % 5 == 2`, want: true},
+		{name: "negative", input: `-certificate.daysLeft < -1`, want: true},
+		{name: "datetime", input: `certificate.notAfter - now() < days(7) && certificate.notAfter > "2000-01-01T00:00:00Z"`, want: true},
+		{name: "short circuit", input: `node.skipped && $nodes.notfound.node.skipped`, want: false},
+		{name: "unknown function", input: `foo(certificate.commonName)`, wantErr: true},
+		{name: "wrong arity", input: `contains(certificate.commonName)`, wantErr: true},
+		{name: "unterminated string", input: `certificate.commonName == "api`, wantErr: true},
+		{name: "invalid node variable", input: `$node.x.y == 1`, wantErr: true},
+		{name: "trailing token", input: `certificate.daysLeft < 10 10`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := Parse(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+
+			data, err := MarshalExpr(e)
+			if err != nil {
+				t.Errorf("MarshalExpr() error = %v", err)
+				return
+			}
+
+			e, err = UnmarshalExpr(data)
+			if err != nil {
+				t.Errorf("UnmarshalExpr() error = %v", err)
+				return
+			}
+
+			got, err := e.Eval(variables)
+			if err != nil {
+				t.Errorf("Expr.Eval() error = %v", err)
+				return
+			}
+			if got.Value != tt.want {
+				t.Errorf("Expr.Eval() got = %v, want %v", got.Value, tt.want)
+			}
+		})
+	}
+}
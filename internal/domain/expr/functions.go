@@ -0,0 +1,186 @@
+package expr
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+type function struct {
+	minArgs int
+	maxArgs int
+	call    func(args []*EvalResult) (*EvalResult, error)
+}
+
+// 内置函数。
+// 其中 days/hours/minutes 返回对应时长的秒数，可与日期时间进行加减运算。
+var functions = map[string]function{
+	"contains": {minArgs: 2, maxArgs: 2, call: fnContains},
+	"startsWith": {minArgs: 2, maxArgs: 2, call: func(args []*EvalResult) (*EvalResult, error) {
+		return callStringPredicate(args, strings.HasPrefix)
+	}},
+	"endsWith": {minArgs: 2, maxArgs: 2, call: func(args []*EvalResult) (*EvalResult, error) {
+		return callStringPredicate(args, strings.HasSuffix)
+	}},
+	"matches": {minArgs: 2, maxArgs: 2, call: fnMatches},
+	"lower": {minArgs: 1, maxArgs: 1, call: func(args []*EvalResult) (*EvalResult, error) {
+		return callStringTransform(args, strings.ToLower)
+	}},
+	"upper": {minArgs: 1, maxArgs: 1, call: func(args []*EvalResult) (*EvalResult, error) {
+		return callStringTransform(args, strings.ToUpper)
+	}},
+	"trim": {minArgs: 1, maxArgs: 1, call: func(args []*EvalResult) (*EvalResult, error) {
+		return callStringTransform(args, strings.TrimSpace)
+	}},
+	"split": {minArgs: 2, maxArgs: 2, call: fnSplit},
+	"len":   {minArgs: 1, maxArgs: 1, call: fnLen},
+	"now": {minArgs: 0, maxArgs: 0, call: func(args []*EvalResult) (*EvalResult, error) {
+		return &EvalResult{Type: DateTime, Value: time.Now()}, nil
+	}},
+	"date": {minArgs: 1, maxArgs: 1, call: fnDate},
+	"days": {minArgs: 1, maxArgs: 1, call: func(args []*EvalResult) (*EvalResult, error) {
+		return callDuration(args, 24*time.Hour)
+	}},
+	"hours": {minArgs: 1, maxArgs: 1, call: func(args []*EvalResult) (*EvalResult, error) {
+		return callDuration(args, time.Hour)
+	}},
+	"minutes": {minArgs: 1, maxArgs: 1, call: func(args []*EvalResult) (*EvalResult, error) {
+		return callDuration(args, time.Minute)
+	}},
+}
+
+func lookupFunction(name string, argc int) (function, error) {
+	fn, ok := functions[name]
+	if !ok {
+		return function{}, fmt.Errorf("unknown function: %s", name)
+	}
+
+	if argc < fn.minArgs || argc > fn.maxArgs {
+		if fn.minArgs == fn.maxArgs {
+			return function{}, fmt.Errorf("function '%s' expects %d argument(s), got %d", name, fn.minArgs, argc)
+		}
+		return function{}, fmt.Errorf("function '%s' expects %d to %d arguments, got %d", name, fn.minArgs, fn.maxArgs, argc)
+	}
+
+	return fn, nil
+}
+
+func fnContains(args []*EvalResult) (*EvalResult, error) {
+	if args[0].Type == List {
+		return args[1].In(args[0])
+	}
+
+	return callStringPredicate(args, strings.Contains)
+}
+
+func fnMatches(args []*EvalResult) (*EvalResult, error) {
+	s, err := args[0].GetString()
+	if err != nil {
+		return nil, err
+	}
+
+	pattern, err := args[1].GetString()
+	if err != nil {
+		return nil, err
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid regular expression '%s': %w", pattern, err)
+	}
+
+	return &EvalResult{Type: Boolean, Value: re.MatchString(s)}, nil
+}
+
+func fnSplit(args []*EvalResult) (*EvalResult, error) {
+	s, err := args[0].GetString()
+	if err != nil {
+		return nil, err
+	}
+
+	sep, err := args[1].GetString()
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]*EvalResult, 0)
+	if s != "" {
+		for _, part := range strings.Split(s, sep) {
+			items = append(items, &EvalResult{Type: String, Value: part})
+		}
+	}
+
+	return &EvalResult{Type: List, Value: items}, nil
+}
+
+func fnLen(args []*EvalResult) (*EvalResult, error) {
+	switch args[0].Type {
+	case String:
+		s, err := args[0].GetString()
+		if err != nil {
+			return nil, err
+		}
+		return &EvalResult{Type: Number, Value: len([]rune(s))}, nil
+
+	case List:
+		items, err := args[0].GetList()
+		if err != nil {
+			return nil, err
+		}
+		return &EvalResult{Type: Number, Value: len(items)}, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported value type: %s", args[0].Type)
+	}
+}
+
+func fnDate(args []*EvalResult) (*EvalResult, error) {
+	if args[0].Type == DateTime {
+		return args[0], nil
+	}
+
+	s, err := args[0].GetString()
+	if err != nil {
+		return nil, err
+	}
+
+	t, err := parseTime(s)
+	if err != nil {
+		return nil, err
+	}
+
+	return &EvalResult{Type: DateTime, Value: t}, nil
+}
+
+func callStringPredicate(args []*EvalResult, predicate func(s, substr string) bool) (*EvalResult, error) {
+	s, err := args[0].GetString()
+	if err != nil {
+		return nil, err
+	}
+
+	substr, err := args[1].GetString()
+	if err != nil {
+		return nil, err
+	}
+
+	return &EvalResult{Type: Boolean, Value: predicate(s, substr)}, nil
+}
+
+func callStringTransform(args []*EvalResult, transform func(s string) string) (*EvalResult, error) {
+	s, err := args[0].GetString()
+	if err != nil {
+		return nil, err
+	}
+
+	return &EvalResult{Type: String, Value: transform(s)}, nil
+}
+
+func callDuration(args []*EvalResult, unit time.Duration) (*EvalResult, error) {
+	n, err := args[0].GetFloat64()
+	if err != nil {
+		return nil, err
+	}
+
+	return &EvalResult{Type: Number, Value: n * unit.Seconds()}, nil
+}
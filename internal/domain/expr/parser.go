@@ -0,0 +1,526 @@
+package expr
+
+import (
+	"fmt"
+	"strings"
+)
+
+const nodeVariablePrefix = "$nodes."
+
+// 解析文本形式的表达式。
+//
+// 语法说明：
+//   - 变量：直接书写变量名表示全局变量，如 `certificate.daysLeft`；
+//     以 `$nodes.<nodeId>.` 开头表示指定节点的变量，如 `$nodes.abc123.node.skipped`。
+//   - 字面量：数值（`10`、`0.5`）、字符串（`"foo"` 或 `'foo'`）、布尔值（`true`、`false`）、列表（`["a", "b"]`）。
+//   - 运算符（按优先级从低到高）：`||`（或 `or`）、`&&`（或 `and`）、`==` `!=` `<` `<=` `>` `>=` `in`、`+` `-`、`*` `/` `%`、`!`（或 `not`）。
+//   - 函数：contains、startsWith、endsWith、matches、lower、upper、trim、split、len、now、date、days、hours、minutes。
+//
+// 示例：
+//   - certificate.daysLeft < 10 && $nodes.abc123.node.skipped == false
+//   - contains(certificate.subjectAltNames, "example.com") || matches(certificate.commonName, "^api\\.")
+//   - certificate.notAfter - now() < days(7)
+//   - run.trigger in ["manual", "webhook"]
+//
+// 入参：
+//   - input: 表达式文本。
+//
+// 出参：
+//   - 表达式。
+//   - 错误。
+func Parse(input string) (Expr, error) {
+	tokens, err := tokenize(input)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return nil, fmt.Errorf("unexpected token '%s' at position %d", tok.text, tok.pos)
+	}
+
+	return expr, nil
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenNumber
+	tokenString
+	tokenIdent
+	tokenNodeVariable
+	tokenOperator
+)
+
+type token struct {
+	kind  tokenKind
+	text  string
+	scope string // 仅节点变量有值
+	pos   int
+}
+
+var operators = []string{"&&", "||", "==", "!=", "<=", ">=", "<", ">", "!", "+", "-", "*", "/", "%", "(", ")", "[", "]", ","}
+
+func tokenize(input string) ([]token, error) {
+	tokens := make([]token, 0)
+
+	for i := 0; i < len(input); {
+		ch := input[i]
+
+		switch {
+		case ch == ' ' || ch == '\t' || ch == '\r' || ch == '\n':
+			i++
+
+		case isDigit(ch):
+			start := i
+			for i < len(input) && isDigit(input[i]) {
+				i++
+			}
+			if i+1 < len(input) && input[i] == '.' && isDigit(input[i+1]) {
+				i++
+				for i < len(input) && isDigit(input[i]) {
+					i++
+				}
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: input[start:i], pos: start})
+
+		case ch == '"' || ch == '\'':
+			s, n, err := scanString(input, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{kind: tokenString, text: s, pos: i})
+			i += n
+
+		case isIdentStart(ch):
+			n := scanPath(input, i)
+			tokens = append(tokens, token{kind: tokenIdent, text: input[i : i+n], pos: i})
+			i += n
+
+		case ch == '$':
+			if !strings.HasPrefix(input[i:], nodeVariablePrefix) {
+				return nil, fmt.Errorf("unexpected character '$' at position %d, node variables must be in the form of '$nodes.<nodeId>.<name>'", i)
+			}
+
+			j := i + len(nodeVariablePrefix)
+			for j < len(input) && isNodeIdChar(input[j]) {
+				j++
+			}
+			scope := input[i+len(nodeVariablePrefix) : j]
+			if scope == "" || j+1 >= len(input) || input[j] != '.' || !isIdentStart(input[j+1]) {
+				return nil, fmt.Errorf("invalid node variable at position %d, node variables must be in the form of '$nodes.<nodeId>.<name>'", i)
+			}
+
+			n := scanPath(input, j+1)
+			tokens = append(tokens, token{kind: tokenNodeVariable, text: input[j+1 : j+1+n], scope: scope, pos: i})
+			i = j + 1 + n
+
+		default:
+			matched := false
+			for _, op := range operators {
+				if strings.HasPrefix(input[i:], op) {
+					tokens = append(tokens, token{kind: tokenOperator, text: op, pos: i})
+					i += len(op)
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return nil, fmt.Errorf("unexpected character '%c' at position %d", ch, i)
+			}
+		}
+	}
+
+	tokens = append(tokens, token{kind: tokenEOF, text: "EOF", pos: len(input)})
+	return tokens, nil
+}
+
+func scanString(input string, start int) (string, int, error) {
+	quote := input[start]
+
+	var sb strings.Builder
+	for i := start + 1; i < len(input); i++ {
+		ch := input[i]
+		switch ch {
+		case quote:
+			return sb.String(), i - start + 1, nil
+
+		case '\\':
+			if i+1 >= len(input) {
+				break
+			}
+
+			i++
+			switch input[i] {
+			case 'n':
+				sb.WriteByte('\n')
+			case 'r':
+				sb.WriteByte('\r')
+			case 't':
+				sb.WriteByte('\t')
+			default:
+				sb.WriteByte(input[i])
+			}
+
+		default:
+			sb.WriteByte(ch)
+		}
+	}
+
+	return "", 0, fmt.Errorf("unterminated string at position %d", start)
+}
+
+func scanPath(input string, start int) int {
+	i := start
+	for {
+		for i < len(input) && isIdentChar(input[i]) {
+			i++
+		}
+
+		if i+1 < len(input) && input[i] == '.' && isIdentStart(input[i+1]) {
+			i++
+			continue
+		}
+
+		return i - start
+	}
+}
+
+func isDigit(ch byte) bool {
+	return ch >= '0' && ch <= '9'
+}
+
+func isIdentStart(ch byte) bool {
+	return ch == '_' || (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z')
+}
+
+func isIdentChar(ch byte) bool {
+	return isIdentStart(ch) || isDigit(ch)
+}
+
+func isNodeIdChar(ch byte) bool {
+	return isIdentChar(ch) || ch == '-'
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *parser) accept(kind tokenKind, texts ...string) (token, bool) {
+	tok := p.peek()
+	if tok.kind != kind {
+		return tok, false
+	}
+
+	for _, text := range texts {
+		if tok.text == text {
+			p.next()
+			return tok, true
+		}
+	}
+
+	return tok, false
+}
+
+func (p *parser) expect(text string) error {
+	if _, ok := p.accept(tokenOperator, text); !ok {
+		tok := p.peek()
+		return fmt.Errorf("expected '%s' but got '%s' at position %d", text, tok.text, tok.pos)
+	}
+	return nil
+}
+
+func (p *parser) parseOr() (Expr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		_, ok := p.accept(tokenOperator, "||")
+		if !ok {
+			_, ok = p.accept(tokenIdent, "or")
+		}
+		if !ok {
+			return left, nil
+		}
+
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+
+		left = LogicalExpr{Type: LogicalExprType, Operator: Or, Left: left, Right: right}
+	}
+}
+
+func (p *parser) parseAnd() (Expr, error) {
+	left, err := p.parseComparison()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		_, ok := p.accept(tokenOperator, "&&")
+		if !ok {
+			_, ok = p.accept(tokenIdent, "and")
+		}
+		if !ok {
+			return left, nil
+		}
+
+		right, err := p.parseComparison()
+		if err != nil {
+			return nil, err
+		}
+
+		left = LogicalExpr{Type: LogicalExprType, Operator: And, Left: left, Right: right}
+	}
+}
+
+func (p *parser) parseComparison() (Expr, error) {
+	left, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+
+	var operator ExprComparisonOperator
+	if tok, ok := p.accept(tokenOperator, "==", "!=", "<", "<=", ">", ">="); ok {
+		switch tok.text {
+		case "==":
+			operator = Equal
+		case "!=":
+			operator = NotEqual
+		case "<":
+			operator = LessThan
+		case "<=":
+			operator = LessOrEqual
+		case ">":
+			operator = GreaterThan
+		case ">=":
+			operator = GreaterOrEqual
+		}
+	} else if _, ok := p.accept(tokenIdent, "in"); ok {
+		operator = In
+	} else {
+		return left, nil
+	}
+
+	right, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+
+	return ComparisonExpr{Type: ComparisonExprType, Operator: operator, Left: left, Right: right}, nil
+}
+
+func (p *parser) parseAdditive() (Expr, error) {
+	left, err := p.parseMultiplicative()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		tok, ok := p.accept(tokenOperator, "+", "-")
+		if !ok {
+			return left, nil
+		}
+
+		right, err := p.parseMultiplicative()
+		if err != nil {
+			return nil, err
+		}
+
+		operator := Add
+		if tok.text == "-" {
+			operator = Subtract
+		}
+		left = ArithmeticExpr{Type: ArithmeticExprType, Operator: operator, Left: left, Right: right}
+	}
+}
+
+func (p *parser) parseMultiplicative() (Expr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		tok, ok := p.accept(tokenOperator, "*", "/", "%")
+		if !ok {
+			return left, nil
+		}
+
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+
+		var operator ExprArithmeticOperator
+		switch tok.text {
+		case "*":
+			operator = Multiply
+		case "/":
+			operator = Divide
+		case "%":
+			operator = Modulo
+		}
+		left = ArithmeticExpr{Type: ArithmeticExprType, Operator: operator, Left: left, Right: right}
+	}
+}
+
+func (p *parser) parseUnary() (Expr, error) {
+	_, ok := p.accept(tokenOperator, "!")
+	if !ok {
+		_, ok = p.accept(tokenIdent, "not")
+	}
+	if ok {
+		inner, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+
+		return NotExpr{Type: NotExprType, Expr: inner}, nil
+	}
+
+	if _, ok := p.accept(tokenOperator, "-"); ok {
+		inner, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+
+		if constant, ok := inner.(ConstantExpr); ok && constant.ValueType == Number {
+			if strings.HasPrefix(constant.Value, "-") {
+				constant.Value = strings.TrimPrefix(constant.Value, "-")
+			} else {
+				constant.Value = "-" + constant.Value
+			}
+			return constant, nil
+		}
+
+		zero := ConstantExpr{Type: ConstantExprType, Value: "0", ValueType: Number}
+		return ArithmeticExpr{Type: ArithmeticExprType, Operator: Subtract, Left: zero, Right: inner}, nil
+	}
+
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (Expr, error) {
+	tok := p.next()
+
+	switch tok.kind {
+	case tokenNumber:
+		return ConstantExpr{Type: ConstantExprType, Value: tok.text, ValueType: Number}, nil
+
+	case tokenString:
+		return ConstantExpr{Type: ConstantExprType, Value: tok.text, ValueType: String}, nil
+
+	case tokenNodeVariable:
+		return VariantExpr{Type: VariantExprType, Selector: ExprValueSelector{Id: tok.scope, Name: tok.text}}, nil
+
+	case tokenIdent:
+		switch tok.text {
+		case "true", "false":
+			return ConstantExpr{Type: ConstantExprType, Value: tok.text, ValueType: Boolean}, nil
+
+		case "and", "or", "not", "in":
+			return nil, fmt.Errorf("unexpected keyword '%s' at position %d", tok.text, tok.pos)
+		}
+
+		if _, ok := p.accept(tokenOperator, "("); ok {
+			return p.parseCall(tok)
+		}
+
+		return VariantExpr{Type: VariantExprType, Selector: ExprValueSelector{Name: tok.text}}, nil
+
+	case tokenOperator:
+		switch tok.text {
+		case "(":
+			inner, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+
+			if err := p.expect(")"); err != nil {
+				return nil, err
+			}
+
+			return inner, nil
+
+		case "[":
+			items := make([]Expr, 0)
+			if _, ok := p.accept(tokenOperator, "]"); ok {
+				return ListExpr{Type: ListExprType, Items: items}, nil
+			}
+
+			for {
+				item, err := p.parseOr()
+				if err != nil {
+					return nil, err
+				}
+				items = append(items, item)
+
+				if _, ok := p.accept(tokenOperator, ","); !ok {
+					break
+				}
+			}
+
+			if err := p.expect("]"); err != nil {
+				return nil, err
+			}
+
+			return ListExpr{Type: ListExprType, Items: items}, nil
+		}
+	}
+
+	if tok.kind == tokenEOF {
+		return nil, fmt.Errorf("unexpected end of expression")
+	}
+	return nil, fmt.Errorf("unexpected token '%s' at position %d", tok.text, tok.pos)
+}
+
+func (p *parser) parseCall(name token) (Expr, error) {
+	args := make([]Expr, 0)
+	if _, ok := p.accept(tokenOperator, ")"); !ok {
+		for {
+			arg, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+
+			if _, ok := p.accept(tokenOperator, ","); !ok {
+				break
+			}
+		}
+
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err := lookupFunction(name.text, len(args)); err != nil {
+		return nil, fmt.Errorf("%w at position %d", err, name.pos)
+	}
+
+	return CallExpr{Type: CallExprType, Name: name.text, Args: args}, nil
+}
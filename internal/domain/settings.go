@@ -1,6 +1,8 @@
 package domain
 
 import (
+	"slices"
+
 	xmaps "github.com/certimate-go/certimate/pkg/utils/maps"
 )
 
@@ -18,6 +20,8 @@ const (
 	SettingsNameScriptTemplate       = "scriptTemplate"
 	SettingsNameSSLProvider          = "sslProvider"
 	SettingsNamePersistence          = "persistence"
+	SettingsNameDiscovery            = "discovery"
+	SettingsNameExpiryAlert          = "expiryAlert"
 )
 
 type SettingsContent map[string]any
@@ -34,6 +38,30 @@ type SettingsContentForPersistence struct {
 	WorkflowRunsRetentionMaxDays        int `json:"workflowRunsRetentionMaxDays"`
 }
 
+type SettingsContentForDiscovery struct {
+	Enabled     bool     `json:"enabled"`
+	Cron        string   `json:"cron"`
+	Targets     []string `json:"targets"`     // 主机名、IP 地址或 CIDR 网段
+	Ports       []string `json:"ports"`       // 端口，可通过 "端口/协议" 的形式指定协议，如 "25/smtp"
+	ServerNames []string `json:"serverNames"` // 额外尝试的 SNI 主机名
+	Concurrency int      `json:"concurrency"`
+	Timeout     int      `json:"timeout"`
+}
+
+type SettingsContentForExpiryAlert struct {
+	Enabled    bool                                   `json:"enabled"`
+	Thresholds []int                                  `json:"thresholds"` // 提醒阈值（单位：天）。零值时沿用持久化设置中的证书即将过期天数
+	Channels   []SettingsContentForExpiryAlertChannel `json:"channels"`   // 通知渠道
+	Subject    string                                 `json:"subject"`    // 通知主题模板
+	Message    string                                 `json:"message"`    // 通知内容模板
+}
+
+type SettingsContentForExpiryAlertChannel struct {
+	Provider         string         `json:"provider"`                 // 通知提供商
+	ProviderAccessId string         `json:"providerAccessId"`         // 通知提供商授权记录 ID
+	ProviderConfig   map[string]any `json:"providerConfig,omitempty"` // 通知提供商额外配置
+}
+
 func (c SettingsContent) AsSSLProvider() *SettingsContentForSSLProvider {
 	content := &SettingsContentForSSLProvider{}
 	xmaps.Populate(c, content)
@@ -67,3 +95,50 @@ func (c SettingsContent) AsPersistence() *SettingsContentForPersistence {
 
 	return content
 }
+
+func (c SettingsContent) AsDiscovery() *SettingsContentForDiscovery {
+	content := &SettingsContentForDiscovery{}
+	xmaps.Populate(c, content)
+
+	if content.Cron == "" {
+		content.Cron = "0 */6 * * *"
+	}
+
+	if len(content.Ports) == 0 {
+		content.Ports = []string{"443"}
+	}
+
+	if content.Concurrency <= 0 {
+		content.Concurrency = 16
+	}
+
+	if content.Timeout <= 0 {
+		content.Timeout = 10
+	}
+
+	return content
+}
+
+func (c SettingsContent) AsExpiryAlert() *SettingsContentForExpiryAlert {
+	content := &SettingsContentForExpiryAlert{}
+	xmaps.Populate(c, content)
+
+	thresholds := make([]int, 0, len(content.Thresholds))
+	for _, threshold := range content.Thresholds {
+		if threshold > 0 && !slices.Contains(thresholds, threshold) {
+			thresholds = append(thresholds, threshold)
+		}
+	}
+	slices.Sort(thresholds)
+	content.Thresholds = thresholds
+
+	if content.Subject == "" {
+		content.Subject = "[Certimate] Certificate Expiry Alert"
+	}
+
+	if content.Message == "" {
+		content.Message = "The certificate ({{ $certificate.subjectAltNames }}) will expire in {{ $certificate.daysLeft }} day(s), at {{ datetime $certificate.notAfter }}."
+	}
+
+	return content
+}
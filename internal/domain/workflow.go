@@ -3,6 +3,8 @@ package domain
 import (
 	"encoding/json"
 	"fmt"
+	"regexp"
+	"strings"
 	"time"
 
 	"github.com/certimate-go/certimate/internal/domain/expr"
@@ -13,18 +15,22 @@ const CollectionNameWorkflow = "workflow"
 
 type Workflow struct {
 	Meta
-	Name          string                `db:"name"          json:"name"`
-	Description   string                `db:"description"   json:"description"`
-	Trigger       WorkflowTriggerType   `db:"trigger"       json:"trigger"`
-	TriggerCron   string                `db:"triggerCron"   json:"triggerCron"`
-	Enabled       bool                  `db:"enabled"       json:"enabled"`
-	GraphDraft    *WorkflowGraph        `db:"graphDraft"    json:"graphDraft"`
-	GraphContent  *WorkflowGraph        `db:"graphContent"  json:"graphContent"`
-	HasDraft      bool                  `db:"hasDraft"      json:"hasDraft"`
-	HasContent    bool                  `db:"hasContent"    json:"hasContent"`
-	LastRunId     string                `db:"lastRunRef"    json:"lastRunId"`
-	LastRunStatus WorkflowRunStatusType `db:"lastRunStatus" json:"lastRunStatus"`
-	LastRunTime   time.Time             `db:"lastRunTime"   json:"lastRunTime"`
+	Name                 string                        `db:"name"                 json:"name"`
+	Description          string                        `db:"description"          json:"description"`
+	Trigger              WorkflowTriggerType           `db:"trigger"              json:"trigger"`
+	TriggerCron          string                        `db:"triggerCron"          json:"triggerCron"`
+	TriggerWebhookSecret string                        `db:"triggerWebhookSecret" json:"triggerWebhookSecret"`
+	Enabled              bool                          `db:"enabled"              json:"enabled"`
+	GraphDraft           *WorkflowGraph                `db:"graphDraft"           json:"graphDraft"`
+	GraphContent         *WorkflowGraph                `db:"graphContent"         json:"graphContent"`
+	HasDraft             bool                          `db:"hasDraft"             json:"hasDraft"`
+	HasContent           bool                          `db:"hasContent"           json:"hasContent"`
+	LastRunId            string                        `db:"lastRunRef"           json:"lastRunId"`
+	LastRunStatus        WorkflowRunStatusType         `db:"lastRunStatus"        json:"lastRunStatus"`
+	LastRunTime          time.Time                     `db:"lastRunTime"          json:"lastRunTime"`
+	ManagedBy            string                        `db:"managedBy"            json:"managedBy,omitempty"`
+	ConcurrencyPolicy    WorkflowConcurrencyPolicyType `db:"concurrencyPolicy"    json:"concurrencyPolicy"`
+	Timeout              int                           `db:"timeout"              json:"timeout"` // 运行超时时间（单位：秒，零值时不限制）
 }
 
 type WorkflowGraph struct {
@@ -60,7 +66,49 @@ func (g *WorkflowGraph) Verify() error {
 		return fmt.Errorf("the last node is not an end node")
 	}
 
-	return nil
+	var verifyNodes func(parent *WorkflowNode, nodes []*WorkflowNode) error
+	verifyNodes = func(parent *WorkflowNode, nodes []*WorkflowNode) error {
+		for _, node := range nodes {
+			switch node.Type {
+			case WorkflowNodeTypeBranchBlock:
+				if _, err := parseWorkflowNodeExpression(node.Data.Config["expression"]); err != nil {
+					return fmt.Errorf("invalid expression of node '%s': %w", node.Id, err)
+				}
+
+			case WorkflowNodeTypeParallel:
+				if len(node.Blocks) == 0 {
+					return fmt.Errorf("the parallel node '%s' has no branches", node.Id)
+				}
+				for _, block := range node.Blocks {
+					if block.Type != WorkflowNodeTypeParallelBlock {
+						return fmt.Errorf("the parallel node '%s' contains a non-parallelBlock node '%s'", node.Id, block.Id)
+					}
+				}
+				if node.Data.Config.AsParallel().Concurrency < 0 {
+					return fmt.Errorf("invalid concurrency of node '%s'", node.Id)
+				}
+
+			case WorkflowNodeTypeParallelBlock:
+				if parent == nil || parent.Type != WorkflowNodeTypeParallel {
+					return fmt.Errorf("the parallelBlock node '%s' is not inside a parallel node", node.Id)
+				}
+			}
+
+			if node.Data.Retry != nil {
+				if err := node.Data.Retry.Verify(); err != nil {
+					return fmt.Errorf("invalid retry policy of node '%s': %w", node.Id, err)
+				}
+			}
+
+			if err := verifyNodes(node, node.Blocks); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	return verifyNodes(nil, g.Nodes)
 }
 
 func (g *WorkflowGraph) Clone() *WorkflowGraph {
@@ -78,6 +126,21 @@ func (t WorkflowTriggerType) String() string {
 const (
 	WorkflowTriggerTypeScheduled = WorkflowTriggerType("scheduled")
 	WorkflowTriggerTypeManual    = WorkflowTriggerType("manual")
+	WorkflowTriggerTypeWebhook   = WorkflowTriggerType("webhook")
+)
+
+type WorkflowConcurrencyPolicyType string
+
+func (t WorkflowConcurrencyPolicyType) String() string {
+	return string(t)
+}
+
+// 工作流被触发时，如果已存在未结束的运行，则按以下策略处理。
+const (
+	WorkflowConcurrencyPolicyTypeAllow          = WorkflowConcurrencyPolicyType("allow")           // 允许，排队依次执行
+	WorkflowConcurrencyPolicyTypeSkip           = WorkflowConcurrencyPolicyType("skip")            // 跳过本次触发
+	WorkflowConcurrencyPolicyTypeQueueOne       = WorkflowConcurrencyPolicyType("queue-one")       // 至多排队一次，多余的触发将被跳过
+	WorkflowConcurrencyPolicyTypeCancelPrevious = WorkflowConcurrencyPolicyType("cancel-previous") // 取消未结束的运行，再执行本次触发
 )
 
 type WorkflowNode struct {
@@ -94,44 +157,167 @@ func (t WorkflowNodeType) String() string {
 }
 
 const (
-	WorkflowNodeTypeStart       = WorkflowNodeType("start")
-	WorkflowNodeTypeEnd         = WorkflowNodeType("end")
-	WorkflowNodeTypeCondition   = WorkflowNodeType("condition")
-	WorkflowNodeTypeBranchBlock = WorkflowNodeType("branchBlock")
-	WorkflowNodeTypeTryCatch    = WorkflowNodeType("tryCatch")
-	WorkflowNodeTypeTryBlock    = WorkflowNodeType("tryBlock")
-	WorkflowNodeTypeCatchBlock  = WorkflowNodeType("catchBlock")
-	WorkflowNodeTypeDelay       = WorkflowNodeType("delay")
-	WorkflowNodeTypeBizApply    = WorkflowNodeType("bizApply")
-	WorkflowNodeTypeBizUpload   = WorkflowNodeType("bizUpload")
-	WorkflowNodeTypeBizMonitor  = WorkflowNodeType("bizMonitor")
-	WorkflowNodeTypeBizDeploy   = WorkflowNodeType("bizDeploy")
-	WorkflowNodeTypeBizNotify   = WorkflowNodeType("bizNotify")
+	WorkflowNodeTypeStart         = WorkflowNodeType("start")
+	WorkflowNodeTypeEnd           = WorkflowNodeType("end")
+	WorkflowNodeTypeCondition     = WorkflowNodeType("condition")
+	WorkflowNodeTypeBranchBlock   = WorkflowNodeType("branchBlock")
+	WorkflowNodeTypeTryCatch      = WorkflowNodeType("tryCatch")
+	WorkflowNodeTypeTryBlock      = WorkflowNodeType("tryBlock")
+	WorkflowNodeTypeCatchBlock    = WorkflowNodeType("catchBlock")
+	WorkflowNodeTypeParallel      = WorkflowNodeType("parallel")
+	WorkflowNodeTypeParallelBlock = WorkflowNodeType("parallelBlock")
+	WorkflowNodeTypeDelay         = WorkflowNodeType("delay")
+	WorkflowNodeTypeApproval      = WorkflowNodeType("approval")
+	WorkflowNodeTypeBizApply      = WorkflowNodeType("bizApply")
+	WorkflowNodeTypeBizUpload     = WorkflowNodeType("bizUpload")
+	WorkflowNodeTypeBizMonitor    = WorkflowNodeType("bizMonitor")
+	WorkflowNodeTypeBizDeploy     = WorkflowNodeType("bizDeploy")
+	WorkflowNodeTypeBizNotify     = WorkflowNodeType("bizNotify")
 )
 
 type WorkflowNodeData struct {
-	Name     string             `json:"name"`
-	Disabled bool               `json:"disabled,omitempty,omitzero"`
-	Config   WorkflowNodeConfig `json:"config,omitempty,omitzero"`
+	Name     string                   `json:"name"`
+	Disabled bool                     `json:"disabled,omitempty,omitzero"`
+	Config   WorkflowNodeConfig       `json:"config,omitempty,omitzero"`
+	Retry    *WorkflowNodeRetryPolicy `json:"retry,omitempty"`   // 重试策略，目前仅部署、通知节点支持
+	Timeout  int                      `json:"timeout,omitempty"` // 执行超时时间（单位：秒，零值时不限制），含重试所耗费的时间
+}
+
+type WorkflowNodeRetryPolicy struct {
+	MaxAttempts     int                          `json:"maxAttempts"`               // 最大尝试次数（含首次执行）
+	Backoff         WorkflowNodeRetryBackoffType `json:"backoff,omitempty"`         // 退避策略（零值时默认值 "fixed"）
+	Interval        int                          `json:"interval,omitempty"`        // 重试间隔（单位：秒）。指数退避时表示首次重试间隔
+	MaxInterval     int                          `json:"maxInterval,omitempty"`     // 最大重试间隔（单位：秒），仅指数退避时有效（零值时不限制）
+	Jitter          bool                         `json:"jitter,omitempty"`          // 是否在重试间隔上添加随机抖动
+	RetryableErrors []string                     `json:"retryableErrors,omitempty"` // 可重试的错误信息匹配规则（正则表达式）。零值时所有错误均可重试
+}
+
+func (p *WorkflowNodeRetryPolicy) Verify() error {
+	if p.MaxAttempts < 0 {
+		return fmt.Errorf("invalid max attempts")
+	}
+	if p.Interval < 0 || p.MaxInterval < 0 {
+		return fmt.Errorf("invalid interval")
+	}
+	if p.Backoff != "" && p.Backoff != WorkflowNodeRetryBackoffTypeFixed && p.Backoff != WorkflowNodeRetryBackoffTypeExponential {
+		return fmt.Errorf("unsupported backoff type '%s'", p.Backoff)
+	}
+	if _, err := p.CompileRetryableErrors(); err != nil {
+		return err
+	}
+
+	return nil
 }
 
+// 编译可重试的错误信息匹配规则，忽略空规则。
+func (p *WorkflowNodeRetryPolicy) CompileRetryableErrors() ([]*regexp.Regexp, error) {
+	patterns := make([]*regexp.Regexp, 0, len(p.RetryableErrors))
+	for _, pattern := range p.RetryableErrors {
+		if pattern == "" {
+			continue
+		}
+
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid retryable error pattern '%s': %w", pattern, err)
+		}
+		patterns = append(patterns, re)
+	}
+
+	return patterns, nil
+}
+
+type WorkflowNodeRetryBackoffType string
+
+func (t WorkflowNodeRetryBackoffType) String() string {
+	return string(t)
+}
+
+const (
+	WorkflowNodeRetryBackoffTypeFixed       = WorkflowNodeRetryBackoffType("fixed")
+	WorkflowNodeRetryBackoffTypeExponential = WorkflowNodeRetryBackoffType("exponential")
+)
+
 type WorkflowNodeConfig map[string]any
 
+// 按路径读取配置值，嵌套的字段以半角句点分隔，如 "providerConfig.pfxPassword"。
+func (c WorkflowNodeConfig) GetPath(path string) (any, bool) {
+	var current any = map[string]any(c)
+	for _, key := range strings.Split(path, ".") {
+		m, ok := current.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+
+		current, ok = m[key]
+		if !ok {
+			return nil, false
+		}
+	}
+
+	return current, true
+}
+
+// 按路径设置配置值，嵌套的字段以半角句点分隔。仅当各级父字段均存在时生效。
+func (c WorkflowNodeConfig) SetPath(path string, value any) bool {
+	keys := strings.Split(path, ".")
+
+	m := map[string]any(c)
+	for _, key := range keys[:len(keys)-1] {
+		next, ok := m[key].(map[string]any)
+		if !ok {
+			return false
+		}
+
+		m = next
+	}
+
+	m[keys[len(keys)-1]] = value
+	return true
+}
+
+// 递归遍历配置中的所有字段，包括嵌套的字段。
+func (c WorkflowNodeConfig) Walk(fn func(path string, value any)) {
+	var walk func(prefix string, m map[string]any)
+	walk = func(prefix string, m map[string]any) {
+		for key, value := range m {
+			path := key
+			if prefix != "" {
+				path = prefix + "." + key
+			}
+
+			if nested, ok := value.(map[string]any); ok {
+				walk(path, nested)
+				continue
+			}
+
+			fn(path, value)
+		}
+	}
+
+	walk("", c)
+}
+
 func (c WorkflowNodeConfig) AsDelay() WorkflowNodeConfigForDelay {
 	return WorkflowNodeConfigForDelay{
 		Wait: xmaps.GetInt(c, "wait"),
 	}
 }
 
-func (c WorkflowNodeConfig) AsBranchBlock() WorkflowNodeConfigForBranchBlock {
-	expression := c["expression"]
-	if expression == nil {
-		return WorkflowNodeConfigForBranchBlock{}
+func (c WorkflowNodeConfig) AsApproval() WorkflowNodeConfigForApproval {
+	return WorkflowNodeConfigForApproval{
+		Provider:         xmaps.GetString(c, "provider"),
+		ProviderAccessId: xmaps.GetString(c, "providerAccessId"),
+		ProviderConfig:   xmaps.GetKVMapAny(c, "providerConfig"),
+		Subject:          xmaps.GetString(c, "subject"),
+		Message:          xmaps.GetString(c, "message"),
+		Timeout:          xmaps.GetOrDefaultInt(c, "timeout", 86400),
 	}
+}
 
-	exprRaw, _ := json.Marshal(expression)
-	expr, err := expr.UnmarshalExpr([]byte(exprRaw))
-	if err != nil {
+func (c WorkflowNodeConfig) AsBranchBlock() WorkflowNodeConfigForBranchBlock {
+	expr, err := parseWorkflowNodeExpression(c["expression"])
+	if err != nil || expr == nil {
 		return WorkflowNodeConfigForBranchBlock{}
 	}
 
@@ -140,6 +326,13 @@ func (c WorkflowNodeConfig) AsBranchBlock() WorkflowNodeConfigForBranchBlock {
 	}
 }
 
+func (c WorkflowNodeConfig) AsParallel() WorkflowNodeConfigForParallel {
+	return WorkflowNodeConfigForParallel{
+		Concurrency: xmaps.GetInt(c, "concurrency"),
+		FailFast:    xmaps.GetBool(c, "failFast"),
+	}
+}
+
 func (c WorkflowNodeConfig) AsBizApply() WorkflowNodeConfigForBizApply {
 	return WorkflowNodeConfigForBizApply{
 		Domains:               xmaps.GetStringsBySplit(c, "domains", ";"),
@@ -195,6 +388,8 @@ func (c WorkflowNodeConfig) AsBizDeploy() WorkflowNodeConfigForBizDeploy {
 		ProviderAccessId:        xmaps.GetString(c, "providerAccessId"),
 		ProviderConfig:          xmaps.GetKVMapAny(c, "providerConfig"),
 		SkipOnLastSucceeded:     xmaps.GetBool(c, "skipOnLastSucceeded"),
+		RollbackOnFailure:       xmaps.GetBool(c, "rollbackOnFailure"),
+		Verification:            parseWorkflowNodeConfigForBizDeployVerification(xmaps.GetKVMapAny(c, "verification")),
 	}
 }
 
@@ -213,15 +408,29 @@ type WorkflowNodeConfigForDelay struct {
 	Wait int `json:"wait"` // 等待时间
 }
 
+type WorkflowNodeConfigForApproval struct {
+	Provider         string         `json:"provider,omitempty"`         // 通知提供商（零值时不发送审批通知）
+	ProviderAccessId string         `json:"providerAccessId,omitempty"` // 通知提供商授权记录 ID
+	ProviderConfig   map[string]any `json:"providerConfig,omitempty"`   // 通知提供商额外配置
+	Subject          string         `json:"subject,omitempty"`          // 通知主题（零值时使用默认模板）
+	Message          string         `json:"message,omitempty"`          // 通知内容（零值时使用默认模板）
+	Timeout          int            `json:"timeout,omitempty"`          // 审批超时时间（单位：秒，零值时默认值 86400）
+}
+
 type WorkflowNodeConfigForBranchBlock struct {
-	Expression expr.Expr `json:"expression"` // 条件表达式
+	Expression expr.Expr `json:"expression"` // 条件表达式，支持 JSON 结构或文本形式
+}
+
+type WorkflowNodeConfigForParallel struct {
+	Concurrency int  `json:"concurrency,omitempty"` // 最大并发分支数（零值时不限制）
+	FailFast    bool `json:"failFast,omitempty"`    // 是否在任一分支失败时取消其余分支
 }
 
 type WorkflowNodeConfigForBizApply struct {
 	Domains               []string       `json:"domains"`                         // 域名列表，以半角分号分隔
 	IPAddrs               []string       `json:"ipaddrs"`                         // IP 地址列表，以半角分号分隔
 	ContactEmail          string         `json:"contactEmail"`                    // 联系邮箱
-	ChallengeType         string         `json:"challengeType"`                   // 质询方式
+	ChallengeType         string         `json:"challengeType"`                   // 质询方式（可取值 "dns-01"、"http-01"、"tls-alpn-01"）
 	Provider              string         `json:"provider"`                        // 质询提供商
 	ProviderAccessId      string         `json:"providerAccessId"`                // 质询提供商授权记录 ID
 	ProviderConfig        map[string]any `json:"providerConfig,omitempty"`        // 质询提供商额外配置
@@ -264,6 +473,22 @@ type WorkflowNodeConfigForBizDeploy struct {
 	ProviderAccessId        string         `json:"providerAccessId,omitempty"` // 主机提供商授权记录 ID
 	ProviderConfig          map[string]any `json:"providerConfig,omitempty"`   // 主机提供商额外配置
 	SkipOnLastSucceeded     bool           `json:"skipOnLastSucceeded"`        // 上次部署成功时是否跳过
+	RollbackOnFailure       bool           `json:"rollbackOnFailure"`          // 工作流执行失败时是否回滚到上次部署的证书
+
+	Verification *WorkflowNodeConfigForBizDeployVerification `json:"verification,omitempty"` // 部署后验证配置
+}
+
+type WorkflowNodeConfigForBizDeployVerification struct {
+	Probes   []WorkflowNodeConfigForBizDeployVerificationProbe `json:"probes"`             // 探测目标
+	Timeout  int32                                             `json:"timeout,omitempty"`  // 超时时间（单位：秒，零值时默认值 300）
+	Interval int32                                             `json:"interval,omitempty"` // 轮询间隔（单位：秒，零值时默认值 10）
+}
+
+type WorkflowNodeConfigForBizDeployVerificationProbe struct {
+	Host        string `json:"host"`                  // 主机地址
+	Port        int32  `json:"port,omitempty"`        // 端口（零值时默认值 443）
+	Domain      string `json:"domain,omitempty"`      // 域名，即 SNI（零值时默认值 [Host]）
+	RequestPath string `json:"requestPath,omitempty"` // 请求路径
 }
 
 type WorkflowNodeConfigForBizNotify struct {
@@ -274,3 +499,62 @@ type WorkflowNodeConfigForBizNotify struct {
 	Message              string         `json:"message"`                  // 通知内容
 	SkipOnAllPrevSkipped bool           `json:"skipOnAllPrevSkipped"`     // 前序节点均已跳过时是否跳过
 }
+
+func parseWorkflowNodeConfigForBizDeployVerification(dict map[string]any) *WorkflowNodeConfigForBizDeployVerification {
+	if len(dict) == 0 {
+		return nil
+	}
+
+	verification := &WorkflowNodeConfigForBizDeployVerification{}
+	if err := xmaps.Populate(dict, verification); err != nil {
+		return nil
+	}
+
+	probes := make([]WorkflowNodeConfigForBizDeployVerificationProbe, 0, len(verification.Probes))
+	for _, probe := range verification.Probes {
+		if probe.Host == "" {
+			continue
+		}
+		if probe.Port == 0 {
+			probe.Port = 443
+		}
+		if probe.Domain == "" {
+			probe.Domain = probe.Host
+		}
+		probes = append(probes, probe)
+	}
+	if len(probes) == 0 {
+		return nil
+	}
+	verification.Probes = probes
+
+	if verification.Timeout <= 0 {
+		verification.Timeout = 300
+	}
+	if verification.Interval <= 0 {
+		verification.Interval = 10
+	}
+
+	return verification
+}
+
+func parseWorkflowNodeExpression(expression any) (expr.Expr, error) {
+	switch expression := expression.(type) {
+	case nil:
+		return nil, nil
+
+	case string:
+		// 文本形式的表达式，如 "certificate.daysLeft < 10 && $nodes.abc123.node.skipped == false"
+		if strings.TrimSpace(expression) == "" {
+			return nil, nil
+		}
+		return expr.Parse(expression)
+
+	default:
+		exprRaw, err := json.Marshal(expression)
+		if err != nil {
+			return nil, err
+		}
+		return expr.UnmarshalExpr(exprRaw)
+	}
+}
@@ -0,0 +1,157 @@
+package domain
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+const (
+	ManifestVersionV1 = "certimate/v1"
+
+	ManifestKindAccess   = "access"
+	ManifestKindWorkflow = "workflow"
+)
+
+// 可移植的工作流清单，用于在不同实例之间导入导出工作流。
+// 清单中不包含授权记录 ID 及敏感信息，均以占位符代替：
+//   - 授权记录按名称引用，形如 "${access:<名称>}"，导入时映射为同名的授权记录；
+//   - 敏感信息（如自定义私钥）形如 "${input:<名称>}"，导入时需另行提供实际值。
+type WorkflowManifest struct {
+	Version           string                        `json:"version"`
+	Kind              string                        `json:"kind"`
+	Name              string                        `json:"name"`
+	Description       string                        `json:"description,omitempty"`
+	Trigger           WorkflowTriggerType           `json:"trigger"`
+	TriggerCron       string                        `json:"triggerCron,omitempty"`
+	Enabled           bool                          `json:"enabled"`
+	ConcurrencyPolicy WorkflowConcurrencyPolicyType `json:"concurrencyPolicy,omitempty"`
+	Timeout           int                           `json:"timeout,omitempty"`
+	Accesses          []*WorkflowManifestAccess     `json:"accesses,omitempty"`
+	Inputs            []string                      `json:"inputs,omitempty"`
+	Graph             *WorkflowGraph                `json:"graph"`
+}
+
+func (m *WorkflowManifest) Verify() error {
+	if m.Version != ManifestVersionV1 {
+		return fmt.Errorf("unsupported manifest version '%s'", m.Version)
+	}
+	if m.Kind != "" && m.Kind != ManifestKindWorkflow {
+		return fmt.Errorf("unexpected manifest kind '%s'", m.Kind)
+	}
+	if m.Name == "" {
+		return fmt.Errorf("missing name")
+	}
+	if m.Graph == nil {
+		return fmt.Errorf("missing graph")
+	}
+
+	switch m.Trigger {
+	case WorkflowTriggerTypeManual, WorkflowTriggerTypeWebhook:
+	case WorkflowTriggerTypeScheduled:
+		if m.TriggerCron == "" {
+			return fmt.Errorf("missing trigger cron")
+		}
+	default:
+		return fmt.Errorf("unsupported trigger '%s'", m.Trigger)
+	}
+
+	switch m.ConcurrencyPolicy {
+	case "", WorkflowConcurrencyPolicyTypeAllow, WorkflowConcurrencyPolicyTypeSkip, WorkflowConcurrencyPolicyTypeQueueOne, WorkflowConcurrencyPolicyTypeCancelPrevious:
+	default:
+		return fmt.Errorf("unsupported concurrency policy '%s'", m.ConcurrencyPolicy)
+	}
+
+	if m.Timeout < 0 {
+		return fmt.Errorf("invalid timeout")
+	}
+
+	return nil
+}
+
+type WorkflowManifestAccess struct {
+	Name     string `json:"name"`     // 授权名称
+	Provider string `json:"provider"` // 授权提供商
+}
+
+// 节点配置中引用授权记录 ID 的字段。
+var WorkflowNodeAccessRefFields = []string{"providerAccessId", "caProviderAccessId"}
+
+// 提供商额外配置中的敏感字段按字段名称识别，匹配时忽略大小写及分隔符。
+// 例如 SSH 部署的 "pfxPassword"、"jksKeypass"，Webhook 通知的 "headers" 中可能携带的令牌等。
+var workflowNodeSensitiveKeyRegexp = regexp.MustCompile(`(?i)(password|passwd|passphrase|secret|token|keypass|storepass|privatekey|apikey|credential|authorization|cookie|headers)`)
+
+// 节点配置中可能包含敏感信息的提供商额外配置字段。
+var workflowNodeProviderConfigFields = []string{"providerConfig", "caProviderConfig"}
+
+// 节点配置中的敏感字段路径（嵌套的字段以半角句点分隔，如 "providerConfig.pfxPassword"）。
+// 除按节点类型区分的字段外，提供商额外配置中的字段按名称识别；已引用外部密钥的字段不视为敏感字段。
+// 返回值为空时表示该节点不包含敏感字段。
+func GetWorkflowNodeSensitiveFields(node *WorkflowNode) []string {
+	var fields []string
+
+	switch node.Type {
+	case WorkflowNodeTypeBizApply:
+		if node.Data.Config.AsBizApply().KeySource == "custom" {
+			fields = append(fields, "keyContent")
+		}
+
+	case WorkflowNodeTypeBizUpload:
+		if node.Data.Config.AsBizUpload().Source == "form" {
+			fields = append(fields, "privateKey")
+		}
+	}
+
+	for _, configField := range workflowNodeProviderConfigFields {
+		if _, ok := node.Data.Config[configField].(map[string]any); !ok {
+			continue
+		}
+
+		WorkflowNodeConfig{configField: node.Data.Config[configField]}.Walk(func(path string, value any) {
+			s, ok := value.(string)
+			if !ok || s == "" || isSecretReference(s) {
+				return
+			}
+
+			key := path[strings.LastIndex(path, ".")+1:]
+			if workflowNodeSensitiveKeyRegexp.MatchString(strings.NewReplacer("_", "", "-", "").Replace(key)) {
+				fields = append(fields, path)
+			}
+		})
+	}
+
+	slices.Sort(fields)
+	return fields
+}
+
+var secretReferenceRegexp = regexp.MustCompile(`^\$\{secret:[a-zA-Z0-9_-]+:[^}]+\}$`)
+
+func isSecretReference(value string) bool {
+	return secretReferenceRegexp.MatchString(value)
+}
+
+var workflowManifestPlaceholderRegexp = regexp.MustCompile(`^\$\{(access|input):(.+)\}$`)
+
+const (
+	WorkflowManifestPlaceholderKindAccess = "access"
+	WorkflowManifestPlaceholderKindInput  = "input"
+)
+
+func BuildWorkflowManifestPlaceholder(kind string, name string) string {
+	return fmt.Sprintf("${%s:%s}", kind, name)
+}
+
+func ParseWorkflowManifestPlaceholder(value any) (kind string, name string, ok bool) {
+	s, isStr := value.(string)
+	if !isStr {
+		return "", "", false
+	}
+
+	matches := workflowManifestPlaceholderRegexp.FindStringSubmatch(s)
+	if matches == nil {
+		return "", "", false
+	}
+
+	return matches[1], matches[2], true
+}
@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"slices"
 	"time"
 )
 
@@ -8,13 +9,17 @@ const CollectionNameWorkflowRun = "workflow_run"
 
 type WorkflowRun struct {
 	Meta
-	WorkflowId string                `db:"workflowRef" json:"workflowId"`
-	Status     WorkflowRunStatusType `db:"status"      json:"status"`
-	Trigger    WorkflowTriggerType   `db:"trigger"     json:"trigger"`
-	StartedAt  time.Time             `db:"startedAt"   json:"startedAt"`
-	EndedAt    time.Time             `db:"endedAt"     json:"endedAt"`
-	Graph      *WorkflowGraph        `db:"graph"       json:"graph"`
-	Error      string                `db:"error"       json:"error"`
+	WorkflowId string                 `db:"workflowRef" json:"workflowId"`
+	Status     WorkflowRunStatusType  `db:"status"      json:"status"`
+	Trigger    WorkflowTriggerType    `db:"trigger"     json:"trigger"`
+	Payload    map[string]any         `db:"payload"     json:"payload"` // 触发时携带的数据，目前仅 Webhook 触发时有值
+	DryRun     bool                   `db:"dryRun"      json:"dryRun"`  // 是否为试运行，试运行时各节点不会产生实际变更
+	StartedAt  time.Time              `db:"startedAt"   json:"startedAt"`
+	EndedAt    time.Time              `db:"endedAt"     json:"endedAt"`
+	Graph      *WorkflowGraph         `db:"graph"       json:"graph"`
+	Error      string                 `db:"error"       json:"error"`
+	Checkpoint *WorkflowRunCheckpoint `db:"checkpoint"  json:"checkpoint,omitempty"` // 执行断点，挂起后恢复执行时使用
+	Reason     string                 `db:"reason"      json:"reason,omitempty"`     // 被跳过或取消的原因
 }
 
 type WorkflowRunStatusType string
@@ -26,7 +31,105 @@ func (t WorkflowRunStatusType) String() string {
 const (
 	WorkflowRunStatusTypePending    WorkflowRunStatusType = "pending"
 	WorkflowRunStatusTypeProcessing WorkflowRunStatusType = "processing"
+	WorkflowRunStatusTypeWaiting    WorkflowRunStatusType = "waiting"
 	WorkflowRunStatusTypeSucceeded  WorkflowRunStatusType = "succeeded"
 	WorkflowRunStatusTypeFailed     WorkflowRunStatusType = "failed"
 	WorkflowRunStatusTypeCanceled   WorkflowRunStatusType = "canceled"
+	WorkflowRunStatusTypeSkipped    WorkflowRunStatusType = "skipped"
+	WorkflowRunStatusTypeTimedOut   WorkflowRunStatusType = "timedout"
 )
+
+type WorkflowRunCheckpoint struct {
+	NodeId           string                           `json:"nodeId"`           // 挂起执行的节点 ID
+	CompletedNodeIds []string                         `json:"completedNodeIds"` // 已执行完成的节点 ID 列表
+	Variables        []*WorkflowRunCheckpointVariable `json:"variables"`
+	Inputs           []*WorkflowRunCheckpointInOut    `json:"inputs"`
+	Rollbacks        []*WorkflowRunCheckpointRollback `json:"rollbacks,omitempty"` // 已登记、尚未执行的回滚动作
+	ElapsedMilli     int64                            `json:"elapsedMilli"`        // 已耗费的执行时间（单位：毫秒），不含挂起等待的时间，用于恢复执行时计算剩余的超时时间
+}
+
+func (c *WorkflowRunCheckpoint) GetVariable(scope string, key string) (*WorkflowRunCheckpointVariable, bool) {
+	for _, variable := range c.Variables {
+		if variable.Scope == scope && variable.Key == key {
+			return variable, true
+		}
+	}
+
+	return nil, false
+}
+
+func (c *WorkflowRunCheckpoint) SetVariable(scope string, key string, value any, valueType string) {
+	if variable, ok := c.GetVariable(scope, key); ok {
+		variable.Value = value
+		variable.ValueType = valueType
+		return
+	}
+
+	c.Variables = append(c.Variables, &WorkflowRunCheckpointVariable{
+		Scope:     scope,
+		Key:       key,
+		Value:     value,
+		ValueType: valueType,
+	})
+}
+
+// 合并另一个执行断点中的进度及状态。
+// 并行分支的执行断点可能乱序保存，合并后已完成的节点及状态不会因较早生成的断点而丢失；同一状态存在冲突时以当前断点为准。
+func (c *WorkflowRunCheckpoint) Merge(other *WorkflowRunCheckpoint) {
+	if other == nil {
+		return
+	}
+
+	if c.NodeId == "" {
+		c.NodeId = other.NodeId
+	}
+
+	for _, nodeId := range other.CompletedNodeIds {
+		if !slices.Contains(c.CompletedNodeIds, nodeId) {
+			c.CompletedNodeIds = append(c.CompletedNodeIds, nodeId)
+		}
+	}
+
+	for _, variable := range other.Variables {
+		if _, ok := c.GetVariable(variable.Scope, variable.Key); !ok {
+			c.Variables = append(c.Variables, variable)
+		}
+	}
+
+	for _, input := range other.Inputs {
+		if !slices.ContainsFunc(c.Inputs, func(item *WorkflowRunCheckpointInOut) bool {
+			return item.NodeId == input.NodeId && item.Name == input.Name
+		}) {
+			c.Inputs = append(c.Inputs, input)
+		}
+	}
+
+	for _, rollback := range other.Rollbacks {
+		if !slices.ContainsFunc(c.Rollbacks, func(item *WorkflowRunCheckpointRollback) bool { return item.NodeId == rollback.NodeId }) {
+			c.Rollbacks = append(c.Rollbacks, rollback)
+		}
+	}
+
+	c.ElapsedMilli = max(c.ElapsedMilli, other.ElapsedMilli)
+}
+
+type WorkflowRunCheckpointVariable struct {
+	Scope     string `json:"scope,omitempty"`
+	Key       string `json:"key"`
+	Value     any    `json:"value"`
+	ValueType string `json:"valueType"`
+}
+
+type WorkflowRunCheckpointInOut struct {
+	NodeId     string `json:"nodeId"`
+	Type       string `json:"type"`
+	Name       string `json:"name"`
+	Value      any    `json:"value"`
+	ValueType  string `json:"valueType"`
+	Persistent bool   `json:"persistent,omitempty"`
+}
+
+type WorkflowRunCheckpointRollback struct {
+	NodeId string         `json:"nodeId"`
+	Data   map[string]any `json:"data"`
+}
@@ -0,0 +1,64 @@
+package domain
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestWorkflowRunCheckpointMerge(t *testing.T) {
+	// 模拟两个并行分支的快照乱序保存：较新的快照先保存，较早的快照后保存
+	newer := &WorkflowRunCheckpoint{
+		CompletedNodeIds: []string{"start", "a1", "b1"},
+		Variables: []*WorkflowRunCheckpointVariable{
+			{Scope: "a1", Key: "node.id", Value: "a1", ValueType: "string"},
+			{Scope: "b1", Key: "node.id", Value: "b1", ValueType: "string"},
+		},
+		Inputs: []*WorkflowRunCheckpointInOut{
+			{NodeId: "b1", Name: "certificate", Value: "cert-b1", ValueType: "string"},
+		},
+		ElapsedMilli: 2000,
+	}
+	older := &WorkflowRunCheckpoint{
+		CompletedNodeIds: []string{"start", "a1", "a2"},
+		Variables: []*WorkflowRunCheckpointVariable{
+			{Scope: "a1", Key: "node.id", Value: "stale", ValueType: "string"},
+			{Scope: "a2", Key: "node.id", Value: "a2", ValueType: "string"},
+		},
+		Inputs: []*WorkflowRunCheckpointInOut{
+			{NodeId: "a2", Name: "certificate", Value: "cert-a2", ValueType: "string"},
+		},
+		ElapsedMilli: 1000,
+	}
+
+	older.Merge(newer)
+
+	for _, nodeId := range []string{"start", "a1", "a2", "b1"} {
+		if !slices.Contains(older.CompletedNodeIds, nodeId) {
+			t.Errorf("expected node '%s' to be completed", nodeId)
+		}
+	}
+	if len(older.CompletedNodeIds) != 4 {
+		t.Errorf("expected 4 completed nodes, got %d", len(older.CompletedNodeIds))
+	}
+
+	if v, ok := older.GetVariable("a1", "node.id"); !ok || v.Value != "stale" {
+		t.Errorf("expected the conflicting variable to keep the current value, got %v", v)
+	}
+	if _, ok := older.GetVariable("b1", "node.id"); !ok {
+		t.Errorf("expected the variable of node 'b1' to be merged")
+	}
+
+	if len(older.Inputs) != 2 {
+		t.Errorf("expected 2 inputs, got %d", len(older.Inputs))
+	}
+
+	if older.ElapsedMilli != 2000 {
+		t.Errorf("expected elapsed 2000, got %d", older.ElapsedMilli)
+	}
+
+	// 与空断点合并时保持不变
+	older.Merge(nil)
+	if len(older.CompletedNodeIds) != 4 {
+		t.Errorf("expected 4 completed nodes after merging nil, got %d", len(older.CompletedNodeIds))
+	}
+}
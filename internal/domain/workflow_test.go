@@ -0,0 +1,188 @@
+package domain
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestWorkflowGraphVerifyParallel(t *testing.T) {
+	newGraph := func(nodes ...*WorkflowNode) *WorkflowGraph {
+		graph := &WorkflowGraph{}
+		graph.Nodes = append(graph.Nodes, &WorkflowNode{Id: "start", Type: WorkflowNodeTypeStart})
+		graph.Nodes = append(graph.Nodes, nodes...)
+		graph.Nodes = append(graph.Nodes, &WorkflowNode{Id: "end", Type: WorkflowNodeTypeEnd})
+		return graph
+	}
+
+	testCases := []struct {
+		name    string
+		graph   *WorkflowGraph
+		wantErr bool
+	}{
+		{
+			name: "valid",
+			graph: newGraph(&WorkflowNode{
+				Id:   "parallel",
+				Type: WorkflowNodeTypeParallel,
+				Data: WorkflowNodeData{Config: WorkflowNodeConfig{"concurrency": 2}},
+				Blocks: []*WorkflowNode{
+					{Id: "block1", Type: WorkflowNodeTypeParallelBlock},
+					{Id: "block2", Type: WorkflowNodeTypeParallelBlock},
+				},
+			}),
+			wantErr: false,
+		},
+		{
+			name:    "no branches",
+			graph:   newGraph(&WorkflowNode{Id: "parallel", Type: WorkflowNodeTypeParallel}),
+			wantErr: true,
+		},
+		{
+			name: "non-parallelBlock branch",
+			graph: newGraph(&WorkflowNode{
+				Id:   "parallel",
+				Type: WorkflowNodeTypeParallel,
+				Blocks: []*WorkflowNode{
+					{Id: "block1", Type: WorkflowNodeTypeBranchBlock},
+				},
+			}),
+			wantErr: true,
+		},
+		{
+			name: "negative concurrency",
+			graph: newGraph(&WorkflowNode{
+				Id:   "parallel",
+				Type: WorkflowNodeTypeParallel,
+				Data: WorkflowNodeData{Config: WorkflowNodeConfig{"concurrency": -1}},
+				Blocks: []*WorkflowNode{
+					{Id: "block1", Type: WorkflowNodeTypeParallelBlock},
+				},
+			}),
+			wantErr: true,
+		},
+		{
+			name:    "parallelBlock outside parallel",
+			graph:   newGraph(&WorkflowNode{Id: "block1", Type: WorkflowNodeTypeParallelBlock}),
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.graph.Verify()
+			if (err != nil) != tc.wantErr {
+				t.Errorf("expected error: %v, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestGetWorkflowNodeSensitiveFields(t *testing.T) {
+	node := &WorkflowNode{
+		Id:   "deploy",
+		Type: WorkflowNodeTypeBizDeploy,
+		Data: WorkflowNodeData{
+			Config: WorkflowNodeConfig{
+				"provider":         "ssh",
+				"providerAccessId": "access1",
+				"providerConfig": map[string]any{
+					"format":         "PFX",
+					"pfxPassword":    "p@ssw0rd",
+					"jksKeypass":     "keypass",
+					"jks_storepass":  "${secret:env:JKS_STOREPASS}",
+					"outputCertPath": "/etc/ssl/cert.pem",
+					"headers": map[string]any{
+						"Authorization": "Bearer token",
+					},
+				},
+			},
+		},
+	}
+
+	got := GetWorkflowNodeSensitiveFields(node)
+	want := []string{"providerConfig.headers.Authorization", "providerConfig.jksKeypass", "providerConfig.pfxPassword"}
+	if !slices.Equal(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+func TestWorkflowNodeConfigPath(t *testing.T) {
+	config := WorkflowNodeConfig{
+		"provider": "ssh",
+		"providerConfig": map[string]any{
+			"pfxPassword": "p@ssw0rd",
+		},
+	}
+
+	if v, ok := config.GetPath("providerConfig.pfxPassword"); !ok || v != "p@ssw0rd" {
+		t.Errorf("expected 'p@ssw0rd', got %v", v)
+	}
+	if _, ok := config.GetPath("providerConfig.missing"); ok {
+		t.Errorf("expected the missing path not to be found")
+	}
+
+	if !config.SetPath("providerConfig.pfxPassword", "changed") {
+		t.Errorf("expected the path to be set")
+	}
+	if v, _ := config.GetPath("providerConfig.pfxPassword"); v != "changed" {
+		t.Errorf("expected 'changed', got %v", v)
+	}
+	if config.SetPath("missing.field", "value") {
+		t.Errorf("expected the path with a missing parent not to be set")
+	}
+
+	paths := make([]string, 0)
+	config.Walk(func(path string, _ any) { paths = append(paths, path) })
+	slices.Sort(paths)
+	if !slices.Equal(paths, []string{"provider", "providerConfig.pfxPassword"}) {
+		t.Errorf("unexpected walked paths %v", paths)
+	}
+}
+
+func TestWorkflowGraphVerifyRetryPolicy(t *testing.T) {
+	newGraph := func(retry *WorkflowNodeRetryPolicy) *WorkflowGraph {
+		return &WorkflowGraph{
+			Nodes: []*WorkflowNode{
+				{Id: "start", Type: WorkflowNodeTypeStart},
+				{Id: "deploy", Type: WorkflowNodeTypeBizDeploy, Data: WorkflowNodeData{Retry: retry}},
+				{Id: "end", Type: WorkflowNodeTypeEnd},
+			},
+		}
+	}
+
+	testCases := []struct {
+		name    string
+		retry   *WorkflowNodeRetryPolicy
+		wantErr bool
+	}{
+		{
+			name:    "valid",
+			retry:   &WorkflowNodeRetryPolicy{MaxAttempts: 3, Backoff: WorkflowNodeRetryBackoffTypeExponential, RetryableErrors: []string{"(?i)timeout", ""}},
+			wantErr: false,
+		},
+		{
+			name:    "invalid retryable error pattern",
+			retry:   &WorkflowNodeRetryPolicy{MaxAttempts: 3, RetryableErrors: []string{"timeout("}},
+			wantErr: true,
+		},
+		{
+			name:    "unsupported backoff",
+			retry:   &WorkflowNodeRetryPolicy{MaxAttempts: 3, Backoff: "linear"},
+			wantErr: true,
+		},
+		{
+			name:    "negative max attempts",
+			retry:   &WorkflowNodeRetryPolicy{MaxAttempts: -1},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := newGraph(tc.retry).Verify()
+			if (err != nil) != tc.wantErr {
+				t.Errorf("expected error: %v, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
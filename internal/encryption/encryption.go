@@ -0,0 +1,134 @@
+package encryption
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/certimate-go/certimate/internal/domain"
+	xenv "github.com/certimate-go/certimate/pkg/utils/env"
+)
+
+const (
+	envMasterKey     = "CERTIMATE_ENCRYPTION_KEY"
+	envMasterKeyFile = "CERTIMATE_ENCRYPTION_KEY_FILE"
+)
+
+var ErrMasterKeyNotConfigured = errors.New("encryption: the master key is not configured, please set the environment variable `" + envMasterKey + "` or `" + envMasterKeyFile + "`")
+
+// 需要加密存储的字段，键为集合名称，值为字段名称列表。
+var EncryptedFields = map[string][]string{
+	domain.CollectionNameAccess:      {"config"},
+	domain.CollectionNameCertificate: {"privateKey"},
+	domain.CollectionNameACMEAccount: {"privateKey"},
+}
+
+var (
+	envelope     *Envelope
+	envelopeErr  error
+	envelopeOnce sync.Once
+)
+
+// 返回由环境变量配置的主密钥所创建的信封加密器。
+// 如果未配置主密钥，则返回 nil。
+func GetEnvelope() (*Envelope, error) {
+	envelopeOnce.Do(func() {
+		masterKey, err := LoadMasterKey(xenv.GetString(envMasterKey), xenv.GetString(envMasterKeyFile))
+		if err != nil {
+			envelopeErr = err
+			return
+		} else if masterKey == nil {
+			return
+		}
+
+		envelope, envelopeErr = NewEnvelope(masterKey)
+	})
+
+	return envelope, envelopeErr
+}
+
+// 加密字符串。
+// 如果未配置主密钥，或值已经是密文，则原样返回。
+func EncryptString(plaintext string) (string, error) {
+	if plaintext == "" || IsEncrypted(plaintext) {
+		return plaintext, nil
+	}
+
+	env, err := GetEnvelope()
+	if err != nil {
+		return "", err
+	} else if env == nil {
+		return plaintext, nil
+	}
+
+	return env.Encrypt(plaintext)
+}
+
+// 解密字符串。
+// 如果值不是密文，则原样返回。
+func DecryptString(value string) (string, error) {
+	if !IsEncrypted(value) {
+		return value, nil
+	}
+
+	env, err := GetEnvelope()
+	if err != nil {
+		return "", err
+	} else if env == nil {
+		return "", ErrMasterKeyNotConfigured
+	}
+
+	return env.Decrypt(value)
+}
+
+// 加载主密钥。
+//
+// 入参：
+//   - key: 主密钥，支持 Base64 或十六进制编码。
+//   - keyFile: 主密钥文件路径，仅当 key 为空时生效。
+//
+// 出参：
+//   - 主密钥。如果均未配置，则返回 nil。
+//   - 错误。
+func LoadMasterKey(key string, keyFile string) ([]byte, error) {
+	if key == "" && keyFile != "" {
+		data, err := os.ReadFile(keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("encryption: failed to read master key file: %w", err)
+		}
+
+		key = string(data)
+	}
+
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return nil, nil
+	}
+
+	if len(key) == 64 {
+		if data, err := hex.DecodeString(key); err == nil {
+			return data, nil
+		}
+	}
+
+	if data, err := base64.StdEncoding.DecodeString(key); err == nil && len(data) == 32 {
+		return data, nil
+	}
+
+	return nil, errors.New("encryption: invalid master key, it must be 32 bytes encoded in base64 or hex")
+}
+
+// 生成随机的主密钥，并以 Base64 编码返回。
+func GenerateMasterKey() (string, error) {
+	masterKey := make([]byte, 32)
+	if _, err := rand.Read(masterKey); err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(masterKey), nil
+}
@@ -0,0 +1,160 @@
+package encryption
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	xcrypto "github.com/certimate-go/certimate/pkg/utils/crypto"
+)
+
+// 密文前缀。
+// 完整格式为 "enc:v1:<主密钥标识>:<经主密钥加密的数据密钥>:<经数据密钥加密的数据>"。
+const envelopePrefix = "enc:v1:"
+
+var ErrKeyMismatch = errors.New("encryption: the value was encrypted with a different master key")
+
+// 信封加密器。
+// 每个值都使用随机生成的数据密钥进行加密，数据密钥再由主密钥加密后与密文一同保存。
+// 轮换主密钥时只需重新加密数据密钥，无需重新加密数据本身。
+type Envelope struct {
+	keyId   string
+	keyWrap xcrypto.AESCryptor
+}
+
+// 创建信封加密器。
+//
+// 入参：
+//   - masterKey: 主密钥，长度必须为 32 字节。
+//
+// 出参：
+//   - 信封加密器。
+//   - 错误。
+func NewEnvelope(masterKey []byte) (*Envelope, error) {
+	if len(masterKey) != 32 {
+		return nil, fmt.Errorf("encryption: invalid master key length %d, expected 32 bytes", len(masterKey))
+	}
+
+	digest := sha256.Sum256(masterKey)
+	return &Envelope{
+		keyId:   hex.EncodeToString(digest[:4]),
+		keyWrap: xcrypto.NewAESCryptor(masterKey),
+	}, nil
+}
+
+// 返回主密钥标识。
+func (e *Envelope) KeyId() string {
+	return e.keyId
+}
+
+func (e *Envelope) Encrypt(plaintext string) (string, error) {
+	dataKey := make([]byte, 32)
+	if _, err := rand.Read(dataKey); err != nil {
+		return "", fmt.Errorf("encryption: failed to generate data key: %w", err)
+	}
+
+	wrappedKey, err := e.keyWrap.GCMEncrypt(dataKey)
+	if err != nil {
+		return "", fmt.Errorf("encryption: failed to encrypt data key: %w", err)
+	}
+
+	ciphertext, err := xcrypto.NewAESCryptor(dataKey).GCMEncrypt([]byte(plaintext))
+	if err != nil {
+		return "", fmt.Errorf("encryption: failed to encrypt data: %w", err)
+	}
+
+	return formatEnvelope(e.keyId, wrappedKey, ciphertext), nil
+}
+
+func (e *Envelope) Decrypt(value string) (string, error) {
+	keyId, wrappedKey, ciphertext, err := parseEnvelope(value)
+	if err != nil {
+		return "", err
+	}
+
+	if keyId != e.keyId {
+		return "", ErrKeyMismatch
+	}
+
+	dataKey, err := e.keyWrap.GCMDecrypt(wrappedKey)
+	if err != nil {
+		return "", fmt.Errorf("encryption: failed to decrypt data key: %w", err)
+	}
+
+	plaintext, err := xcrypto.NewAESCryptor(dataKey).GCMDecrypt(ciphertext)
+	if err != nil {
+		return "", fmt.Errorf("encryption: failed to decrypt data: %w", err)
+	}
+
+	return string(plaintext), nil
+}
+
+// 使用新的主密钥重新加密密文中的数据密钥。
+//
+// 入参：
+//   - value: 由当前主密钥加密的密文。
+//   - target: 新的信封加密器。
+//
+// 出参：
+//   - 由新主密钥加密的密文。
+//   - 错误。
+func (e *Envelope) Rewrap(value string, target *Envelope) (string, error) {
+	keyId, wrappedKey, ciphertext, err := parseEnvelope(value)
+	if err != nil {
+		return "", err
+	}
+
+	if keyId == target.keyId {
+		return value, nil
+	} else if keyId != e.keyId {
+		return "", ErrKeyMismatch
+	}
+
+	dataKey, err := e.keyWrap.GCMDecrypt(wrappedKey)
+	if err != nil {
+		return "", fmt.Errorf("encryption: failed to decrypt data key: %w", err)
+	}
+
+	rewrappedKey, err := target.keyWrap.GCMEncrypt(dataKey)
+	if err != nil {
+		return "", fmt.Errorf("encryption: failed to encrypt data key: %w", err)
+	}
+
+	return formatEnvelope(target.keyId, rewrappedKey, ciphertext), nil
+}
+
+// 判断值是否为信封加密后的密文。
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, envelopePrefix)
+}
+
+func formatEnvelope(keyId string, wrappedKey, ciphertext []byte) string {
+	return envelopePrefix + keyId + ":" + base64.StdEncoding.EncodeToString(wrappedKey) + ":" + base64.StdEncoding.EncodeToString(ciphertext)
+}
+
+func parseEnvelope(value string) (string, []byte, []byte, error) {
+	if !IsEncrypted(value) {
+		return "", nil, nil, errors.New("encryption: the value is not encrypted")
+	}
+
+	parts := strings.Split(strings.TrimPrefix(value, envelopePrefix), ":")
+	if len(parts) != 3 {
+		return "", nil, nil, errors.New("encryption: malformed encrypted value")
+	}
+
+	wrappedKey, err := base64.StdEncoding.DecodeString(parts[1])
+	if err != nil {
+		return "", nil, nil, fmt.Errorf("encryption: malformed encrypted value: %w", err)
+	}
+
+	ciphertext, err := base64.StdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return "", nil, nil, fmt.Errorf("encryption: malformed encrypted value: %w", err)
+	}
+
+	return parts[0], wrappedKey, ciphertext, nil
+}
@@ -0,0 +1,197 @@
+package encryption
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func newTestEnvelope(t *testing.T, seed byte) *Envelope {
+	env, err := NewEnvelope(bytes.Repeat([]byte{seed}, 32))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return env
+}
+
+// 修改密文中指定部分（0: 主密钥标识，1: 数据密钥，2: 数据）的最后一个字节。
+func tamperEnvelope(t *testing.T, value string, index int) string {
+	parts := strings.Split(strings.TrimPrefix(value, envelopePrefix), ":")
+	if index == 0 {
+		parts[0] = parts[0][:len(parts[0])-1] + "x"
+		return envelopePrefix + strings.Join(parts, ":")
+	}
+
+	data, err := base64.StdEncoding.DecodeString(parts[index])
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data[len(data)-1] ^= 0x01
+	parts[index] = base64.StdEncoding.EncodeToString(data)
+	return envelopePrefix + strings.Join(parts, ":")
+}
+
+func TestEnvelope(t *testing.T) {
+	const plaintext = `{"accessKeyId":"foo","accessKeySecret":"bar"}`
+
+	t.Run("Invalid master key", func(t *testing.T) {
+		if _, err := NewEnvelope(make([]byte, 16)); err == nil {
+			t.Error("expected error, got nil")
+		}
+	})
+
+	t.Run("Round trip", func(t *testing.T) {
+		env := newTestEnvelope(t, 1)
+
+		for _, s := range []string{plaintext, "", "证书私钥"} {
+			encrypted, err := env.Encrypt(s)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !IsEncrypted(encrypted) || strings.Contains(encrypted, "accessKeySecret") {
+				t.Errorf("expected an encrypted value, got '%s'", encrypted)
+			}
+			if !strings.HasPrefix(encrypted, envelopePrefix+env.KeyId()+":") {
+				t.Errorf("expected the value to be tagged with key id '%s', got '%s'", env.KeyId(), encrypted)
+			}
+
+			decrypted, err := env.Decrypt(encrypted)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if decrypted != s {
+				t.Errorf("expected '%s', got '%s'", s, decrypted)
+			}
+		}
+
+		// 每次加密均使用新的数据密钥和随机数
+		encrypted1, _ := env.Encrypt(plaintext)
+		encrypted2, _ := env.Encrypt(plaintext)
+		if encrypted1 == encrypted2 {
+			t.Error("expected different ciphertexts for the same plaintext")
+		}
+	})
+
+	t.Run("Wrong key", func(t *testing.T) {
+		env := newTestEnvelope(t, 1)
+		other := newTestEnvelope(t, 2)
+
+		encrypted, err := env.Encrypt(plaintext)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if _, err := other.Decrypt(encrypted); !errors.Is(err, ErrKeyMismatch) {
+			t.Errorf("expected ErrKeyMismatch, got %v", err)
+		}
+
+		// 即使伪造了主密钥标识，也无法解开数据密钥
+		forged := strings.Replace(encrypted, envelopePrefix+env.KeyId()+":", envelopePrefix+other.KeyId()+":", 1)
+		if _, err := other.Decrypt(forged); err == nil || errors.Is(err, ErrKeyMismatch) {
+			t.Errorf("expected a decryption error, got %v", err)
+		}
+	})
+
+	t.Run("Tampered ciphertext", func(t *testing.T) {
+		env := newTestEnvelope(t, 1)
+
+		encrypted, err := env.Encrypt(plaintext)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		testCases := []struct {
+			name  string
+			value string
+		}{
+			{name: "key id", value: tamperEnvelope(t, encrypted, 0)},
+			{name: "data key", value: tamperEnvelope(t, encrypted, 1)},
+			{name: "data", value: tamperEnvelope(t, encrypted, 2)},
+			{name: "truncated", value: encrypted[:len(encrypted)-8]},
+			{name: "malformed", value: envelopePrefix + env.KeyId() + ":foo"},
+			{name: "not base64", value: envelopePrefix + env.KeyId() + ":!!!:!!!"},
+			{name: "plaintext", value: plaintext},
+		}
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				if decrypted, err := env.Decrypt(tc.value); err == nil {
+					t.Errorf("expected error, got '%s'", decrypted)
+				}
+			})
+		}
+	})
+
+	t.Run("Key rotation", func(t *testing.T) {
+		oldEnv := newTestEnvelope(t, 1)
+		newEnv := newTestEnvelope(t, 2)
+
+		encrypted, err := oldEnv.Encrypt(plaintext)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		rewrapped, err := oldEnv.Rewrap(encrypted, newEnv)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		// 仅重新加密数据密钥，数据本身保持不变
+		if strings.Split(rewrapped, ":")[4] != strings.Split(encrypted, ":")[4] {
+			t.Error("expected the data to be kept as is after rewrapping")
+		}
+
+		if decrypted, err := newEnv.Decrypt(rewrapped); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		} else if decrypted != plaintext {
+			t.Errorf("expected '%s', got '%s'", plaintext, decrypted)
+		}
+
+		if _, err := oldEnv.Decrypt(rewrapped); !errors.Is(err, ErrKeyMismatch) {
+			t.Errorf("expected ErrKeyMismatch, got %v", err)
+		}
+
+		// 已经由新主密钥加密的值应原样返回，以便轮换中断后可以重复执行
+		if again, err := oldEnv.Rewrap(rewrapped, newEnv); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		} else if again != rewrapped {
+			t.Errorf("expected the rewrapped value to be kept as is, got '%s'", again)
+		}
+
+		// 由其他主密钥加密的值无法轮换
+		if _, err := newTestEnvelope(t, 3).Rewrap(encrypted, newEnv); !errors.Is(err, ErrKeyMismatch) {
+			t.Errorf("expected ErrKeyMismatch, got %v", err)
+		}
+	})
+}
+
+func TestLoadMasterKey(t *testing.T) {
+	masterKey := bytes.Repeat([]byte{0xab}, 32)
+
+	testCases := []struct {
+		name    string
+		key     string
+		want    []byte
+		wantErr bool
+	}{
+		{name: "empty", key: "", want: nil},
+		{name: "base64", key: base64.StdEncoding.EncodeToString(masterKey), want: masterKey},
+		{name: "hex", key: strings.Repeat("ab", 32), want: masterKey},
+		{name: "with spaces", key: " " + strings.Repeat("ab", 32) + "\n", want: masterKey},
+		{name: "too short", key: base64.StdEncoding.EncodeToString(masterKey[:16]), wantErr: true},
+		{name: "invalid", key: "not-a-key", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := LoadMasterKey(tc.key, "")
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("expected error: %v, got %v", tc.wantErr, err)
+			}
+			if !bytes.Equal(got, tc.want) {
+				t.Errorf("expected %x, got %x", tc.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package encryption
+
+import (
+	"log/slog"
+
+	"github.com/pocketbase/pocketbase/core"
+
+	"github.com/certimate-go/certimate/internal/app"
+)
+
+func Setup() {
+	if env, err := GetEnvelope(); err != nil {
+		app.GetLogger().Error("failed to load encryption master key", slog.Any("error", err))
+	} else if env == nil {
+		app.GetLogger().Warn("encryption master key is not configured, sensitive data will be stored in plaintext")
+	}
+
+	registerEncryptedRecordEvents()
+}
+
+func registerEncryptedRecordEvents() {
+	pb := app.GetApp()
+
+	for collectionName, fields := range EncryptedFields {
+		// 通过 API 写入的记录，需在保存前加密
+		pb.OnRecordCreateRequest(collectionName).BindFunc(func(e *core.RecordRequestEvent) error {
+			for _, field := range fields {
+				if err := EncryptRecordField(e.Record, field); err != nil {
+					return err
+				}
+			}
+
+			return e.Next()
+		})
+		pb.OnRecordUpdateRequest(collectionName).BindFunc(func(e *core.RecordRequestEvent) error {
+			for _, field := range fields {
+				if err := EncryptRecordField(e.Record, field); err != nil {
+					return err
+				}
+			}
+
+			return e.Next()
+		})
+
+		// 通过 API 读取的记录，需在返回前解密
+		pb.OnRecordEnrich(collectionName).BindFunc(func(e *core.RecordEnrichEvent) error {
+			for _, field := range fields {
+				if err := DecryptRecordField(e.Record, field); err != nil {
+					app.GetLogger().Warn("failed to decrypt record", slog.String("collection", collectionName), slog.String("id", e.Record.Id), slog.Any("error", err))
+				}
+			}
+
+			return e.Next()
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package gitops
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/certimate-go/certimate/internal/domain"
+	xyaml "github.com/certimate-go/certimate/pkg/utils/yaml"
+)
+
+type accessManifestFile struct {
+	Source   string
+	Manifest *domain.AccessManifest
+}
+
+type workflowManifestFile struct {
+	Source   string
+	Manifest *domain.WorkflowManifest
+}
+
+type manifestSet struct {
+	Accesses    []*accessManifestFile
+	Workflows   []*workflowManifestFile
+	Fingerprint string
+}
+
+// 递归读取目录中的所有清单文件（*.yaml、*.yml、*.json）。
+// 隐藏目录（如 ".git"）及未声明清单版本的文件将被忽略。
+func loadManifests(dir string) (*manifestSet, error) {
+	set := &manifestSet{
+		Accesses:  make([]*accessManifestFile, 0),
+		Workflows: make([]*workflowManifestFile, 0),
+	}
+
+	hasher := sha256.New()
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			if path != dir && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".yaml", ".yml", ".json":
+		default:
+			return nil
+		}
+
+		source, _ := filepath.Rel(dir, path)
+		source = filepath.ToSlash(source)
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read manifest '%s': %w", source, err)
+		}
+
+		hasher.Write([]byte(source))
+		hasher.Write(content)
+
+		if err := set.parse(source, content); err != nil {
+			return fmt.Errorf("invalid manifest '%s': %w", source, err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	set.Fingerprint = hex.EncodeToString(hasher.Sum(nil))
+	return set, nil
+}
+
+func (s *manifestSet) parse(source string, content []byte) error {
+	data, err := xyaml.ToJSON(content)
+	if err != nil {
+		return err
+	}
+
+	header := &struct {
+		Version string `json:"version"`
+		Kind    string `json:"kind"`
+	}{}
+	if err := json.Unmarshal(data, header); err != nil || header.Version == "" {
+		return nil
+	}
+
+	switch header.Kind {
+	case domain.ManifestKindAccess:
+		manifest := &domain.AccessManifest{}
+		if err := json.Unmarshal(data, manifest); err != nil {
+			return err
+		} else if err := manifest.Verify(); err != nil {
+			return err
+		}
+
+		for _, existing := range s.Accesses {
+			if existing.Manifest.Name == manifest.Name {
+				return fmt.Errorf("access '%s' is already declared in '%s'", manifest.Name, existing.Source)
+			}
+		}
+
+		s.Accesses = append(s.Accesses, &accessManifestFile{Source: source, Manifest: manifest})
+
+	case domain.ManifestKindWorkflow, "":
+		manifest := &domain.WorkflowManifest{}
+		if err := json.Unmarshal(data, manifest); err != nil {
+			return err
+		} else if err := manifest.Verify(); err != nil {
+			return err
+		} else if err := manifest.Graph.Verify(); err != nil {
+			return fmt.Errorf("invalid graph: %w", err)
+		}
+
+		for _, existing := range s.Workflows {
+			if existing.Manifest.Name == manifest.Name {
+				return fmt.Errorf("workflow '%s' is already declared in '%s'", manifest.Name, existing.Source)
+			}
+		}
+
+		s.Workflows = append(s.Workflows, &workflowManifestFile{Source: source, Manifest: manifest})
+
+	default:
+		return fmt.Errorf("unsupported manifest kind '%s'", header.Kind)
+	}
+
+	return nil
+}
@@ -0,0 +1,39 @@
+package gitops
+
+import (
+	"fmt"
+
+	"github.com/pocketbase/pocketbase/core"
+
+	"github.com/certimate-go/certimate/internal/app"
+	"github.com/certimate-go/certimate/internal/domain"
+)
+
+func registerManagedRecordEvents() {
+	pb := app.GetApp()
+
+	collectionNames := []string{domain.CollectionNameAccess, domain.CollectionNameWorkflow}
+
+	// 不允许通过 API 将记录标记为受管记录
+	pb.OnRecordCreateRequest(collectionNames...).BindFunc(func(e *core.RecordRequestEvent) error {
+		e.Record.Set("managedBy", "")
+		return e.Next()
+	})
+
+	// 受管记录在 API 中只读，只能通过修改清单文件变更
+	pb.OnRecordUpdateRequest(collectionNames...).BindFunc(func(e *core.RecordRequestEvent) error {
+		if managedBy := e.Record.Original().GetString("managedBy"); managedBy != "" {
+			return e.ForbiddenError(fmt.Sprintf("The record is managed by '%s' and cannot be modified via API.", managedBy), nil)
+		}
+
+		e.Record.Set("managedBy", "")
+		return e.Next()
+	})
+	pb.OnRecordDeleteRequest(collectionNames...).BindFunc(func(e *core.RecordRequestEvent) error {
+		if managedBy := e.Record.GetString("managedBy"); managedBy != "" {
+			return e.ForbiddenError(fmt.Sprintf("The record is managed by '%s' and cannot be deleted via API.", managedBy), nil)
+		}
+
+		return e.Next()
+	})
+}
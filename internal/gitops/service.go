@@ -0,0 +1,517 @@
+package gitops
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/samber/lo"
+
+	"github.com/certimate-go/certimate/internal/app"
+	"github.com/certimate-go/certimate/internal/domain"
+	"github.com/certimate-go/certimate/internal/domain/dtos"
+	xenv "github.com/certimate-go/certimate/pkg/utils/env"
+)
+
+const (
+	// 由 GitOps 管理的记录的 `managedBy` 字段值
+	ManagedByGitOps = "gitops"
+
+	envDir   = "CERTIMATE_GITOPS_DIR"
+	envPrune = "CERTIMATE_GITOPS_PRUNE"
+
+	pbJobKey = "syncGitOpsManifests"
+)
+
+const (
+	changeActionCreate  = "create"
+	changeActionUpdate  = "update"
+	changeActionDisable = "disable"
+	changeActionDelete  = "delete"
+)
+
+type GitOpsService struct {
+	accessRepo   accessRepository
+	workflowRepo workflowRepository
+	workflowSvc  workflowService
+}
+
+// 路由与定时任务各自持有服务实例，因此同步锁及清单指纹须在包级别共享
+var (
+	syncMtx         sync.Mutex
+	lastFingerprint string
+)
+
+func NewGitOpsService(accessRepo accessRepository, workflowRepo workflowRepository, workflowSvc workflowService) *GitOpsService {
+	return &GitOpsService{
+		accessRepo:   accessRepo,
+		workflowRepo: workflowRepo,
+		workflowSvc:  workflowSvc,
+	}
+}
+
+func (s *GitOpsService) InitSchedule(ctx context.Context) error {
+	if xenv.GetString(envDir) == "" {
+		return nil
+	}
+
+	registerManagedRecordEvents()
+
+	// 启动时立即同步一次，之后每分钟检查清单文件是否有变化
+	s.syncIfChanged(ctx)
+	app.GetScheduler().MustAdd(pbJobKey, "* * * * *", func() {
+		s.syncIfChanged(context.Background())
+	})
+
+	return nil
+}
+
+// 将清单目录中声明的授权和工作流同步到数据库中。
+// 试运行模式下只返回变更计划，不做任何修改。
+func (s *GitOpsService) Sync(ctx context.Context, req *dtos.GitOpsSyncReq) (*dtos.GitOpsSyncResp, error) {
+	dir := xenv.GetString(envDir)
+	if dir == "" {
+		return nil, domain.NewError(400, fmt.Sprintf("gitops is not enabled, please set the environment variable '%s'", envDir))
+	}
+
+	syncMtx.Lock()
+	defer syncMtx.Unlock()
+
+	manifests, err := loadManifests(dir)
+	if err != nil {
+		return nil, domain.NewError(400, err.Error())
+	}
+
+	changes, err := s.reconcile(ctx, manifests, req.DryRun)
+	if err != nil {
+		return nil, err
+	}
+
+	if !req.DryRun {
+		lastFingerprint = manifests.Fingerprint
+	}
+
+	return &dtos.GitOpsSyncResp{
+		DryRun:  req.DryRun,
+		Changes: changes,
+	}, nil
+}
+
+func (s *GitOpsService) syncIfChanged(ctx context.Context) {
+	manifests, err := loadManifests(xenv.GetString(envDir))
+	if err != nil {
+		app.GetLogger().Error("gitops: failed to load manifests", slog.Any("error", err))
+		return
+	}
+
+	syncMtx.Lock()
+	unchanged := manifests.Fingerprint == lastFingerprint
+	syncMtx.Unlock()
+	if unchanged {
+		return
+	}
+
+	res, err := s.Sync(ctx, &dtos.GitOpsSyncReq{})
+	if err != nil {
+		app.GetLogger().Error("gitops: failed to sync manifests", slog.Any("error", err))
+		return
+	}
+
+	for _, change := range res.Changes {
+		app.GetLogger().Info(fmt.Sprintf("gitops: %s %s '%s'", change.Action, change.Kind, change.Name), slog.String("source", change.Source), slog.Any("fields", change.Fields))
+	}
+}
+
+type operation struct {
+	change *dtos.GitOpsChange
+	apply  func(ctx context.Context) error
+	commit func(ctx context.Context) error // 事务提交后执行的动作，如同步定时任务
+}
+
+func (s *GitOpsService) reconcile(ctx context.Context, manifests *manifestSet, dryRun bool) ([]*dtos.GitOpsChange, error) {
+	prune := xenv.GetBool(envPrune)
+
+	accessOps, accessNames, err := s.planAccesses(ctx, manifests.Accesses, prune)
+	if err != nil {
+		return nil, err
+	}
+
+	workflowOps, err := s.planWorkflows(ctx, manifests.Workflows, accessNames, prune)
+	if err != nil {
+		return nil, err
+	}
+
+	// 授权须先于工作流应用，以便工作流能够引用新创建的授权
+	ops := append(accessOps, workflowOps...)
+	changes := lo.Map(ops, func(op *operation, _ int) *dtos.GitOpsChange { return op.change })
+	if dryRun || len(ops) == 0 {
+		return changes, nil
+	}
+
+	// 在同一事务中应用全部变更，任一变更失败时全部回滚，以免清单只被部分同步
+	err = app.GetApp().RunInTransaction(func(txApp core.App) error {
+		txCtx := app.WithTxApp(ctx, txApp)
+		for _, op := range ops {
+			if err := op.apply(txCtx); err != nil {
+				return fmt.Errorf("failed to %s %s '%s': %w", op.change.Action, op.change.Kind, op.change.Name, err)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, op := range ops {
+		if op.commit == nil {
+			continue
+		}
+
+		if err := op.commit(ctx); err != nil {
+			app.GetLogger().Warn(fmt.Sprintf("gitops: failed to finish %s %s '%s'", op.change.Action, op.change.Kind, op.change.Name), slog.Any("error", err))
+		}
+	}
+
+	return changes, nil
+}
+
+func (s *GitOpsService) planAccesses(ctx context.Context, files []*accessManifestFile, prune bool) ([]*operation, map[string]struct{}, error) {
+	accesses, err := s.accessRepo.ListWithExprs(ctx, dbx.HashExp{"deleted": ""})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// 收集所有可被工作流引用的授权名称，包括即将创建的授权
+	accessNames := make(map[string]struct{})
+	for _, access := range accesses {
+		accessNames[access.Name] = struct{}{}
+	}
+
+	ops := make([]*operation, 0)
+	declared := make(map[string]struct{})
+	for _, file := range files {
+		manifest := file.Manifest
+		declared[manifest.Name] = struct{}{}
+		accessNames[manifest.Name] = struct{}{}
+
+		var existing *domain.Access
+		for _, access := range accesses {
+			if access.Name != manifest.Name {
+				continue
+			}
+
+			if access.ManagedBy != ManagedByGitOps {
+				return nil, nil, domain.NewError(409, fmt.Sprintf("access '%s' declared in '%s' conflicts with an unmanaged access with the same name", manifest.Name, file.Source))
+			}
+
+			existing = access
+			break
+		}
+
+		if existing == nil {
+			access := &domain.Access{
+				Name:      manifest.Name,
+				Provider:  manifest.Provider,
+				Config:    manifest.Config,
+				ManagedBy: ManagedByGitOps,
+			}
+			ops = append(ops, &operation{
+				change: &dtos.GitOpsChange{Kind: domain.ManifestKindAccess, Name: manifest.Name, Action: changeActionCreate, Source: file.Source},
+				apply: func(ctx context.Context) error {
+					_, err := s.accessRepo.Save(ctx, access)
+					return err
+				},
+			})
+			continue
+		}
+
+		fields := make([]string, 0)
+		if existing.Provider != manifest.Provider {
+			fields = append(fields, "provider")
+		}
+		if !equalsJSON(existing.Config, manifest.Config) {
+			fields = append(fields, "config")
+		}
+		if len(fields) == 0 {
+			continue
+		}
+
+		ops = append(ops, &operation{
+			change: &dtos.GitOpsChange{Kind: domain.ManifestKindAccess, Name: manifest.Name, Action: changeActionUpdate, Fields: fields, Source: file.Source},
+			apply: func(ctx context.Context) error {
+				existing.Provider = manifest.Provider
+				existing.Config = manifest.Config
+				_, err := s.accessRepo.Save(ctx, existing)
+				return err
+			},
+		})
+	}
+
+	// 清单中已移除的授权：仅在开启清理时软删除
+	if prune {
+		for _, access := range accesses {
+			if access.ManagedBy != ManagedByGitOps {
+				continue
+			}
+			if _, ok := declared[access.Name]; ok {
+				continue
+			}
+
+			delete(accessNames, access.Name)
+			ops = append(ops, &operation{
+				change: &dtos.GitOpsChange{Kind: domain.ManifestKindAccess, Name: access.Name, Action: changeActionDelete},
+				apply: func(ctx context.Context) error {
+					access.DeletedAt = lo.ToPtr(time.Now())
+					_, err := s.accessRepo.Save(ctx, access)
+					return err
+				},
+			})
+		}
+	}
+
+	return ops, accessNames, nil
+}
+
+func (s *GitOpsService) planWorkflows(ctx context.Context, files []*workflowManifestFile, accessNames map[string]struct{}, prune bool) ([]*operation, error) {
+	workflows, err := s.workflowRepo.ListWithExprs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	accesses, err := s.accessRepo.ListWithExprs(ctx, dbx.HashExp{"deleted": ""})
+	if err != nil {
+		return nil, err
+	}
+
+	accessNamesById := make(map[string]string)
+	for _, access := range accesses {
+		accessNamesById[access.Id] = access.Name
+	}
+
+	ops := make([]*operation, 0)
+	declared := make(map[string]struct{})
+	for _, file := range files {
+		manifest := file.Manifest
+		declared[manifest.Name] = struct{}{}
+
+		if err := verifyWorkflowManifestPlaceholders(manifest, accessNames); err != nil {
+			return nil, domain.NewError(400, fmt.Sprintf("invalid manifest '%s': %s", file.Source, err.Error()))
+		}
+
+		var existing *domain.Workflow
+		for _, workflow := range workflows {
+			if workflow.Name != manifest.Name {
+				continue
+			}
+
+			if workflow.ManagedBy != ManagedByGitOps {
+				return nil, domain.NewError(409, fmt.Sprintf("workflow '%s' declared in '%s' conflicts with an unmanaged workflow with the same name", manifest.Name, file.Source))
+			}
+
+			existing = workflow
+			break
+		}
+
+		if existing == nil {
+			workflow := &domain.Workflow{ManagedBy: ManagedByGitOps}
+			ops = append(ops, &operation{
+				change: &dtos.GitOpsChange{Kind: domain.ManifestKindWorkflow, Name: manifest.Name, Action: changeActionCreate, Source: file.Source},
+				apply: func(ctx context.Context) error {
+					if err := s.workflowSvc.ApplyWorkflowManifest(ctx, workflow, manifest, nil); err != nil {
+						return err
+					}
+
+					_, err := s.workflowRepo.Save(ctx, workflow)
+					return err
+				},
+				commit: func(ctx context.Context) error {
+					return s.workflowSvc.SyncWorkflowSchedule(ctx, workflow)
+				},
+			})
+			continue
+		}
+
+		fields := diffWorkflow(existing, manifest, accessNamesById)
+		if len(fields) == 0 {
+			continue
+		}
+
+		ops = append(ops, &operation{
+			change: &dtos.GitOpsChange{Kind: domain.ManifestKindWorkflow, Name: manifest.Name, Action: changeActionUpdate, Fields: fields, Source: file.Source},
+			apply: func(ctx context.Context) error {
+				if err := s.workflowSvc.ApplyWorkflowManifest(ctx, existing, manifest, nil); err != nil {
+					return err
+				}
+
+				_, err := s.workflowRepo.Save(ctx, existing)
+				return err
+			},
+			commit: func(ctx context.Context) error {
+				return s.workflowSvc.SyncWorkflowSchedule(ctx, existing)
+			},
+		})
+	}
+
+	// 清单中已移除的工作流：开启清理时删除，否则仅禁用
+	for _, workflow := range workflows {
+		if workflow.ManagedBy != ManagedByGitOps {
+			continue
+		}
+		if _, ok := declared[workflow.Name]; ok {
+			continue
+		}
+
+		if prune {
+			ops = append(ops, &operation{
+				change: &dtos.GitOpsChange{Kind: domain.ManifestKindWorkflow, Name: workflow.Name, Action: changeActionDelete},
+				apply: func(ctx context.Context) error {
+					return s.workflowRepo.Delete(ctx, workflow)
+				},
+				commit: func(ctx context.Context) error {
+					// 已删除的工作流按禁用处理，以移除其定时任务
+					workflow.Enabled = false
+					return s.workflowSvc.SyncWorkflowSchedule(ctx, workflow)
+				},
+			})
+		} else if workflow.Enabled {
+			ops = append(ops, &operation{
+				change: &dtos.GitOpsChange{Kind: domain.ManifestKindWorkflow, Name: workflow.Name, Action: changeActionDisable},
+				apply: func(ctx context.Context) error {
+					workflow.Enabled = false
+					_, err := s.workflowRepo.Save(ctx, workflow)
+					return err
+				},
+				commit: func(ctx context.Context) error {
+					return s.workflowSvc.SyncWorkflowSchedule(ctx, workflow)
+				},
+			})
+		}
+	}
+
+	return ops, nil
+}
+
+// 比较工作流与清单，返回存在差异的字段。
+// 工作流图与数据库中存储的原始数据比较：仅将授权记录 ID 还原为清单中的名称引用，敏感字段及外部密钥引用均原样比较。
+func diffWorkflow(workflow *domain.Workflow, manifest *domain.WorkflowManifest, accessNamesById map[string]string) []string {
+	fields := make([]string, 0)
+	if workflow.Description != manifest.Description {
+		fields = append(fields, "description")
+	}
+	if workflow.Trigger != manifest.Trigger {
+		fields = append(fields, "trigger")
+	}
+	if workflow.TriggerCron != lo.If(manifest.Trigger == domain.WorkflowTriggerTypeScheduled, manifest.TriggerCron).Else("") {
+		fields = append(fields, "triggerCron")
+	}
+	if workflow.Enabled != manifest.Enabled {
+		fields = append(fields, "enabled")
+	}
+	if workflow.ConcurrencyPolicy != manifest.ConcurrencyPolicy {
+		fields = append(fields, "concurrencyPolicy")
+	}
+	if workflow.Timeout != manifest.Timeout {
+		fields = append(fields, "timeout")
+	}
+	if workflow.HasDraft || !equalsJSON(normalizeWorkflowGraph(workflow.GraphContent, accessNamesById), manifest.Graph) {
+		fields = append(fields, "graph")
+	}
+
+	return fields
+}
+
+// 将工作流图中的授权记录 ID 替换为名称引用，以便与清单比较。
+// 找不到的授权记录将保留原 ID，此时必然与清单不一致，从而重新应用清单。
+func normalizeWorkflowGraph(graph *domain.WorkflowGraph, accessNamesById map[string]string) *domain.WorkflowGraph {
+	if graph == nil {
+		return nil
+	}
+
+	data, err := json.Marshal(graph)
+	if err != nil {
+		return nil
+	}
+
+	normalized := &domain.WorkflowGraph{}
+	if err := json.Unmarshal(data, normalized); err != nil {
+		return nil
+	}
+
+	walkWorkflowNodes(normalized.Nodes, func(node *domain.WorkflowNode) {
+		for _, field := range domain.WorkflowNodeAccessRefFields {
+			accessId, ok := node.Data.Config[field].(string)
+			if !ok || accessId == "" {
+				continue
+			}
+
+			if name, ok := accessNamesById[accessId]; ok {
+				node.Data.Config[field] = domain.BuildWorkflowManifestPlaceholder(domain.WorkflowManifestPlaceholderKindAccess, name)
+			}
+		}
+	})
+
+	return normalized
+}
+
+// 校验工作流清单中的占位符：
+// 引用的授权必须已存在或在清单目录中声明；敏感信息须通过外部密钥引用提供，不支持输入占位符。
+func verifyWorkflowManifestPlaceholders(manifest *domain.WorkflowManifest, accessNames map[string]struct{}) error {
+	var errs []error
+	walkWorkflowNodes(manifest.Graph.Nodes, func(node *domain.WorkflowNode) {
+		node.Data.Config.Walk(func(field string, value any) {
+			kind, name, ok := domain.ParseWorkflowManifestPlaceholder(value)
+			if !ok {
+				return
+			}
+
+			switch kind {
+			case domain.WorkflowManifestPlaceholderKindAccess:
+				if _, ok := accessNames[name]; !ok {
+					errs = append(errs, fmt.Errorf("access '%s' referenced by node '%s' not found", name, node.Id))
+				}
+
+			case domain.WorkflowManifestPlaceholderKindInput:
+				errs = append(errs, fmt.Errorf("input placeholder of field '%s' in node '%s' is not supported", field, node.Id))
+			}
+		})
+	})
+
+	return errors.Join(errs...)
+}
+
+func walkWorkflowNodes(nodes []*domain.WorkflowNode, fn func(node *domain.WorkflowNode)) {
+	for _, node := range nodes {
+		fn(node)
+
+		if len(node.Blocks) > 0 {
+			walkWorkflowNodes(node.Blocks, fn)
+		}
+	}
+}
+
+// 以 JSON 语义比较两个值是否相等，忽略数值类型及零值字段等差异。
+func equalsJSON(a, b any) bool {
+	normalize := func(v any) any {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return nil
+		}
+
+		var res any
+		if err := json.Unmarshal(data, &res); err != nil {
+			return nil
+		}
+
+		return res
+	}
+
+	return reflect.DeepEqual(normalize(a), normalize(b))
+}
@@ -0,0 +1,25 @@
+package gitops
+
+import (
+	"context"
+
+	"github.com/pocketbase/dbx"
+
+	"github.com/certimate-go/certimate/internal/domain"
+)
+
+type accessRepository interface {
+	ListWithExprs(ctx context.Context, exprs ...dbx.Expression) ([]*domain.Access, error)
+	Save(ctx context.Context, access *domain.Access) (*domain.Access, error)
+}
+
+type workflowRepository interface {
+	ListWithExprs(ctx context.Context, exprs ...dbx.Expression) ([]*domain.Workflow, error)
+	Save(ctx context.Context, workflow *domain.Workflow) (*domain.Workflow, error)
+	Delete(ctx context.Context, workflow *domain.Workflow) error
+}
+
+type workflowService interface {
+	ApplyWorkflowManifest(ctx context.Context, workflow *domain.Workflow, manifest *domain.WorkflowManifest, inputs map[string]string) error
+	SyncWorkflowSchedule(ctx context.Context, workflow *domain.Workflow) error
+}
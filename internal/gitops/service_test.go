@@ -0,0 +1,103 @@
+package gitops
+
+import (
+	"testing"
+
+	"github.com/certimate-go/certimate/internal/domain"
+)
+
+func TestDiffWorkflow(t *testing.T) {
+	newGraph := func(accessRef string, password string) *domain.WorkflowGraph {
+		return &domain.WorkflowGraph{
+			Nodes: []*domain.WorkflowNode{
+				{Id: "start", Type: domain.WorkflowNodeTypeStart},
+				{
+					Id:   "deploy",
+					Type: domain.WorkflowNodeTypeBizDeploy,
+					Data: domain.WorkflowNodeData{
+						Name: "deploy",
+						Config: domain.WorkflowNodeConfig{
+							"provider":         "ssh",
+							"providerAccessId": accessRef,
+							"providerConfig":   map[string]any{"pfxPassword": password},
+						},
+					},
+				},
+				{Id: "end", Type: domain.WorkflowNodeTypeEnd},
+			},
+		}
+	}
+
+	accessNamesById := map[string]string{"access1": "my-ssh"}
+	accessPlaceholder := domain.BuildWorkflowManifestPlaceholder(domain.WorkflowManifestPlaceholderKindAccess, "my-ssh")
+
+	workflow := &domain.Workflow{
+		Name:         "test",
+		Trigger:      domain.WorkflowTriggerTypeManual,
+		Enabled:      true,
+		GraphContent: newGraph("access1", "${secret:env:PFX_PASSWORD}"),
+		HasContent:   true,
+	}
+
+	testCases := []struct {
+		name     string
+		manifest *domain.WorkflowManifest
+		want     []string
+	}{
+		{
+			name: "unchanged, including the secret reference",
+			manifest: &domain.WorkflowManifest{
+				Name:    "test",
+				Trigger: domain.WorkflowTriggerTypeManual,
+				Enabled: true,
+				Graph:   newGraph(accessPlaceholder, "${secret:env:PFX_PASSWORD}"),
+			},
+			want: []string{},
+		},
+		{
+			name: "secret reference changed",
+			manifest: &domain.WorkflowManifest{
+				Name:    "test",
+				Trigger: domain.WorkflowTriggerTypeManual,
+				Enabled: true,
+				Graph:   newGraph(accessPlaceholder, "${secret:env:PFX_PASSWORD_NEW}"),
+			},
+			want: []string{"graph"},
+		},
+		{
+			name: "fields changed",
+			manifest: &domain.WorkflowManifest{
+				Name:    "test",
+				Trigger: domain.WorkflowTriggerTypeManual,
+				Enabled: false,
+				Timeout: 60,
+				Graph:   newGraph(accessPlaceholder, "${secret:env:PFX_PASSWORD}"),
+			},
+			want: []string{"enabled", "timeout"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := diffWorkflow(workflow, tc.manifest, accessNamesById)
+			if !equalsJSON(got, tc.want) {
+				t.Errorf("expected %v, got %v", tc.want, got)
+			}
+		})
+	}
+
+	// 比较时不应修改原始的工作流图
+	if workflow.GraphContent.Nodes[1].Data.Config["providerAccessId"] != "access1" {
+		t.Errorf("expected the stored graph not to be modified")
+	}
+
+	// 引用的授权已被删除时，视为工作流图发生变化
+	if got := diffWorkflow(workflow, &domain.WorkflowManifest{
+		Name:    "test",
+		Trigger: domain.WorkflowTriggerTypeManual,
+		Enabled: true,
+		Graph:   newGraph(accessPlaceholder, "${secret:env:PFX_PASSWORD}"),
+	}, map[string]string{}); !equalsJSON(got, []string{"graph"}) {
+		t.Errorf("expected the graph to be changed, got %v", got)
+	}
+}
@@ -0,0 +1,432 @@
+package notify
+
+import (
+	"bytes"
+	"fmt"
+	htmltemplate "html/template"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	texttemplate "text/template"
+	"text/template/parse"
+	"time"
+
+	"github.com/certimate-go/certimate/internal/domain"
+	"github.com/certimate-go/certimate/pkg/core/notifier/providers/email"
+	xmaps "github.com/certimate-go/certimate/pkg/utils/maps"
+)
+
+type MessageFormat string
+
+const (
+	MessageFormatPlain    = MessageFormat("plain")
+	MessageFormatMarkdown = MessageFormat("markdown")
+	MessageFormatMrkdwn   = MessageFormat("mrkdwn") // Slack 专有的 Markdown 方言
+	MessageFormatHTML     = MessageFormat("html")
+)
+
+// 获取通知提供商所支持的消息格式。
+//
+// 入参：
+//   - provider: 通知提供商。
+//   - providerExtendedConfig: 通知提供商额外配置。
+//
+// 出参：
+//   - 消息格式。
+func GetMessageFormat(provider domain.NotificationProviderType, providerExtendedConfig map[string]any) MessageFormat {
+	switch provider {
+	case domain.NotificationProviderTypeEmail:
+		if xmaps.GetString(providerExtendedConfig, "format") == email.MESSAGE_FORMAT_HTML {
+			return MessageFormatHTML
+		}
+		return MessageFormatPlain
+
+	case domain.NotificationProviderTypeDiscordBot,
+		domain.NotificationProviderTypeMattermost:
+		return MessageFormatMarkdown
+
+	case domain.NotificationProviderTypeSlackBot:
+		return MessageFormatMrkdwn
+
+	default:
+		return MessageFormatPlain
+	}
+}
+
+var reTemplateVarName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// 渲染消息模板。
+//
+// 模板语法同 Go 标准库 text/template，数据中的每个顶层键都会被预先声明为同名的模板变量，
+// 因此可直接使用如 `{{ $certificate.daysLeft }}`、`{{ $nodes.<nodeId>.certificate.daysLeft }}` 的写法。
+// 对于 ID 中包含特殊字符的节点，可使用 `{{ (node "<nodeId>").certificate.daysLeft }}`。
+//
+// 引用不存在的变量或字段时将返回错误，而不是输出 "<no value>"。对于可能不存在的值，
+// 可使用 `{{ default "-" (lookup "nodes.<nodeId>.certificate.daysLeft") }}` 的写法。
+//
+// 除标准库内置函数外，还提供以下辅助函数：
+//   - lookup <path>：按以半角句点分隔的路径读取数据，不存在时返回空字符串。
+//   - formatDate <layout> <time>：按 Go 时间布局格式化日期时间。
+//   - date <time>、datetime <time>：格式化为 "2006-01-02"、"2006-01-02 15:04:05"。
+//   - since <time>、until <time>：计算与当前时间的间隔。
+//   - daysUntil <time>：计算距今的剩余天数。
+//   - duration <value>：格式化时长，参数为 time.Duration 或秒数。
+//   - default <fallback> <value>、upper、lower、trim、join、split、replace、contains、escapeMarkdown、escapeMrkdwn。
+//
+// 入参：
+//   - tmpl: 模板内容。
+//   - format: 消息格式。为 [MessageFormatHTML]、[MessageFormatMarkdown]、[MessageFormatMrkdwn] 时，输出的变量值将按相应格式被转义。
+//   - data: 模板数据。
+//
+// 出参：
+//   - 渲染结果。
+//   - 错误。
+func RenderTemplate(tmpl string, format MessageFormat, data map[string]any) (string, error) {
+	if !strings.Contains(tmpl, "{{") {
+		return tmpl, nil
+	}
+
+	data = normalizeTemplateData(data).(map[string]any)
+
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		if reTemplateVarName.MatchString(key) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	var prelude strings.Builder
+	for _, key := range keys {
+		prelude.WriteString(fmt.Sprintf("{{ $%s := .%s }}", key, key))
+	}
+
+	funcs := templateFuncs(data)
+
+	var buf bytes.Buffer
+	switch format {
+	case MessageFormatHTML:
+		t, err := htmltemplate.New("message").Option("missingkey=error").Funcs(htmltemplate.FuncMap(funcs)).Parse(prelude.String() + tmpl)
+		if err != nil {
+			return "", fmt.Errorf("failed to parse template: %w", err)
+		}
+		if err := t.Execute(&buf, data); err != nil {
+			return "", fmt.Errorf("failed to render template: %w", err)
+		}
+
+	default:
+		t, err := texttemplate.New("message").Option("missingkey=error").Funcs(funcs).Parse(prelude.String() + tmpl)
+		if err != nil {
+			return "", fmt.Errorf("failed to parse template: %w", err)
+		}
+
+		switch format {
+		case MessageFormatMarkdown:
+			err = escapeTemplateActions(t, "escapeMarkdown")
+		case MessageFormatMrkdwn:
+			err = escapeTemplateActions(t, "escapeMrkdwn")
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to parse template: %w", err)
+		}
+
+		if err := t.Execute(&buf, data); err != nil {
+			return "", fmt.Errorf("failed to render template: %w", err)
+		}
+	}
+
+	return buf.String(), nil
+}
+
+// 用于在模板中输出日期时间的包装类型，以保持与旧版一致的输出格式（RFC3339）。
+type templateTime struct {
+	time.Time
+}
+
+func (t templateTime) String() string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.Format(time.RFC3339)
+}
+
+// 为模板中所有输出值的动作追加转义函数，效果同 html/template 的自动转义。
+// 已显式调用该转义函数的动作不会被重复转义。
+func escapeTemplateActions(t *texttemplate.Template, escaper string) error {
+	// 借助辅助模板构造调用转义函数的命令节点
+	helper, err := texttemplate.New("escaper").Funcs(texttemplate.FuncMap{escaper: func(any) string { return "" }}).Parse("{{ . | " + escaper + " }}")
+	if err != nil {
+		return err
+	}
+	escapeCmd := helper.Tree.Root.Nodes[0].(*parse.ActionNode).Pipe.Cmds[1]
+
+	var walk func(node parse.Node)
+	walk = func(node parse.Node) {
+		switch n := node.(type) {
+		case *parse.ListNode:
+			if n == nil {
+				return
+			}
+			for _, child := range n.Nodes {
+				walk(child)
+			}
+
+		case *parse.ActionNode:
+			// 变量声明或赋值不产生输出
+			if len(n.Pipe.Decl) > 0 {
+				return
+			}
+
+			lastCmd := n.Pipe.Cmds[len(n.Pipe.Cmds)-1]
+			if ident, ok := lastCmd.Args[0].(*parse.IdentifierNode); ok && ident.Ident == escaper {
+				return
+			}
+
+			n.Pipe.Cmds = append(n.Pipe.Cmds, escapeCmd)
+
+		case *parse.IfNode:
+			walk(n.List)
+			walk(n.ElseList)
+
+		case *parse.RangeNode:
+			walk(n.List)
+			walk(n.ElseList)
+
+		case *parse.WithNode:
+			walk(n.List)
+			walk(n.ElseList)
+		}
+	}
+
+	for _, tmpl := range t.Templates() {
+		if tmpl.Tree != nil {
+			walk(tmpl.Tree.Root)
+		}
+	}
+
+	return nil
+}
+
+func normalizeTemplateData(value any) any {
+	switch v := value.(type) {
+	case nil:
+		// 避免空值被输出为 "<no value>"
+		return ""
+	case time.Time:
+		return templateTime{v}
+	case map[string]any:
+		m := make(map[string]any, len(v))
+		for key, val := range v {
+			m[key] = normalizeTemplateData(val)
+		}
+		return m
+	case []map[string]any:
+		s := make([]map[string]any, 0, len(v))
+		for _, val := range v {
+			s = append(s, normalizeTemplateData(val).(map[string]any))
+		}
+		return s
+	case []any:
+		s := make([]any, 0, len(v))
+		for _, val := range v {
+			s = append(s, normalizeTemplateData(val))
+		}
+		return s
+	default:
+		return value
+	}
+}
+
+func templateFuncs(data map[string]any) texttemplate.FuncMap {
+	return texttemplate.FuncMap{
+		"node": func(id string) map[string]any {
+			nodes, _ := data["nodes"].(map[string]any)
+			node, _ := nodes[id].(map[string]any)
+			return node
+		},
+		"lookup": func(path string) any {
+			var value any = data
+			for _, key := range strings.Split(path, ".") {
+				m, ok := value.(map[string]any)
+				if !ok {
+					return ""
+				}
+				if value, ok = m[key]; !ok {
+					return ""
+				}
+			}
+			return value
+		},
+		"formatDate": func(layout string, value any) (string, error) {
+			t, err := toTemplateTime(value)
+			if err != nil {
+				return "", err
+			}
+			if t.IsZero() {
+				return "-", nil
+			}
+			return t.Format(layout), nil
+		},
+		"date": func(value any) (string, error) {
+			t, err := toTemplateTime(value)
+			if err != nil {
+				return "", err
+			}
+			if t.IsZero() {
+				return "-", nil
+			}
+			return t.Format(time.DateOnly), nil
+		},
+		"datetime": func(value any) (string, error) {
+			t, err := toTemplateTime(value)
+			if err != nil {
+				return "", err
+			}
+			if t.IsZero() {
+				return "-", nil
+			}
+			return t.Format(time.DateTime), nil
+		},
+		"since": func(value any) (time.Duration, error) {
+			t, err := toTemplateTime(value)
+			if err != nil {
+				return 0, err
+			}
+			return time.Since(t), nil
+		},
+		"until": func(value any) (time.Duration, error) {
+			t, err := toTemplateTime(value)
+			if err != nil {
+				return 0, err
+			}
+			return time.Until(t), nil
+		},
+		"daysUntil": func(value any) (int, error) {
+			t, err := toTemplateTime(value)
+			if err != nil {
+				return 0, err
+			}
+			return int(math.Floor(time.Until(t).Hours() / 24)), nil
+		},
+		"duration": func(value any) (string, error) {
+			d, err := toTemplateDuration(value)
+			if err != nil {
+				return "", err
+			}
+			return formatTemplateDuration(d), nil
+		},
+		"default": func(fallback any, value any) any {
+			if value == nil {
+				return fallback
+			}
+			rv := reflect.ValueOf(value)
+			if rv.IsZero() {
+				return fallback
+			}
+			return value
+		},
+		"upper":    strings.ToUpper,
+		"lower":    strings.ToLower,
+		"trim":     strings.TrimSpace,
+		"replace":  strings.ReplaceAll,
+		"contains": strings.Contains,
+		"split":    strings.Split,
+		"join": func(sep string, value any) string {
+			rv := reflect.ValueOf(value)
+			if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+				return fmt.Sprintf("%v", value)
+			}
+
+			parts := make([]string, 0, rv.Len())
+			for i := 0; i < rv.Len(); i++ {
+				parts = append(parts, fmt.Sprintf("%v", rv.Index(i).Interface()))
+			}
+			return strings.Join(parts, sep)
+		},
+		"escapeMarkdown": func(value any) string {
+			s := fmt.Sprintf("%v", value)
+			return reMarkdownSpecialChars.ReplaceAllString(s, `\$0`)
+		},
+		"escapeMrkdwn": func(value any) string {
+			// Slack 的 mrkdwn 不支持反斜杠转义，仅需转义控制字符
+			s := fmt.Sprintf("%v", value)
+			return mrkdwnReplacer.Replace(s)
+		},
+	}
+}
+
+var reMarkdownSpecialChars = regexp.MustCompile("[\\\\`*_{}\\[\\]()#+\\-.!|<>~]")
+
+var mrkdwnReplacer = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+func toTemplateTime(value any) (time.Time, error) {
+	switch v := value.(type) {
+	case templateTime:
+		return v.Time, nil
+	case time.Time:
+		return v, nil
+	case string:
+		if v == "" || v == "-" {
+			return time.Time{}, nil
+		}
+		for _, layout := range []string{time.RFC3339Nano, time.DateTime, time.DateOnly} {
+			if t, err := time.Parse(layout, v); err == nil {
+				return t, nil
+			}
+		}
+		return time.Time{}, fmt.Errorf("failed to parse datetime: %s", v)
+	case nil:
+		return time.Time{}, nil
+	default:
+		return time.Time{}, fmt.Errorf("value is not a datetime: %v", value)
+	}
+}
+
+func toTemplateDuration(value any) (time.Duration, error) {
+	if d, ok := value.(time.Duration); ok {
+		return d, nil
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return time.Duration(rv.Int()) * time.Second, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return time.Duration(rv.Uint()) * time.Second, nil
+	case reflect.Float32, reflect.Float64:
+		return time.Duration(rv.Float() * float64(time.Second)), nil
+	}
+
+	return 0, fmt.Errorf("value is not a duration: %v", value)
+}
+
+func formatTemplateDuration(d time.Duration) string {
+	sign := ""
+	if d <= -time.Minute {
+		sign = "-"
+	}
+	if d < 0 {
+		d = -d
+	}
+
+	days := int64(d / (24 * time.Hour))
+	d -= time.Duration(days) * 24 * time.Hour
+	hours := int64(d / time.Hour)
+	d -= time.Duration(hours) * time.Hour
+	minutes := int64(d / time.Minute)
+
+	parts := make([]string, 0, 3)
+	if days > 0 {
+		parts = append(parts, fmt.Sprintf("%dd", days))
+	}
+	if hours > 0 {
+		parts = append(parts, fmt.Sprintf("%dh", hours))
+	}
+	if minutes > 0 || len(parts) == 0 {
+		parts = append(parts, fmt.Sprintf("%dm", minutes))
+	}
+
+	return sign + strings.Join(parts, " ")
+}
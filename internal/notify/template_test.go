@@ -0,0 +1,112 @@
+package notify
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRenderTemplate(t *testing.T) {
+	data := map[string]any{
+		"workflow": map[string]any{
+			"name": "my_workflow *prod*",
+		},
+		"certificate": map[string]any{
+			"commonName": "<example.com>",
+			"daysLeft":   7,
+			"notAfter":   time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+			"renewedBy":  nil,
+		},
+		"nodes": map[string]any{
+			"apply": map[string]any{
+				"certificate": map[string]any{"daysLeft": 30},
+			},
+		},
+	}
+
+	testCases := []struct {
+		name    string
+		tmpl    string
+		format  MessageFormat
+		want    string
+		wantErr bool
+	}{
+		{
+			name:   "plain",
+			tmpl:   "{{ $workflow.name }}: {{ $certificate.commonName }} expires in {{ $certificate.daysLeft }} days, at {{ datetime $certificate.notAfter }}",
+			format: MessageFormatPlain,
+			want:   "my_workflow *prod*: <example.com> expires in 7 days, at 2025-01-02 03:04:05",
+		},
+		{
+			name:   "no template",
+			tmpl:   "*hello*",
+			format: MessageFormatMarkdown,
+			want:   "*hello*",
+		},
+		{
+			name:    "missing key",
+			tmpl:    "{{ $certificate.unknown }}",
+			format:  MessageFormatPlain,
+			wantErr: true,
+		},
+		{
+			name:    "undefined variable",
+			tmpl:    "{{ $unknown }}",
+			format:  MessageFormatPlain,
+			wantErr: true,
+		},
+		{
+			name:   "nil value",
+			tmpl:   "[{{ $certificate.renewedBy }}]",
+			format: MessageFormatPlain,
+			want:   "[]",
+		},
+		{
+			name:   "lookup with default",
+			tmpl:   `{{ lookup "nodes.apply.certificate.daysLeft" }}/{{ default "-" (lookup "nodes.deploy.certificate.daysLeft") }}`,
+			format: MessageFormatPlain,
+			want:   "30/-",
+		},
+		{
+			name:   "markdown",
+			tmpl:   "**{{ $workflow.name }}**{{ if gt $certificate.daysLeft 0 }} _{{ $certificate.daysLeft }}_{{ end }}",
+			format: MessageFormatMarkdown,
+			want:   `**my\_workflow \*prod\***` + " _7_",
+		},
+		{
+			name:   "markdown escaped explicitly",
+			tmpl:   "{{ $workflow.name | escapeMarkdown }}",
+			format: MessageFormatMarkdown,
+			want:   `my\_workflow \*prod\*`,
+		},
+		{
+			name:   "markdown in range",
+			tmpl:   "{{ range $k, $v := $nodes }}{{ $k }}: {{ $v.certificate.daysLeft }}{{ end }}",
+			format: MessageFormatMarkdown,
+			want:   "apply: 30",
+		},
+		{
+			name:   "mrkdwn",
+			tmpl:   "*{{ $certificate.commonName }}* & {{ $workflow.name }}",
+			format: MessageFormatMrkdwn,
+			want:   "*&lt;example.com&gt;* & my_workflow *prod*",
+		},
+		{
+			name:   "html",
+			tmpl:   "<b>{{ $certificate.commonName }}</b>",
+			format: MessageFormatHTML,
+			want:   "<b>&lt;example.com&gt;</b>",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := RenderTemplate(tc.tmpl, tc.format, data)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("expected error: %v, got %v", tc.wantErr, err)
+			}
+			if got != tc.want {
+				t.Errorf("expected '%s', got '%s'", tc.want, got)
+			}
+		})
+	}
+}
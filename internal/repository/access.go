@@ -6,10 +6,13 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/pocketbase/dbx"
 	"github.com/pocketbase/pocketbase/core"
 
 	"github.com/certimate-go/certimate/internal/app"
 	"github.com/certimate-go/certimate/internal/domain"
+	"github.com/certimate-go/certimate/internal/encryption"
+	"github.com/certimate-go/certimate/internal/secrets"
 )
 
 type AccessRepository struct{}
@@ -31,7 +34,79 @@ func (r *AccessRepository) GetById(ctx context.Context, id string) (*domain.Acce
 		return nil, domain.ErrRecordNotFound
 	}
 
-	return r.castRecordToModel(record)
+	access, err := r.castRecordToModel(record)
+	if err != nil {
+		return nil, err
+	}
+
+	// 即时解析授权配置中引用的外部密钥
+	if access.Config, err = secrets.ResolveMap(ctx, access.Config); err != nil {
+		return nil, fmt.Errorf("failed to resolve secrets of access '%s': %w", access.Id, err)
+	}
+
+	return access, nil
+}
+
+func (r *AccessRepository) Save(ctx context.Context, access *domain.Access) (*domain.Access, error) {
+	collection, err := app.GetAppWithContext(ctx).FindCollectionByNameOrId(domain.CollectionNameAccess)
+	if err != nil {
+		return access, err
+	}
+
+	var record *core.Record
+	if access.Id == "" {
+		record = core.NewRecord(collection)
+	} else {
+		record, err = app.GetAppWithContext(ctx).FindRecordById(collection, access.Id)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return access, domain.ErrRecordNotFound
+			}
+			return access, err
+		}
+	}
+
+	record.Set("name", access.Name)
+	record.Set("provider", access.Provider)
+	record.Set("config", access.Config)
+	record.Set("reserve", access.Reserve)
+	record.Set("managedBy", access.ManagedBy)
+	if access.DeletedAt != nil {
+		record.Set("deleted", *access.DeletedAt)
+	} else {
+		record.Set("deleted", "")
+	}
+	if err := encryption.EncryptRecordField(record, "config"); err != nil {
+		return access, err
+	}
+	if err := app.GetAppWithContext(ctx).Save(record); err != nil {
+		return access, err
+	}
+
+	access.Id = record.Id
+	access.CreatedAt = record.GetDateTime("created").Time()
+	access.UpdatedAt = record.GetDateTime("updated").Time()
+	return access, nil
+}
+
+// 注意该方法不会解析授权配置中引用的外部密钥。
+func (r *AccessRepository) ListWithExprs(ctx context.Context, exprs ...dbx.Expression) ([]*domain.Access, error) {
+	records, err := app.GetAppWithContext(ctx).FindAllRecords(domain.CollectionNameAccess, exprs...)
+	if err != nil {
+		return nil, err
+	}
+
+	accesses := make([]*domain.Access, 0, len(records))
+	for _, record := range records {
+		access, err := r.castRecordToModel(record)
+		if err != nil {
+			return nil, err
+		}
+
+		accesses = append(accesses, access)
+	}
+
+	return accesses, nil
 }
 
 func (r *AccessRepository) castRecordToModel(record *core.Record) (*domain.Access, error) {
@@ -39,6 +114,10 @@ func (r *AccessRepository) castRecordToModel(record *core.Record) (*domain.Acces
 		return nil, fmt.Errorf("the record is nil")
 	}
 
+	if err := encryption.DecryptRecordField(record, "config"); err != nil {
+		return nil, err
+	}
+
 	config := make(map[string]any)
 	if err := record.UnmarshalJSONField("config", &config); err != nil {
 		return nil, fmt.Errorf("field 'config' is malformed")
@@ -50,10 +129,14 @@ func (r *AccessRepository) castRecordToModel(record *core.Record) (*domain.Acces
 			CreatedAt: record.GetDateTime("created").Time(),
 			UpdatedAt: record.GetDateTime("updated").Time(),
 		},
-		Name:     record.GetString("name"),
-		Provider: record.GetString("provider"),
-		Config:   config,
-		Reserve:  record.GetString("reserve"),
+		Name:      record.GetString("name"),
+		Provider:  record.GetString("provider"),
+		Config:    config,
+		Reserve:   record.GetString("reserve"),
+		ManagedBy: record.GetString("managedBy"),
+	}
+	if deletedAt := record.GetDateTime("deleted").Time(); !deletedAt.IsZero() {
+		access.DeletedAt = &deletedAt
 	}
 	return access, nil
 }
@@ -12,6 +12,7 @@ import (
 
 	"github.com/certimate-go/certimate/internal/app"
 	"github.com/certimate-go/certimate/internal/domain"
+	"github.com/certimate-go/certimate/internal/encryption"
 )
 
 type ACMEAccountRepository struct{}
@@ -77,6 +78,9 @@ func (r *ACMEAccountRepository) Save(ctx context.Context, acmeAccount *domain.AC
 	record.Set("acmeDirUrl", acmeAccount.ACMEDirectoryUrl)
 	record.Set("acmeAcctUrl", acmeAccount.ACMEAccountUrl)
 	record.Set("resourceObj", acmeAccount.ResourceObject)
+	if err := encryption.EncryptRecordField(record, "privateKey"); err != nil {
+		return acmeAccount, err
+	}
 	if err := app.GetApp().Save(record); err != nil {
 		return acmeAccount, err
 	}
@@ -92,6 +96,10 @@ func (r *ACMEAccountRepository) castRecordToModel(record *core.Record) (*domain.
 		return nil, fmt.Errorf("the record is nil")
 	}
 
+	if err := encryption.DecryptRecordField(record, "privateKey"); err != nil {
+		return nil, err
+	}
+
 	resourceObj := &acme.Account{}
 	if err := record.UnmarshalJSONField("resourceObj", resourceObj); err != nil {
 		return nil, fmt.Errorf("field 'resourceObj' is malformed")
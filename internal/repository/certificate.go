@@ -8,6 +8,7 @@ import (
 
 	"github.com/certimate-go/certimate/internal/app"
 	"github.com/certimate-go/certimate/internal/domain"
+	"github.com/certimate-go/certimate/internal/encryption"
 	"github.com/pocketbase/dbx"
 	"github.com/pocketbase/pocketbase/core"
 )
@@ -34,6 +35,25 @@ func (r *CertificateRepository) GetById(ctx context.Context, id string) (*domain
 	return r.castRecordToModel(record)
 }
 
+func (r *CertificateRepository) GetBySerialNumber(ctx context.Context, serialNumber string) (*domain.Certificate, error) {
+	records, err := app.GetApp().FindRecordsByFilter(
+		domain.CollectionNameCertificate,
+		"serialNumber={:serialNumber} && deleted=null",
+		"-created",
+		1, 0,
+		dbx.Params{"serialNumber": serialNumber},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(records) == 0 {
+		return nil, domain.ErrRecordNotFound
+	}
+
+	return r.castRecordToModel(records[0])
+}
+
 func (r *CertificateRepository) GetByWorkflowIdAndNodeId(ctx context.Context, workflowId string, workflowNodeId string) (*domain.Certificate, error) {
 	records, err := app.GetApp().FindRecordsByFilter(
 		domain.CollectionNameCertificate,
@@ -112,9 +132,15 @@ func (r *CertificateRepository) Save(ctx context.Context, certificate *domain.Ce
 	record.Set("acmeCertUrl", certificate.ACMECertificateUrl)
 	record.Set("isRenewed", certificate.IsRenewed)
 	record.Set("isRevoked", certificate.IsRevoked)
+	record.Set("expiryAlertedDays", certificate.ExpiryAlertedDays)
+	record.Set("predecessorRef", certificate.PredecessorId)
+	record.Set("replacedByRef", certificate.ReplacedById)
 	record.Set("workflowRef", certificate.WorkflowId)
 	record.Set("workflowRunRef", certificate.WorkflowRunId)
 	record.Set("workflowNodeId", certificate.WorkflowNodeId)
+	if err := encryption.EncryptRecordField(record, "privateKey"); err != nil {
+		return certificate, err
+	}
 	if err := app.GetApp().Save(record); err != nil {
 		return certificate, err
 	}
@@ -125,6 +151,57 @@ func (r *CertificateRepository) Save(ctx context.Context, certificate *domain.Ce
 	return certificate, nil
 }
 
+func (r *CertificateRepository) ListWithExprs(ctx context.Context, exprs ...dbx.Expression) ([]*domain.Certificate, error) {
+	records, err := app.GetApp().FindAllRecords(domain.CollectionNameCertificate, exprs...)
+	if err != nil {
+		return nil, err
+	}
+
+	certificates := make([]*domain.Certificate, 0, len(records))
+	for _, record := range records {
+		certificate, err := r.castRecordToModel(record)
+		if err != nil {
+			return nil, err
+		}
+
+		certificates = append(certificates, certificate)
+	}
+
+	return certificates, nil
+}
+
+// 与 ListWithExprs 相同，但不会解密私钥，返回的证书实体中私钥为空。
+// 适用于只需要证书元数据的场景，不会因个别记录的私钥无法解密而失败。
+func (r *CertificateRepository) ListWithExprsWithoutPrivateKey(ctx context.Context, exprs ...dbx.Expression) ([]*domain.Certificate, error) {
+	records, err := app.GetApp().FindAllRecords(domain.CollectionNameCertificate, exprs...)
+	if err != nil {
+		return nil, err
+	}
+
+	certificates := make([]*domain.Certificate, 0, len(records))
+	for _, record := range records {
+		record.Set("privateKey", "")
+		certificate, err := r.castRecordToModel(record)
+		if err != nil {
+			return nil, err
+		}
+
+		certificates = append(certificates, certificate)
+	}
+
+	return certificates, nil
+}
+
+func (r *CertificateRepository) UpdateExpiryAlertedDays(ctx context.Context, id string, days int32) error {
+	_, err := app.GetApp().DB().
+		Update(domain.CollectionNameCertificate,
+			dbx.Params{"expiryAlertedDays": days},
+			dbx.HashExp{"id": id},
+		).
+		Execute()
+	return err
+}
+
 func (r *CertificateRepository) DeleteWithExprs(ctx context.Context, exprs ...dbx.Expression) (int, error) {
 	records, err := app.GetApp().FindAllRecords(domain.CollectionNameCertificate, exprs...)
 	if err != nil {
@@ -153,6 +230,10 @@ func (r *CertificateRepository) castRecordToModel(record *core.Record) (*domain.
 		return nil, fmt.Errorf("the record is nil")
 	}
 
+	if err := encryption.DecryptRecordField(record, "privateKey"); err != nil {
+		return nil, err
+	}
+
 	certificate := &domain.Certificate{
 		Meta: domain.Meta{
 			Id:        record.Id,
@@ -178,6 +259,9 @@ func (r *CertificateRepository) castRecordToModel(record *core.Record) (*domain.
 		ACMECertificateUrl: record.GetString("acmeCertUrl"),
 		IsRenewed:          record.GetBool("isRenewed"),
 		IsRevoked:          record.GetBool("isRevoked"),
+		ExpiryAlertedDays:  int32(record.GetInt("expiryAlertedDays")),
+		PredecessorId:      record.GetString("predecessorRef"),
+		ReplacedById:       record.GetString("replacedByRef"),
 		WorkflowId:         record.GetString("workflowRef"),
 		WorkflowRunId:      record.GetString("workflowRunRef"),
 		WorkflowNodeId:     record.GetString("workflowNodeId"),
@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/certimate-go/certimate/internal/app"
+	"github.com/certimate-go/certimate/internal/domain"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+type CertificateDeploymentRepository struct{}
+
+func NewCertificateDeploymentRepository() *CertificateDeploymentRepository {
+	return &CertificateDeploymentRepository{}
+}
+
+func (r *CertificateDeploymentRepository) ListWithExprs(ctx context.Context, exprs ...dbx.Expression) ([]*domain.CertificateDeployment, error) {
+	records, err := app.GetApp().FindAllRecords(domain.CollectionNameCertificateDeployment, exprs...)
+	if err != nil {
+		return nil, err
+	}
+
+	certificateDeployments := make([]*domain.CertificateDeployment, 0, len(records))
+	for _, record := range records {
+		certificateDeployment, err := r.castRecordToModel(record)
+		if err != nil {
+			return nil, err
+		}
+
+		certificateDeployments = append(certificateDeployments, certificateDeployment)
+	}
+
+	return certificateDeployments, nil
+}
+
+// 获取部署目标当前持有的证书的部署记录。
+func (r *CertificateDeploymentRepository) GetCurrentByTargetKey(ctx context.Context, targetKey string) (*domain.CertificateDeployment, error) {
+	records, err := app.GetApp().FindRecordsByFilter(
+		domain.CollectionNameCertificateDeployment,
+		"targetKey={:targetKey} && isCurrent=true",
+		"-created",
+		1, 0,
+		dbx.Params{"targetKey": targetKey},
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrRecordNotFound
+		}
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, domain.ErrRecordNotFound
+	}
+
+	return r.castRecordToModel(records[0])
+}
+
+// 保存部署记录。
+// 如果记录被标记为当前持有，则同一部署目标下的其他记录将被标记为非当前持有。
+func (r *CertificateDeploymentRepository) Save(ctx context.Context, certificateDeployment *domain.CertificateDeployment) (*domain.CertificateDeployment, error) {
+	collection, err := app.GetApp().FindCollectionByNameOrId(domain.CollectionNameCertificateDeployment)
+	if err != nil {
+		return certificateDeployment, err
+	}
+
+	var record *core.Record
+	if certificateDeployment.Id == "" {
+		record = core.NewRecord(collection)
+	} else {
+		record, err = app.GetApp().FindRecordById(collection, certificateDeployment.Id)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return certificateDeployment, domain.ErrRecordNotFound
+			}
+			return certificateDeployment, err
+		}
+	}
+
+	err = app.GetApp().RunInTransaction(func(txApp core.App) error {
+		record.Set("certificateRef", certificateDeployment.CertificateId)
+		record.Set("serialNumber", certificateDeployment.SerialNumber)
+		record.Set("provider", certificateDeployment.Provider)
+		record.Set("providerAccessRef", certificateDeployment.ProviderAccessId)
+		record.Set("providerConfig", certificateDeployment.ProviderConfig)
+		record.Set("targetKey", certificateDeployment.TargetKey)
+		record.Set("isCurrent", certificateDeployment.IsCurrent)
+		record.Set("workflowRef", certificateDeployment.WorkflowId)
+		record.Set("workflowRunRef", certificateDeployment.WorkflowRunId)
+		record.Set("workflowNodeId", certificateDeployment.WorkflowNodeId)
+		if err := txApp.Save(record); err != nil {
+			return err
+		}
+
+		if certificateDeployment.IsCurrent {
+			_, err := txApp.DB().
+				Update(
+					domain.CollectionNameCertificateDeployment,
+					dbx.Params{"isCurrent": false},
+					dbx.And(
+						dbx.HashExp{"targetKey": certificateDeployment.TargetKey, "isCurrent": true},
+						dbx.Not(dbx.HashExp{"id": record.Id}),
+					),
+				).
+				Execute()
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return certificateDeployment, err
+	}
+
+	certificateDeployment.Id = record.Id
+	certificateDeployment.CreatedAt = record.GetDateTime("created").Time()
+	certificateDeployment.UpdatedAt = record.GetDateTime("updated").Time()
+	return certificateDeployment, nil
+}
+
+func (r *CertificateDeploymentRepository) castRecordToModel(record *core.Record) (*domain.CertificateDeployment, error) {
+	if record == nil {
+		return nil, fmt.Errorf("the record is nil")
+	}
+
+	providerConfig := make(map[string]any)
+	if err := record.UnmarshalJSONField("providerConfig", &providerConfig); err != nil {
+		return nil, fmt.Errorf("field 'providerConfig' is malformed")
+	}
+
+	certificateDeployment := &domain.CertificateDeployment{
+		Meta: domain.Meta{
+			Id:        record.Id,
+			CreatedAt: record.GetDateTime("created").Time(),
+			UpdatedAt: record.GetDateTime("updated").Time(),
+		},
+		CertificateId:    record.GetString("certificateRef"),
+		SerialNumber:     record.GetString("serialNumber"),
+		Provider:         record.GetString("provider"),
+		ProviderAccessId: record.GetString("providerAccessRef"),
+		ProviderConfig:   providerConfig,
+		TargetKey:        record.GetString("targetKey"),
+		IsCurrent:        record.GetBool("isCurrent"),
+		WorkflowId:       record.GetString("workflowRef"),
+		WorkflowRunId:    record.GetString("workflowRunRef"),
+		WorkflowNodeId:   record.GetString("workflowNodeId"),
+	}
+	return certificateDeployment, nil
+}
@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/certimate-go/certimate/internal/app"
+	"github.com/certimate-go/certimate/internal/domain"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+type DiscoveredCertificateRepository struct{}
+
+func NewDiscoveredCertificateRepository() *DiscoveredCertificateRepository {
+	return &DiscoveredCertificateRepository{}
+}
+
+func (r *DiscoveredCertificateRepository) GetById(ctx context.Context, id string) (*domain.DiscoveredCertificate, error) {
+	record, err := app.GetApp().FindRecordById(domain.CollectionNameDiscoveredCertificate, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrRecordNotFound
+		}
+		return nil, err
+	}
+
+	return r.castRecordToModel(record)
+}
+
+func (r *DiscoveredCertificateRepository) GetByEndpointAndSerialNumber(ctx context.Context, host string, port int32, serverName string, serialNumber string) (*domain.DiscoveredCertificate, error) {
+	record, err := app.GetApp().FindFirstRecordByFilter(
+		domain.CollectionNameDiscoveredCertificate,
+		"host={:host} && port={:port} && serverName={:serverName} && serialNumber={:serialNumber}",
+		dbx.Params{"host": host},
+		dbx.Params{"port": port},
+		dbx.Params{"serverName": serverName},
+		dbx.Params{"serialNumber": serialNumber},
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrRecordNotFound
+		}
+		return nil, err
+	}
+
+	return r.castRecordToModel(record)
+}
+
+func (r *DiscoveredCertificateRepository) Save(ctx context.Context, discoveredCertificate *domain.DiscoveredCertificate) (*domain.DiscoveredCertificate, error) {
+	collection, err := app.GetApp().FindCollectionByNameOrId(domain.CollectionNameDiscoveredCertificate)
+	if err != nil {
+		return discoveredCertificate, err
+	}
+
+	var record *core.Record
+	if discoveredCertificate.Id == "" {
+		record = core.NewRecord(collection)
+	} else {
+		record, err = app.GetApp().FindRecordById(collection, discoveredCertificate.Id)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return discoveredCertificate, domain.ErrRecordNotFound
+			}
+			return discoveredCertificate, err
+		}
+	}
+
+	record.Set("host", discoveredCertificate.Host)
+	record.Set("address", discoveredCertificate.Address)
+	record.Set("port", discoveredCertificate.Port)
+	record.Set("protocol", discoveredCertificate.Protocol.String())
+	record.Set("serverName", discoveredCertificate.ServerName)
+	record.Set("serialNumber", discoveredCertificate.SerialNumber)
+	record.Set("subjectName", discoveredCertificate.SubjectName)
+	record.Set("subjectAltNames", discoveredCertificate.SubjectAltNames)
+	record.Set("issuerName", discoveredCertificate.IssuerName)
+	record.Set("issuerOrg", discoveredCertificate.IssuerOrg)
+	record.Set("keyAlgorithm", discoveredCertificate.KeyAlgorithm.String())
+	record.Set("validityNotBefore", discoveredCertificate.ValidityNotBefore)
+	record.Set("validityNotAfter", discoveredCertificate.ValidityNotAfter)
+	record.Set("certificate", discoveredCertificate.Certificate)
+	record.Set("chainLength", discoveredCertificate.ChainLength)
+	record.Set("certificateRef", discoveredCertificate.CertificateId)
+	record.Set("firstSeenAt", discoveredCertificate.FirstSeenAt)
+	record.Set("lastSeenAt", discoveredCertificate.LastSeenAt)
+	if err := app.GetApp().Save(record); err != nil {
+		return discoveredCertificate, err
+	}
+
+	discoveredCertificate.Id = record.Id
+	discoveredCertificate.CreatedAt = record.GetDateTime("created").Time()
+	discoveredCertificate.UpdatedAt = record.GetDateTime("updated").Time()
+	return discoveredCertificate, nil
+}
+
+func (r *DiscoveredCertificateRepository) castRecordToModel(record *core.Record) (*domain.DiscoveredCertificate, error) {
+	if record == nil {
+		return nil, fmt.Errorf("the record is nil")
+	}
+
+	discoveredCertificate := &domain.DiscoveredCertificate{
+		Meta: domain.Meta{
+			Id:        record.Id,
+			CreatedAt: record.GetDateTime("created").Time(),
+			UpdatedAt: record.GetDateTime("updated").Time(),
+		},
+		Host:              record.GetString("host"),
+		Address:           record.GetString("address"),
+		Port:              int32(record.GetInt("port")),
+		Protocol:          domain.DiscoveryProtocolType(record.GetString("protocol")),
+		ServerName:        record.GetString("serverName"),
+		SerialNumber:      record.GetString("serialNumber"),
+		SubjectName:       record.GetString("subjectName"),
+		SubjectAltNames:   record.GetString("subjectAltNames"),
+		IssuerName:        record.GetString("issuerName"),
+		IssuerOrg:         record.GetString("issuerOrg"),
+		KeyAlgorithm:      domain.CertificateKeyAlgorithmType(record.GetString("keyAlgorithm")),
+		ValidityNotBefore: record.GetDateTime("validityNotBefore").Time(),
+		ValidityNotAfter:  record.GetDateTime("validityNotAfter").Time(),
+		Certificate:       record.GetString("certificate"),
+		ChainLength:       int32(record.GetInt("chainLength")),
+		CertificateId:     record.GetString("certificateRef"),
+		FirstSeenAt:       record.GetDateTime("firstSeenAt").Time(),
+		LastSeenAt:        record.GetDateTime("lastSeenAt").Time(),
+	}
+	return discoveredCertificate, nil
+}
@@ -43,6 +43,25 @@ func (r *WorkflowRepository) ListEnabledScheduled(ctx context.Context) ([]*domai
 	return workflows, nil
 }
 
+func (r *WorkflowRepository) ListWithExprs(ctx context.Context, exprs ...dbx.Expression) ([]*domain.Workflow, error) {
+	records, err := app.GetAppWithContext(ctx).FindAllRecords(domain.CollectionNameWorkflow, exprs...)
+	if err != nil {
+		return nil, err
+	}
+
+	workflows := make([]*domain.Workflow, 0, len(records))
+	for _, record := range records {
+		workflow, err := r.castRecordToModel(record)
+		if err != nil {
+			return nil, err
+		}
+
+		workflows = append(workflows, workflow)
+	}
+
+	return workflows, nil
+}
+
 func (r *WorkflowRepository) GetById(ctx context.Context, id string) (*domain.Workflow, error) {
 	record, err := app.GetApp().FindRecordById(domain.CollectionNameWorkflow, id)
 	if err != nil {
@@ -56,7 +75,7 @@ func (r *WorkflowRepository) GetById(ctx context.Context, id string) (*domain.Wo
 }
 
 func (r *WorkflowRepository) Save(ctx context.Context, workflow *domain.Workflow) (*domain.Workflow, error) {
-	collection, err := app.GetApp().FindCollectionByNameOrId(domain.CollectionNameWorkflow)
+	collection, err := app.GetAppWithContext(ctx).FindCollectionByNameOrId(domain.CollectionNameWorkflow)
 	if err != nil {
 		return workflow, err
 	}
@@ -65,7 +84,7 @@ func (r *WorkflowRepository) Save(ctx context.Context, workflow *domain.Workflow
 	if workflow.Id == "" {
 		record = core.NewRecord(collection)
 	} else {
-		record, err = app.GetApp().FindRecordById(collection, workflow.Id)
+		record, err = app.GetAppWithContext(ctx).FindRecordById(collection, workflow.Id)
 		if err != nil {
 			if errors.Is(err, sql.ErrNoRows) {
 				return workflow, domain.ErrRecordNotFound
@@ -78,6 +97,7 @@ func (r *WorkflowRepository) Save(ctx context.Context, workflow *domain.Workflow
 	record.Set("description", workflow.Description)
 	record.Set("trigger", workflow.Trigger.String())
 	record.Set("triggerCron", workflow.TriggerCron)
+	record.Set("triggerWebhookSecret", workflow.TriggerWebhookSecret)
 	record.Set("enabled", workflow.Enabled)
 	record.Set("graphDraft", workflow.GraphDraft)
 	record.Set("graphContent", workflow.GraphContent)
@@ -86,7 +106,10 @@ func (r *WorkflowRepository) Save(ctx context.Context, workflow *domain.Workflow
 	record.Set("lastRunRef", workflow.LastRunId)
 	record.Set("lastRunStatus", workflow.LastRunStatus.String())
 	record.Set("lastRunTime", workflow.LastRunTime)
-	if err := app.GetApp().Save(record); err != nil {
+	record.Set("managedBy", workflow.ManagedBy)
+	record.Set("concurrencyPolicy", workflow.ConcurrencyPolicy.String())
+	record.Set("timeout", workflow.Timeout)
+	if err := app.GetAppWithContext(ctx).Save(record); err != nil {
 		return workflow, err
 	}
 
@@ -96,6 +119,18 @@ func (r *WorkflowRepository) Save(ctx context.Context, workflow *domain.Workflow
 	return workflow, nil
 }
 
+func (r *WorkflowRepository) Delete(ctx context.Context, workflow *domain.Workflow) error {
+	record, err := app.GetAppWithContext(ctx).FindRecordById(domain.CollectionNameWorkflow, workflow.Id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.ErrRecordNotFound
+		}
+		return err
+	}
+
+	return app.GetAppWithContext(ctx).Delete(record)
+}
+
 func (r *WorkflowRepository) castRecordToModel(record *core.Record) (*domain.Workflow, error) {
 	if record == nil {
 		return nil, fmt.Errorf("the record is nil")
@@ -117,18 +152,22 @@ func (r *WorkflowRepository) castRecordToModel(record *core.Record) (*domain.Wor
 			CreatedAt: record.GetDateTime("created").Time(),
 			UpdatedAt: record.GetDateTime("updated").Time(),
 		},
-		Name:          record.GetString("name"),
-		Description:   record.GetString("description"),
-		Trigger:       domain.WorkflowTriggerType(record.GetString("trigger")),
-		TriggerCron:   record.GetString("triggerCron"),
-		Enabled:       record.GetBool("enabled"),
-		GraphDraft:    graphDraft,
-		GraphContent:  graphContent,
-		HasDraft:      record.GetBool("hasDraft"),
-		HasContent:    record.GetBool("hasContent"),
-		LastRunId:     record.GetString("lastRunRef"),
-		LastRunStatus: domain.WorkflowRunStatusType(record.GetString("lastRunStatus")),
-		LastRunTime:   record.GetDateTime("lastRunTime").Time(),
+		Name:                 record.GetString("name"),
+		Description:          record.GetString("description"),
+		Trigger:              domain.WorkflowTriggerType(record.GetString("trigger")),
+		TriggerCron:          record.GetString("triggerCron"),
+		TriggerWebhookSecret: record.GetString("triggerWebhookSecret"),
+		Enabled:              record.GetBool("enabled"),
+		GraphDraft:           graphDraft,
+		GraphContent:         graphContent,
+		HasDraft:             record.GetBool("hasDraft"),
+		HasContent:           record.GetBool("hasContent"),
+		LastRunId:            record.GetString("lastRunRef"),
+		LastRunStatus:        domain.WorkflowRunStatusType(record.GetString("lastRunStatus")),
+		LastRunTime:          record.GetDateTime("lastRunTime").Time(),
+		ManagedBy:            record.GetString("managedBy"),
+		ConcurrencyPolicy:    domain.WorkflowConcurrencyPolicyType(record.GetString("concurrencyPolicy")),
+		Timeout:              record.GetInt("timeout"),
 	}
 	return workflow, nil
 }
@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
 
@@ -10,6 +11,7 @@ import (
 	"github.com/certimate-go/certimate/internal/domain"
 	"github.com/pocketbase/dbx"
 	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/types"
 )
 
 type WorkflowRunRepository struct{}
@@ -18,6 +20,25 @@ func NewWorkflowRunRepository() *WorkflowRunRepository {
 	return &WorkflowRunRepository{}
 }
 
+func (r *WorkflowRunRepository) ListWithExprs(ctx context.Context, exprs ...dbx.Expression) ([]*domain.WorkflowRun, error) {
+	records, err := app.GetApp().FindAllRecords(domain.CollectionNameWorkflowRun, exprs...)
+	if err != nil {
+		return nil, err
+	}
+
+	workflowRuns := make([]*domain.WorkflowRun, 0, len(records))
+	for _, record := range records {
+		workflowRun, err := r.castRecordToModel(record)
+		if err != nil {
+			return nil, err
+		}
+
+		workflowRuns = append(workflowRuns, workflowRun)
+	}
+
+	return workflowRuns, nil
+}
+
 func (r *WorkflowRunRepository) GetById(ctx context.Context, id string) (*domain.WorkflowRun, error) {
 	record, err := app.GetApp().FindRecordById(domain.CollectionNameWorkflowRun, id)
 	if err != nil {
@@ -51,11 +72,15 @@ func (r *WorkflowRunRepository) Save(ctx context.Context, workflowRun *domain.Wo
 
 	record.Set("workflowRef", workflowRun.WorkflowId)
 	record.Set("trigger", workflowRun.Trigger.String())
+	record.Set("payload", workflowRun.Payload)
+	record.Set("dryRun", workflowRun.DryRun)
 	record.Set("status", workflowRun.Status.String())
 	record.Set("startedAt", workflowRun.StartedAt)
 	record.Set("endedAt", workflowRun.EndedAt)
 	record.Set("graph", workflowRun.Graph)
 	record.Set("error", workflowRun.Error)
+	record.Set("checkpoint", workflowRun.Checkpoint)
+	record.Set("reason", workflowRun.Reason)
 	err = app.GetApp().Save(record)
 	if err != nil {
 		return workflowRun, err
@@ -89,11 +114,15 @@ func (r *WorkflowRunRepository) SaveWithCascading(ctx context.Context, workflowR
 	err = app.GetApp().RunInTransaction(func(txApp core.App) error {
 		record.Set("workflowRef", workflowRun.WorkflowId)
 		record.Set("trigger", workflowRun.Trigger.String())
+		record.Set("payload", workflowRun.Payload)
+		record.Set("dryRun", workflowRun.DryRun)
 		record.Set("status", workflowRun.Status.String())
 		record.Set("startedAt", workflowRun.StartedAt)
 		record.Set("endedAt", workflowRun.EndedAt)
 		record.Set("graph", workflowRun.Graph)
 		record.Set("error", workflowRun.Error)
+		record.Set("checkpoint", workflowRun.Checkpoint)
+		record.Set("reason", workflowRun.Reason)
 		err = txApp.Save(record)
 		if err != nil {
 			return err
@@ -157,15 +186,81 @@ func (r *WorkflowRunRepository) DeleteWithExprs(ctx context.Context, exprs ...db
 	return ret, nil
 }
 
+func (r *WorkflowRunRepository) SaveCheckpoint(ctx context.Context, workflowRunId string, checkpoint *domain.WorkflowRunCheckpoint) error {
+	record, err := app.GetApp().FindRecordById(domain.CollectionNameWorkflowRun, workflowRunId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.ErrRecordNotFound
+		}
+		return err
+	}
+
+	// 仅更新执行断点，以免覆盖其他协程并发写入的运行状态
+	record.Set("checkpoint", checkpoint)
+	return app.GetApp().Save(record)
+}
+
+// 仅当运行状态仍为 expectedStatus 时，才更新其状态及执行断点，并级联更新所属工作流的最后运行状态。
+// 返回值表示是否已更新，以此避免并发请求（如同时审批与超时）重复处理同一运行。
+func (r *WorkflowRunRepository) UpdateStatusIfMatched(ctx context.Context, workflowRun *domain.WorkflowRun, expectedStatus domain.WorkflowRunStatusType) (bool, error) {
+	checkpoint, err := json.Marshal(workflowRun.Checkpoint)
+	if err != nil {
+		return false, err
+	}
+
+	var matched bool
+	err = app.GetApp().RunInTransaction(func(txApp core.App) error {
+		res, err := txApp.DB().
+			Update(domain.CollectionNameWorkflowRun,
+				dbx.Params{
+					"status":     workflowRun.Status.String(),
+					"checkpoint": string(checkpoint),
+					"updated":    types.NowDateTime().String(),
+				},
+				dbx.HashExp{
+					"id":     workflowRun.Id,
+					"status": expectedStatus.String(),
+				},
+			).
+			Execute()
+		if err != nil {
+			return err
+		}
+
+		if rows, err := res.RowsAffected(); err != nil {
+			return err
+		} else if rows == 0 {
+			return nil
+		}
+
+		_, err = txApp.DB().
+			Update(domain.CollectionNameWorkflow,
+				dbx.Params{"lastRunStatus": workflowRun.Status.String()},
+				dbx.HashExp{"id": workflowRun.WorkflowId, "lastRunRef": workflowRun.Id},
+			).
+			Execute()
+		if err != nil {
+			return err
+		}
+
+		matched = true
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return matched, nil
+}
+
 func (r *WorkflowRunRepository) ResetStatusIfHanging(ctx context.Context) error {
 	return app.GetApp().RunInTransaction(func(txApp core.App) error {
 		var err error
 
 		_, err = txApp.DB().
-			NewQuery(fmt.Sprintf("UPDATE %s SET lastRunStatus = '%s' WHERE lastRunStatus = '%s' OR lastRunStatus = '%s'",
+			NewQuery(fmt.Sprintf("UPDATE %s SET lastRunStatus = '%s' WHERE lastRunStatus = '%s'",
 				domain.CollectionNameWorkflow,
 				domain.WorkflowRunStatusTypeCanceled.String(),
-				domain.WorkflowRunStatusTypePending.String(),
 				domain.WorkflowRunStatusTypeProcessing.String(),
 			)).
 			Execute()
@@ -174,10 +269,9 @@ func (r *WorkflowRunRepository) ResetStatusIfHanging(ctx context.Context) error
 		}
 
 		_, err = txApp.DB().
-			NewQuery(fmt.Sprintf("UPDATE %s SET lastRunStatus = '%s' WHERE lastRunStatus = '%s' OR lastRunStatus = '%s'",
-				domain.CollectionNameWorkflow,
+			NewQuery(fmt.Sprintf("UPDATE %s SET status = '%s' WHERE status = '%s'",
+				domain.CollectionNameWorkflowRun,
 				domain.WorkflowRunStatusTypeCanceled.String(),
-				domain.WorkflowRunStatusTypePending.String(),
 				domain.WorkflowRunStatusTypeProcessing.String(),
 			)).
 			Execute()
@@ -199,6 +293,16 @@ func (r *WorkflowRunRepository) castRecordToModel(record *core.Record) (*domain.
 		return nil, fmt.Errorf("field 'graph' is malformed")
 	}
 
+	payload := make(map[string]any)
+	if err := record.UnmarshalJSONField("payload", &payload); err != nil {
+		return nil, fmt.Errorf("field 'payload' is malformed")
+	}
+
+	var checkpoint *domain.WorkflowRunCheckpoint
+	if err := record.UnmarshalJSONField("checkpoint", &checkpoint); err != nil {
+		return nil, fmt.Errorf("field 'checkpoint' is malformed")
+	}
+
 	workflowRun := &domain.WorkflowRun{
 		Meta: domain.Meta{
 			Id:        record.Id,
@@ -208,10 +312,14 @@ func (r *WorkflowRunRepository) castRecordToModel(record *core.Record) (*domain.
 		WorkflowId: record.GetString("workflowRef"),
 		Status:     domain.WorkflowRunStatusType(record.GetString("status")),
 		Trigger:    domain.WorkflowTriggerType(record.GetString("trigger")),
+		Payload:    payload,
+		DryRun:     record.GetBool("dryRun"),
 		StartedAt:  record.GetDateTime("startedAt").Time(),
 		EndedAt:    record.GetDateTime("endedAt").Time(),
 		Graph:      graph,
 		Error:      record.GetString("error"),
+		Checkpoint: checkpoint,
+		Reason:     record.GetString("reason"),
 	}
 	return workflowRun, nil
 }
@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/router"
+
+	"github.com/certimate-go/certimate/internal/domain/dtos"
+	"github.com/certimate-go/certimate/internal/rest/resp"
+)
+
+type accessService interface {
+	TestAccess(ctx context.Context, req *dtos.AccessTestReq) (*dtos.AccessTestResp, error)
+}
+
+type AccessesHandler struct {
+	service accessService
+}
+
+func NewAccessesHandler(router *router.RouterGroup[*core.RequestEvent], service accessService) {
+	handler := &AccessesHandler{
+		service: service,
+	}
+
+	group := router.Group("/accesses")
+	group.POST("/{accessId}/test", handler.test)
+}
+
+func (handler *AccessesHandler) test(e *core.RequestEvent) error {
+	req := &dtos.AccessTestReq{}
+	req.AccessId = e.Request.PathValue("accessId")
+
+	res, err := handler.service.TestAccess(e.Request.Context(), req)
+	if err != nil {
+		return resp.Err(e, err)
+	}
+
+	return resp.Ok(e, res)
+}
@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"html/template"
+	"net/http"
+	"strings"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/router"
+
+	"github.com/certimate-go/certimate/internal/domain/dtos"
+	"github.com/certimate-go/certimate/internal/rest/resp"
+)
+
+// 审批通知中的链接会在浏览器中以 GET 方式打开，因此先展示确认页面，由页面中的表单以 POST 方式提交审批结果。
+// 这同时也避免了邮件客户端等对链接的预取导致误操作。
+var approvalPageTmpl = template.Must(template.New("approval").Parse(`<!DOCTYPE html>
+<html>
+<head>
+  <meta charset="utf-8">
+  <meta name="viewport" content="width=device-width, initial-scale=1">
+  <title>Certimate</title>
+</head>
+<body style="font-family: sans-serif; max-width: 480px; margin: 48px auto; padding: 0 16px;">
+  {{ if .Message }}
+  <p>{{ .Message }}</p>
+  {{ else }}
+  <h3>{{ if .Approved }}Approve{{ else }}Reject{{ end }} the workflow run?</h3>
+  <form method="POST">
+    <p><textarea name="comment" rows="4" style="width: 100%;" placeholder="Comment (optional)"></textarea></p>
+    <p><button type="submit">{{ if .Approved }}Approve{{ else }}Reject{{ end }}</button></p>
+  </form>
+  {{ end }}
+</body>
+</html>`))
+
+type approvalService interface {
+	ApproveRun(ctx context.Context, req *dtos.WorkflowApproveRunReq) (*dtos.WorkflowApproveRunResp, error)
+}
+
+type ApprovalsHandler struct {
+	service approvalService
+}
+
+func NewApprovalsHandler(router *router.RouterGroup[*core.RequestEvent], service approvalService) {
+	handler := &ApprovalsHandler{
+		service: service,
+	}
+
+	group := router.Group("/workflows")
+	group.GET("/{workflowId}/runs/{runId}/approve", handler.confirmApproveRun)
+	group.GET("/{workflowId}/runs/{runId}/reject", handler.confirmRejectRun)
+	group.POST("/{workflowId}/runs/{runId}/approve", handler.approveRun)
+	group.POST("/{workflowId}/runs/{runId}/reject", handler.rejectRun)
+}
+
+func (handler *ApprovalsHandler) confirmApproveRun(e *core.RequestEvent) error {
+	return handler.renderPage(e, true, "")
+}
+
+func (handler *ApprovalsHandler) confirmRejectRun(e *core.RequestEvent) error {
+	return handler.renderPage(e, false, "")
+}
+
+func (handler *ApprovalsHandler) approveRun(e *core.RequestEvent) error {
+	return handler.decideRun(e, true)
+}
+
+func (handler *ApprovalsHandler) rejectRun(e *core.RequestEvent) error {
+	return handler.decideRun(e, false)
+}
+
+func (handler *ApprovalsHandler) decideRun(e *core.RequestEvent, approved bool) error {
+	// 来自确认页面的表单提交，需以页面的形式返回结果
+	fromPage := strings.HasPrefix(e.Request.Header.Get("Content-Type"), "application/x-www-form-urlencoded")
+
+	req := &dtos.WorkflowApproveRunReq{}
+	if fromPage {
+		req.Comment = e.Request.PostFormValue("comment")
+	} else if e.Request.ContentLength > 0 {
+		if err := e.BindBody(req); err != nil {
+			return resp.Err(e, err)
+		}
+	}
+
+	req.WorkflowId = e.Request.PathValue("workflowId")
+	req.RunId = e.Request.PathValue("runId")
+	req.Token = e.Request.URL.Query().Get("token")
+	req.Approved = approved
+	req.Authorized = e.HasSuperuserAuth()
+
+	res, err := handler.service.ApproveRun(e.Request.Context(), req)
+	if err != nil {
+		if fromPage {
+			return handler.renderPage(e, approved, err.Error())
+		}
+		return resp.Err(e, err)
+	}
+
+	if fromPage {
+		if approved {
+			return handler.renderPage(e, approved, "The workflow run has been approved.")
+		}
+		return handler.renderPage(e, approved, "The workflow run has been rejected.")
+	}
+	return resp.Ok(e, res)
+}
+
+func (handler *ApprovalsHandler) renderPage(e *core.RequestEvent, approved bool, message string) error {
+	var buf bytes.Buffer
+	if err := approvalPageTmpl.Execute(&buf, map[string]any{"Approved": approved, "Message": message}); err != nil {
+		return resp.Err(e, err)
+	}
+
+	return e.HTML(http.StatusOK, buf.String())
+}
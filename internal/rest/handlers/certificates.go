@@ -13,6 +13,8 @@ import (
 type certificateService interface {
 	DownloadCertificate(ctx context.Context, req *dtos.CertificateDownloadReq) (*dtos.CertificateDownloadResp, error)
 	RevokeCertificate(ctx context.Context, req *dtos.CertificateRevokeReq) (*dtos.CertificateRevokeResp, error)
+	GetCertificateLineage(ctx context.Context, req *dtos.CertificateGetLineageReq) (*dtos.CertificateGetLineageResp, error)
+	ListDeployments(ctx context.Context, req *dtos.CertificateListDeploymentsReq) (*dtos.CertificateListDeploymentsResp, error)
 }
 
 type CertificatesHandler struct {
@@ -25,6 +27,9 @@ func NewCertificatesHandler(router *router.RouterGroup[*core.RequestEvent], serv
 	}
 
 	group := router.Group("/certificates")
+	group.GET("/lineage", handler.getLineage)
+	group.GET("/{certificateId}/lineage", handler.getLineage)
+	group.GET("/{certificateId}/deployments", handler.listDeployments)
 	group.POST("/{certificateId}/download", handler.downloadCertificate)
 	group.POST("/{certificateId}/revoke", handler.revokeCertificate)
 
@@ -60,3 +65,29 @@ func (handler *CertificatesHandler) revokeCertificate(e *core.RequestEvent) erro
 
 	return resp.Ok(e, res)
 }
+
+func (handler *CertificatesHandler) getLineage(e *core.RequestEvent) error {
+	req := &dtos.CertificateGetLineageReq{}
+	req.CertificateId = e.Request.PathValue("certificateId")
+	req.Domain = e.Request.URL.Query().Get("domain")
+
+	res, err := handler.service.GetCertificateLineage(e.Request.Context(), req)
+	if err != nil {
+		return resp.Err(e, err)
+	}
+
+	return resp.Ok(e, res)
+}
+
+func (handler *CertificatesHandler) listDeployments(e *core.RequestEvent) error {
+	req := &dtos.CertificateListDeploymentsReq{}
+	req.CertificateId = e.Request.PathValue("certificateId")
+	req.OnlyCurrent = e.Request.URL.Query().Get("current") == "true"
+
+	res, err := handler.service.ListDeployments(e.Request.Context(), req)
+	if err != nil {
+		return resp.Err(e, err)
+	}
+
+	return resp.Ok(e, res)
+}
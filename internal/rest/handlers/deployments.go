@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/router"
+
+	"github.com/certimate-go/certimate/internal/domain/dtos"
+	"github.com/certimate-go/certimate/internal/rest/resp"
+)
+
+type deploymentService interface {
+	ListDeployments(ctx context.Context, req *dtos.CertificateListDeploymentsReq) (*dtos.CertificateListDeploymentsResp, error)
+}
+
+type DeploymentsHandler struct {
+	service deploymentService
+}
+
+func NewDeploymentsHandler(router *router.RouterGroup[*core.RequestEvent], service deploymentService) {
+	handler := &DeploymentsHandler{
+		service: service,
+	}
+
+	router.GET("/deployments", handler.list)
+}
+
+func (handler *DeploymentsHandler) list(e *core.RequestEvent) error {
+	query := e.Request.URL.Query()
+
+	req := &dtos.CertificateListDeploymentsReq{}
+	req.CertificateId = query.Get("certificateId")
+	req.SerialNumber = query.Get("serialNumber")
+	req.Provider = query.Get("provider")
+	req.ProviderAccessId = query.Get("accessId")
+	req.WorkflowId = query.Get("workflowId")
+	req.OnlyCurrent = query.Get("current") == "true"
+
+	res, err := handler.service.ListDeployments(e.Request.Context(), req)
+	if err != nil {
+		return resp.Err(e, err)
+	}
+
+	return resp.Ok(e, res)
+}
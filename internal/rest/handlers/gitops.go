@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/router"
+
+	"github.com/certimate-go/certimate/internal/domain/dtos"
+	"github.com/certimate-go/certimate/internal/rest/resp"
+)
+
+type gitOpsService interface {
+	Sync(ctx context.Context, req *dtos.GitOpsSyncReq) (*dtos.GitOpsSyncResp, error)
+}
+
+type GitOpsHandler struct {
+	service gitOpsService
+}
+
+func NewGitOpsHandler(router *router.RouterGroup[*core.RequestEvent], service gitOpsService) {
+	handler := &GitOpsHandler{
+		service: service,
+	}
+
+	group := router.Group("/gitops")
+	group.POST("/sync", handler.sync)
+}
+
+func (handler *GitOpsHandler) sync(e *core.RequestEvent) error {
+	req := &dtos.GitOpsSyncReq{}
+	if err := e.BindBody(req); err != nil {
+		return resp.Err(e, err)
+	}
+
+	res, err := handler.service.Sync(e.Request.Context(), req)
+	if err != nil {
+		return resp.Err(e, err)
+	}
+
+	return resp.Ok(e, res)
+}
@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"context"
+	"io"
+	"net/http"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/router"
+
+	"github.com/certimate-go/certimate/internal/domain/dtos"
+	"github.com/certimate-go/certimate/internal/rest/resp"
+)
+
+type webhookService interface {
+	TriggerRunByWebhook(ctx context.Context, req *dtos.WorkflowTriggerByWebhookReq) (*dtos.WorkflowTriggerByWebhookResp, error)
+}
+
+type WebhooksHandler struct {
+	service webhookService
+}
+
+func NewWebhooksHandler(router *router.RouterGroup[*core.RequestEvent], service webhookService) {
+	handler := &WebhooksHandler{
+		service: service,
+	}
+
+	group := router.Group("/webhooks")
+	group.POST("/workflows/{workflowId}", handler.triggerWorkflow)
+}
+
+func (handler *WebhooksHandler) triggerWorkflow(e *core.RequestEvent) error {
+	payload, err := io.ReadAll(http.MaxBytesReader(e.Response, e.Request.Body, 1<<20))
+	if err != nil {
+		return resp.Err(e, err)
+	}
+
+	req := &dtos.WorkflowTriggerByWebhookReq{}
+	req.WorkflowId = e.Request.PathValue("workflowId")
+	req.Timestamp = e.Request.Header.Get("X-Certimate-Timestamp")
+	req.Signature = e.Request.Header.Get("X-Certimate-Signature")
+	req.Payload = payload
+
+	res, err := handler.service.TriggerRunByWebhook(e.Request.Context(), req)
+	if err != nil {
+		return resp.Err(e, err)
+	}
+
+	return resp.Ok(e, res)
+}
@@ -14,8 +14,11 @@ import (
 
 type workflowService interface {
 	GetStatistics(ctx context.Context) (*dtos.WorkflowStatisticsResp, error)
+	ExportWorkflow(ctx context.Context, req *dtos.WorkflowExportReq) (*dtos.WorkflowExportResp, error)
+	ImportWorkflow(ctx context.Context, req *dtos.WorkflowImportReq) (*dtos.WorkflowImportResp, error)
 	StartRun(ctx context.Context, req *dtos.WorkflowStartRunReq) (*dtos.WorkflowStartRunResp, error)
 	CancelRun(ctx context.Context, req *dtos.WorkflowCancelRunReq) (*dtos.WorkflowCancelRunResp, error)
+	ResumeRun(ctx context.Context, req *dtos.WorkflowResumeRunReq) (*dtos.WorkflowResumeRunResp, error)
 	Shutdown(ctx context.Context)
 }
 
@@ -30,8 +33,11 @@ func NewWorkflowsHandler(router *router.RouterGroup[*core.RequestEvent], service
 
 	group := router.Group("/workflows")
 	group.GET("/stats", handler.getStatistics)
+	group.POST("/import", handler.importWorkflow)
+	group.GET("/{workflowId}/export", handler.exportWorkflow)
 	group.POST("/{workflowId}/runs", handler.startRun)
 	group.POST("/{workflowId}/runs/{runId}/cancel", handler.cancelRun)
+	group.POST("/{workflowId}/runs/{runId}/resume", handler.resumeRun)
 }
 
 func (handler *WorkflowsHandler) getStatistics(e *core.RequestEvent) error {
@@ -43,6 +49,33 @@ func (handler *WorkflowsHandler) getStatistics(e *core.RequestEvent) error {
 	return resp.Ok(e, res)
 }
 
+func (handler *WorkflowsHandler) exportWorkflow(e *core.RequestEvent) error {
+	req := &dtos.WorkflowExportReq{}
+	req.WorkflowId = e.Request.PathValue("workflowId")
+	req.Format = e.Request.URL.Query().Get("format")
+
+	res, err := handler.service.ExportWorkflow(e.Request.Context(), req)
+	if err != nil {
+		return resp.Err(e, err)
+	}
+
+	return resp.Ok(e, res)
+}
+
+func (handler *WorkflowsHandler) importWorkflow(e *core.RequestEvent) error {
+	req := &dtos.WorkflowImportReq{}
+	if err := e.BindBody(req); err != nil {
+		return resp.Err(e, err)
+	}
+
+	res, err := handler.service.ImportWorkflow(e.Request.Context(), req)
+	if err != nil {
+		return resp.Err(e, err)
+	}
+
+	return resp.Ok(e, res)
+}
+
 func (handler *WorkflowsHandler) startRun(e *core.RequestEvent) error {
 	req := &dtos.WorkflowStartRunReq{}
 	req.WorkflowId = e.Request.PathValue("workflowId")
@@ -73,3 +106,16 @@ func (handler *WorkflowsHandler) cancelRun(e *core.RequestEvent) error {
 
 	return resp.Ok(e, res)
 }
+
+func (handler *WorkflowsHandler) resumeRun(e *core.RequestEvent) error {
+	req := &dtos.WorkflowResumeRunReq{}
+	req.WorkflowId = e.Request.PathValue("workflowId")
+	req.RunId = e.Request.PathValue("runId")
+
+	res, err := handler.service.ResumeRun(e.Request.Context(), req)
+	if err != nil {
+		return resp.Err(e, err)
+	}
+
+	return resp.Ok(e, res)
+}
@@ -5,7 +5,9 @@ import (
 	"github.com/pocketbase/pocketbase/core"
 	"github.com/pocketbase/pocketbase/tools/router"
 
+	"github.com/certimate-go/certimate/internal/access"
 	"github.com/certimate-go/certimate/internal/certificate"
+	"github.com/certimate-go/certimate/internal/gitops"
 	"github.com/certimate-go/certimate/internal/notify"
 	"github.com/certimate-go/certimate/internal/repository"
 	"github.com/certimate-go/certimate/internal/rest/handlers"
@@ -14,10 +16,12 @@ import (
 )
 
 var (
+	accessSvc      *access.AccessService
 	certificateSvc *certificate.CertificateService
 	workflowSvc    *workflow.WorkflowService
 	statisticsSvc  *statistics.StatisticsService
 	notifySvc      *notify.NotifyService
+	gitOpsSvc      *gitops.GitOpsService
 )
 
 func BindRouter(router *router.Router[*core.RequestEvent]) {
@@ -26,17 +30,28 @@ func BindRouter(router *router.Router[*core.RequestEvent]) {
 	workflowRunRepo := repository.NewWorkflowRunRepository()
 	acmeAccountRepo := repository.NewACMEAccountRepository()
 	certificateRepo := repository.NewCertificateRepository()
+	certificateDeploymentRepo := repository.NewCertificateDeploymentRepository()
 	statisticsRepo := repository.NewStatisticsRepository()
 
-	certificateSvc = certificate.NewCertificateService(acmeAccountRepo, certificateRepo)
-	workflowSvc = workflow.NewWorkflowService(workflowRepo, workflowRunRepo)
+	accessSvc = access.NewAccessService(accessRepo)
+	certificateSvc = certificate.NewCertificateService(accessRepo, acmeAccountRepo, certificateRepo, certificateDeploymentRepo)
+	workflowSvc = workflow.NewWorkflowService(accessRepo, workflowRepo, workflowRunRepo)
 	statisticsSvc = statistics.NewStatisticsService(statisticsRepo)
 	notifySvc = notify.NewNotifyService(accessRepo)
+	gitOpsSvc = gitops.NewGitOpsService(accessRepo, workflowRepo, workflowSvc)
+
+	// 以下路由无需鉴权，由各自的处理逻辑自行校验签名
+	publicGroup := router.Group("/api")
+	handlers.NewWebhooksHandler(publicGroup, workflowSvc)
+	handlers.NewApprovalsHandler(publicGroup, workflowSvc)
 
 	group := router.Group("/api")
 	group.Bind(apis.RequireSuperuserAuth())
+	handlers.NewAccessesHandler(group, accessSvc)
 	handlers.NewCertificatesHandler(group, certificateSvc)
+	handlers.NewDeploymentsHandler(group, certificateSvc)
 	handlers.NewWorkflowsHandler(group, workflowSvc)
 	handlers.NewStatisticsHandler(group, statisticsSvc)
 	handlers.NewNotificationsHandler(group, notifySvc)
+	handlers.NewGitOpsHandler(group, gitOpsSvc)
 }
@@ -0,0 +1,13 @@
+package scheduler
+
+import (
+	"context"
+)
+
+type gitOpsService interface {
+	InitSchedule(ctx context.Context) error
+}
+
+func initGitOpsScheduler(service gitOpsService) error {
+	return service.InitSchedule(context.Background())
+}
@@ -5,18 +5,25 @@ import (
 
 	"github.com/certimate-go/certimate/internal/app"
 	"github.com/certimate-go/certimate/internal/certificate"
+	"github.com/certimate-go/certimate/internal/discovery"
+	"github.com/certimate-go/certimate/internal/gitops"
 	"github.com/certimate-go/certimate/internal/repository"
 	"github.com/certimate-go/certimate/internal/workflow"
 )
 
 func Setup() {
+	accessRepo := repository.NewAccessRepository()
 	workflowRepo := repository.NewWorkflowRepository()
 	workflowRunRepo := repository.NewWorkflowRunRepository()
 	acmeAccountRepo := repository.NewACMEAccountRepository()
 	certificateRepo := repository.NewCertificateRepository()
+	certificateDeploymentRepo := repository.NewCertificateDeploymentRepository()
+	discoveredCertificateRepo := repository.NewDiscoveredCertificateRepository()
 
-	workflowSvc := workflow.NewWorkflowService(workflowRepo, workflowRunRepo)
-	certificateSvc := certificate.NewCertificateService(acmeAccountRepo, certificateRepo)
+	workflowSvc := workflow.NewWorkflowService(accessRepo, workflowRepo, workflowRunRepo)
+	certificateSvc := certificate.NewCertificateService(accessRepo, acmeAccountRepo, certificateRepo, certificateDeploymentRepo)
+	discoverySvc := discovery.NewDiscoveryService(certificateRepo, discoveredCertificateRepo)
+	gitOpsSvc := gitops.NewGitOpsService(accessRepo, workflowRepo, workflowSvc)
 
 	if err := initWorkflowScheduler(workflowSvc); err != nil {
 		app.GetLogger().Error("failed to init workflow scheduler", slog.Any("error", err))
@@ -25,4 +32,12 @@ func Setup() {
 	if err := initCertificateScheduler(certificateSvc); err != nil {
 		app.GetLogger().Error("failed to init certificate scheduler", slog.Any("error", err))
 	}
+
+	if err := initDiscoveryScheduler(discoverySvc); err != nil {
+		app.GetLogger().Error("failed to init discovery scheduler", slog.Any("error", err))
+	}
+
+	if err := initGitOpsScheduler(gitOpsSvc); err != nil {
+		app.GetLogger().Error("failed to init gitops scheduler", slog.Any("error", err))
+	}
 }
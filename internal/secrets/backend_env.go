@@ -0,0 +1,76 @@
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	xenv "github.com/certimate-go/certimate/pkg/utils/env"
+)
+
+type EnvBackendConfig struct {
+	// 允许读取的环境变量白名单。
+	// 每一项可以是完整的变量名称（如 "ALIYUN_ACCESS_KEY_SECRET"），也可以是以 "*" 结尾的前缀（如 "SECRET_*"）。
+	Allowlist []string
+}
+
+// 从环境变量中读取密钥。引用为环境变量名称。
+// 出于安全考虑，只允许读取白名单中的环境变量，可通过环境变量 `CERTIMATE_SECRETS_ENV_ALLOWLIST` 配置（多项以半角逗号分隔），默认仅允许以 "SECRET_" 开头的变量；
+// 无论白名单如何配置，均不允许读取 Certimate 自身的环境变量（以 "CERTIMATE_" 开头）。
+type EnvBackend struct {
+	config *EnvBackendConfig
+}
+
+func NewEnvBackend() *EnvBackend {
+	return NewEnvBackendWithConfig(nil)
+}
+
+func NewEnvBackendWithConfig(config *EnvBackendConfig) *EnvBackend {
+	if config == nil {
+		allowlist := make([]string, 0)
+		for _, item := range strings.Split(xenv.GetOrDefaultString("CERTIMATE_SECRETS_ENV_ALLOWLIST", "SECRET_*"), ",") {
+			item = strings.TrimSpace(item)
+			if item == "" {
+				continue
+			}
+
+			allowlist = append(allowlist, item)
+		}
+
+		config = &EnvBackendConfig{Allowlist: allowlist}
+	}
+
+	return &EnvBackend{config: config}
+}
+
+func (b *EnvBackend) Resolve(ctx context.Context, ref string) (string, error) {
+	name := strings.TrimSpace(ref)
+	if name == "" {
+		return "", fmt.Errorf("environment variable name is empty")
+	}
+	if strings.HasPrefix(strings.ToUpper(name), "CERTIMATE_") || !b.isAllowed(name) {
+		return "", fmt.Errorf("access to environment variable '%s' is not allowed", name)
+	}
+
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return "", fmt.Errorf("environment variable '%s' is not set", name)
+	}
+
+	return value, nil
+}
+
+func (b *EnvBackend) isAllowed(name string) bool {
+	for _, item := range b.config.Allowlist {
+		if prefix, ok := strings.CutSuffix(item, "*"); ok {
+			if prefix != "" && strings.HasPrefix(name, prefix) {
+				return true
+			}
+		} else if name == item {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,57 @@
+package secrets_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/certimate-go/certimate/internal/secrets"
+)
+
+func TestEnvBackend(t *testing.T) {
+	t.Setenv("SECRET_ALIYUN_ACCESS_KEY_SECRET", "aliyun-secret")
+	t.Setenv("PFX_PASSWORD", "pfx-password")
+	t.Setenv("DATABASE_PASSWORD", "db-password")
+	t.Setenv("CERTIMATE_ENCRYPTION_KEY", "master-key")
+
+	backend := secrets.NewEnvBackendWithConfig(&secrets.EnvBackendConfig{
+		Allowlist: []string{"SECRET_*", "PFX_PASSWORD", "CERTIMATE_*"},
+	})
+
+	testCases := []struct {
+		name    string
+		ref     string
+		want    string
+		wantErr bool
+	}{
+		{name: "prefix allowed", ref: "SECRET_ALIYUN_ACCESS_KEY_SECRET", want: "aliyun-secret"},
+		{name: "name allowed", ref: "PFX_PASSWORD", want: "pfx-password"},
+		{name: "not in allowlist", ref: "DATABASE_PASSWORD", wantErr: true},
+		{name: "certimate variable", ref: "CERTIMATE_ENCRYPTION_KEY", wantErr: true},
+		{name: "not set", ref: "SECRET_UNKNOWN", wantErr: true},
+		{name: "empty", ref: " ", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			value, err := backend.Resolve(context.Background(), tc.ref)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("expected error: %v, got %v", tc.wantErr, err)
+			}
+			if value != tc.want {
+				t.Errorf("expected '%s', got '%s'", tc.want, value)
+			}
+		})
+	}
+
+	t.Run("Default allowlist", func(t *testing.T) {
+		t.Setenv("CERTIMATE_SECRETS_ENV_ALLOWLIST", "")
+
+		backend := secrets.NewEnvBackend()
+		if _, err := backend.Resolve(context.Background(), "SECRET_ALIYUN_ACCESS_KEY_SECRET"); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if _, err := backend.Resolve(context.Background(), "PFX_PASSWORD"); err == nil {
+			t.Error("expected error, got nil")
+		}
+	})
+}
@@ -0,0 +1,65 @@
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	xenv "github.com/certimate-go/certimate/pkg/utils/env"
+)
+
+// 从文件中读取密钥，适用于 Docker 或 Kubernetes 挂载的密钥文件。引用为文件的绝对路径。
+// 出于安全考虑，只允许读取位于指定目录下的文件，可通过环境变量 `CERTIMATE_SECRETS_FILE_ROOTS` 配置（多个目录以半角逗号分隔）。
+type FileBackend struct {
+	roots []string
+}
+
+func NewFileBackend() *FileBackend {
+	roots := make([]string, 0)
+	for _, root := range strings.Split(xenv.GetOrDefaultString("CERTIMATE_SECRETS_FILE_ROOTS", "/run/secrets,/var/run/secrets"), ",") {
+		root = strings.TrimSpace(root)
+		if root == "" {
+			continue
+		}
+
+		roots = append(roots, filepath.Clean(root))
+	}
+
+	return &FileBackend{roots: roots}
+}
+
+func (b *FileBackend) Resolve(ctx context.Context, ref string) (string, error) {
+	path := filepath.Clean(strings.TrimSpace(ref))
+	if !filepath.IsAbs(path) {
+		return "", fmt.Errorf("file path '%s' must be absolute", ref)
+	}
+
+	if realPath, err := filepath.EvalSymlinks(path); err == nil {
+		path = realPath
+	}
+
+	allowed := false
+	for _, root := range b.roots {
+		if realRoot, err := filepath.EvalSymlinks(root); err == nil {
+			root = realRoot
+		}
+
+		if rel, err := filepath.Rel(root, path); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return "", fmt.Errorf("access to file '%s' is not allowed", ref)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	// 密钥文件通常以换行符结尾
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
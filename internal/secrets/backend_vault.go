@@ -0,0 +1,125 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	xenv "github.com/certimate-go/certimate/pkg/utils/env"
+)
+
+type VaultBackendConfig struct {
+	// Vault 服务地址。
+	Address string
+	// Vault 访问令牌。
+	Token string
+	// Vault 命名空间（仅企业版）。
+	Namespace string
+}
+
+// 从 HashiCorp Vault 的 KV 密钥引擎中读取密钥，同时支持 KV v1 和 KV v2。
+// 引用格式为 "<路径>#<键>"，如 "secret/data/certimate/aliyun#accessKeySecret"（KV v2）、"kv/certimate/aliyun#accessKeySecret"（KV v1）。
+// 默认通过环境变量 `VAULT_ADDR`、`VAULT_TOKEN`、`VAULT_NAMESPACE` 进行配置。
+type VaultBackend struct {
+	config     *VaultBackendConfig
+	httpClient *http.Client
+}
+
+func NewVaultBackend() *VaultBackend {
+	return NewVaultBackendWithConfig(nil)
+}
+
+func NewVaultBackendWithConfig(config *VaultBackendConfig) *VaultBackend {
+	return &VaultBackend{
+		config:     config,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+func (b *VaultBackend) Resolve(ctx context.Context, ref string) (string, error) {
+	path, key, ok := strings.Cut(strings.TrimSpace(ref), "#")
+	path = strings.Trim(path, "/")
+	if !ok || path == "" || key == "" {
+		return "", fmt.Errorf("invalid vault reference '%s', expected '<path>#<key>'", ref)
+	}
+
+	config := b.config
+	if config == nil {
+		config = &VaultBackendConfig{
+			Address:   xenv.GetOrDefaultString("VAULT_ADDR", "https://127.0.0.1:8200"),
+			Token:     xenv.GetString("VAULT_TOKEN"),
+			Namespace: xenv.GetString("VAULT_NAMESPACE"),
+		}
+	}
+
+	reqUrl, err := url.JoinPath(config.Address, "v1", path)
+	if err != nil {
+		return "", fmt.Errorf("invalid vault address: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqUrl, nil)
+	if err != nil {
+		return "", err
+	}
+	if config.Token != "" {
+		req.Header.Set("X-Vault-Token", config.Token)
+	}
+	if config.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", config.Namespace)
+	}
+
+	resp, err := b.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to request vault: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read vault response: %w", err)
+	}
+
+	type vaultResponse struct {
+		Data   map[string]any `json:"data"`
+		Errors []string       `json:"errors"`
+	}
+	respData := &vaultResponse{}
+	if err := json.Unmarshal(respBody, respData); err != nil && resp.StatusCode == http.StatusOK {
+		return "", fmt.Errorf("failed to parse vault response: %w", err)
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		return "", fmt.Errorf("vault secret '%s' not found", path)
+	} else if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected vault response status code: %d, errors: %s", resp.StatusCode, strings.Join(respData.Errors, "; "))
+	}
+
+	// KV v2 的数据嵌套在 "data.data" 中，并附带 "data.metadata"
+	data := respData.Data
+	if inner, ok := data["data"].(map[string]any); ok {
+		if _, ok := data["metadata"]; ok {
+			data = inner
+		}
+	}
+
+	value, ok := data[key]
+	if !ok {
+		return "", fmt.Errorf("key '%s' not found in vault secret '%s'", key, path)
+	}
+
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	default:
+		bytes, err := json.Marshal(v)
+		if err != nil {
+			return "", err
+		}
+		return string(bytes), nil
+	}
+}
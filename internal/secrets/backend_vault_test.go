@@ -0,0 +1,85 @@
+package secrets_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/certimate-go/certimate/internal/secrets"
+)
+
+func TestVaultBackend(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "test-token" {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"errors":["permission denied"]}`))
+			return
+		}
+
+		switch r.URL.Path {
+		case "/v1/secret/data/certimate/aliyun":
+			w.Write([]byte(`{"data":{"data":{"accessKeyId":"id-v2","accessKeySecret":"secret-v2"},"metadata":{"version":1}}}`))
+		case "/v1/kv/certimate/aliyun":
+			w.Write([]byte(`{"data":{"accessKeyId":"id-v1","accessKeySecret":"secret-v1"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"errors":[]}`))
+		}
+	}))
+	defer server.Close()
+
+	backend := secrets.NewVaultBackendWithConfig(&secrets.VaultBackendConfig{
+		Address: server.URL,
+		Token:   "test-token",
+	})
+
+	t.Run("KV v2", func(t *testing.T) {
+		value, err := backend.Resolve(context.Background(), "secret/data/certimate/aliyun#accessKeySecret")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if value != "secret-v2" {
+			t.Errorf("expected 'secret-v2', got '%s'", value)
+		}
+	})
+
+	t.Run("KV v1", func(t *testing.T) {
+		value, err := backend.Resolve(context.Background(), "kv/certimate/aliyun#accessKeyId")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if value != "id-v1" {
+			t.Errorf("expected 'id-v1', got '%s'", value)
+		}
+	})
+
+	t.Run("Missing key", func(t *testing.T) {
+		if _, err := backend.Resolve(context.Background(), "kv/certimate/aliyun#unknown"); err == nil {
+			t.Error("expected error, got nil")
+		}
+	})
+
+	t.Run("Missing secret", func(t *testing.T) {
+		if _, err := backend.Resolve(context.Background(), "kv/certimate/unknown#accessKeyId"); err == nil {
+			t.Error("expected error, got nil")
+		}
+	})
+
+	t.Run("Resolve map", func(t *testing.T) {
+		secrets.RegisterBackend("vault", backend)
+		defer secrets.RegisterBackend("vault", secrets.NewVaultBackend())
+
+		config, err := secrets.ResolveMap(context.Background(), map[string]any{
+			"accessKeyId":     "${secret:vault:secret/data/certimate/aliyun#accessKeyId}",
+			"accessKeySecret": "${secret:vault:secret/data/certimate/aliyun#accessKeySecret}",
+			"region":          "cn-hangzhou",
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if config["accessKeyId"] != "id-v2" || config["accessKeySecret"] != "secret-v2" || config["region"] != "cn-hangzhou" {
+			t.Errorf("unexpected resolved config: %v", config)
+		}
+	})
+}
@@ -0,0 +1,135 @@
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sync"
+)
+
+// 外部密钥引用的格式为 "${secret:<后端>:<引用>}"，可出现在授权配置或工作流节点配置的任意字符串值中。
+// 例如：
+//   - ${secret:env:SECRET_ALIYUN_ACCESS_KEY_SECRET}
+//   - ${secret:file:/run/secrets/aliyun_access_key_secret}
+//   - ${secret:vault:secret/data/certimate/aliyun#accessKeySecret}
+var referenceRegexp = regexp.MustCompile(`\$\{secret:([a-zA-Z0-9_-]+):([^}]+)\}`)
+
+// 表示一个外部密钥存储后端。
+type Backend interface {
+	// 读取密钥。
+	//
+	// 入参：
+	//   - ctx: 上下文。
+	//   - ref: 密钥引用，格式由具体后端决定。
+	//
+	// 出参：
+	//   - 密钥值。
+	//   - 错误。
+	Resolve(ctx context.Context, ref string) (string, error)
+}
+
+var (
+	backends   = make(map[string]Backend)
+	backendsMu sync.RWMutex
+)
+
+func init() {
+	RegisterBackend("env", NewEnvBackend())
+	RegisterBackend("file", NewFileBackend())
+	RegisterBackend("vault", NewVaultBackend())
+}
+
+// 注册外部密钥存储后端。同名后端将被覆盖。
+func RegisterBackend(name string, backend Backend) {
+	backendsMu.Lock()
+	defer backendsMu.Unlock()
+
+	backends[name] = backend
+}
+
+func getBackend(name string) (Backend, error) {
+	backendsMu.RLock()
+	defer backendsMu.RUnlock()
+
+	if backend, ok := backends[name]; ok {
+		return backend, nil
+	}
+
+	return nil, fmt.Errorf("secrets: unsupported backend '%s'", name)
+}
+
+// 判断字符串中是否包含外部密钥引用。
+func HasReference(value string) bool {
+	return referenceRegexp.MatchString(value)
+}
+
+// 解析字符串中的所有外部密钥引用，并替换为实际的密钥值。
+func ResolveString(ctx context.Context, value string) (string, error) {
+	var rerr error
+
+	resolved := referenceRegexp.ReplaceAllStringFunc(value, func(match string) string {
+		if rerr != nil {
+			return match
+		}
+
+		groups := referenceRegexp.FindStringSubmatch(match)
+		backend, err := getBackend(groups[1])
+		if err != nil {
+			rerr = err
+			return match
+		}
+
+		secret, err := backend.Resolve(ctx, groups[2])
+		if err != nil {
+			rerr = fmt.Errorf("secrets: failed to resolve '%s': %w", match, err)
+			return match
+		}
+
+		return secret
+	})
+	if rerr != nil {
+		return "", rerr
+	}
+
+	return resolved, nil
+}
+
+// 递归地解析字典中的所有外部密钥引用，并替换为实际的密钥值。
+// 注意该函数会修改原始的字典。
+func ResolveMap(ctx context.Context, dict map[string]any) (map[string]any, error) {
+	resolved, err := resolveValue(ctx, dict)
+	if err != nil {
+		return nil, err
+	}
+
+	return resolved.(map[string]any), nil
+}
+
+func resolveValue(ctx context.Context, data any) (any, error) {
+	switch v := data.(type) {
+	case map[string]any:
+		for k, va := range v {
+			r, err := resolveValue(ctx, va)
+			if err != nil {
+				return nil, err
+			}
+			v[k] = r
+		}
+
+	case []any:
+		for i, va := range v {
+			r, err := resolveValue(ctx, va)
+			if err != nil {
+				return nil, err
+			}
+			v[i] = r
+		}
+
+	case string:
+		if HasReference(v) {
+			return ResolveString(ctx, v)
+		}
+	}
+
+	return data, nil
+}
@@ -30,6 +30,26 @@ func GetGlobalSettingsForPersistence() domain.SettingsContentForPersistence {
 	return *(content.(domain.SettingsContent)).AsPersistence()
 }
 
+func GetGlobalSettingsForDiscovery() domain.SettingsContentForDiscovery {
+	pb := app.GetApp()
+	name := domain.SettingsNameDiscovery
+	content := pb.Store().Get(buildPbStoreKey(name))
+	if content == nil {
+		content = domain.SettingsContent{}
+	}
+	return *(content.(domain.SettingsContent)).AsDiscovery()
+}
+
+func GetGlobalSettingsForExpiryAlert() domain.SettingsContentForExpiryAlert {
+	pb := app.GetApp()
+	name := domain.SettingsNameExpiryAlert
+	content := pb.Store().Get(buildPbStoreKey(name))
+	if content == nil {
+		content = domain.SettingsContent{}
+	}
+	return *(content.(domain.SettingsContent)).AsExpiryAlert()
+}
+
 func registerSettingsStoreByName(settingsName string) error {
 	settingsRepo := repository.NewSettingsRepository()
 	settings, err := settingsRepo.GetByName(context.Background(), settingsName)
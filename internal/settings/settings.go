@@ -9,5 +9,7 @@ func Setup() {
 
 	registerSettingsStoreByName(domain.SettingsNameSSLProvider)
 	registerSettingsStoreByName(domain.SettingsNamePersistence)
+	registerSettingsStoreByName(domain.SettingsNameDiscovery)
+	registerSettingsStoreByName(domain.SettingsNameExpiryAlert)
 	registerSettingsRecordEvents()
 }
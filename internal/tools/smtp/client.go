@@ -41,6 +41,15 @@ func (c *Client) Close() error {
 	return nil
 }
 
+// 仅连接到 SMTP 服务器并完成身份认证，不发送邮件。
+func (c *Client) Verify(ctx context.Context) error {
+	if err := c.cli.DialWithContext(ctx); err != nil {
+		return fmt.Errorf("smtp: %w", err)
+	}
+
+	return c.Close()
+}
+
 func (c *Client) Send(ctx context.Context, msg *Message) error {
 	if err := c.cli.DialAndSendWithContext(ctx, msg); err != nil {
 		errShouldBeIgnored := false
@@ -1,11 +1,13 @@
 package ssh
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net"
 	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/samber/lo"
 	"golang.org/x/crypto/ssh"
@@ -14,6 +16,10 @@ import (
 type Client struct {
 	conns []net.Conn
 	clis  []*ssh.Client
+
+	closeOnce sync.Once
+	closeErr  error
+	stopFunc  func() bool
 }
 
 func NewClient(config *Config) (*Client, error) {
@@ -37,6 +43,39 @@ func NewClient(config *Config) (*Client, error) {
 	return &Client{conns: conns, clis: clis}, nil
 }
 
+// 与 NewClient 相同，但在上下文取消时中断连接过程。
+// 连接建立后，上下文取消时也将关闭连接，以中断正在执行的命令或文件传输。
+func NewClientWithContext(ctx context.Context, config *Config) (*Client, error) {
+	type result struct {
+		client *Client
+		err    error
+	}
+
+	done := make(chan result, 1)
+	go func() {
+		client, err := NewClient(config)
+		done <- result{client: client, err: err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		go func() {
+			if r := <-done; r.client != nil {
+				r.client.Close()
+			}
+		}()
+		return nil, fmt.Errorf("ssh: %w", ctx.Err())
+
+	case r := <-done:
+		if r.err != nil {
+			return nil, r.err
+		}
+
+		r.client.stopFunc = context.AfterFunc(ctx, func() { r.client.Close() })
+		return r.client, nil
+	}
+}
+
 func (c *Client) RawClient() *ssh.Client {
 	if len(c.clis) == 0 {
 		return nil
@@ -46,6 +85,18 @@ func (c *Client) RawClient() *ssh.Client {
 }
 
 func (c *Client) Close() error {
+	c.closeOnce.Do(func() {
+		if c.stopFunc != nil {
+			c.stopFunc()
+		}
+
+		c.closeErr = c.close()
+	})
+
+	return c.closeErr
+}
+
+func (c *Client) close() error {
 	errs := make([]error, 0)
 
 	for i := len(c.clis) - 1; i >= 0; i-- {
@@ -3,6 +3,8 @@ package dispatcher
 import (
 	"context"
 
+	"github.com/pocketbase/dbx"
+
 	"github.com/certimate-go/certimate/internal/domain"
 )
 
@@ -12,9 +14,11 @@ type workflowRepository interface {
 }
 
 type workflowRunRepository interface {
+	ListWithExprs(ctx context.Context, exprs ...dbx.Expression) ([]*domain.WorkflowRun, error)
 	GetById(ctx context.Context, id string) (*domain.WorkflowRun, error)
 	Save(ctx context.Context, workflowRun *domain.WorkflowRun) (*domain.WorkflowRun, error)
 	SaveWithCascading(ctx context.Context, workflowRun *domain.WorkflowRun) (*domain.WorkflowRun, error)
+	SaveCheckpoint(ctx context.Context, workflowRunId string, checkpoint *domain.WorkflowRunCheckpoint) error
 	ResetStatusIfHanging(ctx context.Context) error
 }
 
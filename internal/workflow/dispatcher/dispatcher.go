@@ -8,9 +8,11 @@ import (
 	"log/slog"
 	"runtime"
 	"runtime/debug"
+	"slices"
 	"sync"
 	"time"
 
+	"github.com/pocketbase/dbx"
 	"github.com/samber/lo"
 
 	"github.com/certimate-go/certimate/internal/app"
@@ -21,15 +23,33 @@ import (
 	xenv "github.com/certimate-go/certimate/pkg/utils/env"
 )
 
-var envMaxWorkers = 1
+// 进程重启后，对上次被中断的运行的恢复策略。
+const (
+	recoveryPolicyResume = "resume" // 从断点处恢复执行
+	recoveryPolicyRetry  = "retry"  // 从头重新执行
+	recoveryPolicyAbort  = "abort"  // 直接取消
+)
+
+var (
+	envMaxWorkers     = 1
+	envRecoveryPolicy = recoveryPolicyResume
+)
 
 func init() {
 	envMaxWorkers = xenv.GetOrDefaultInt("CERTIMATE_WORKFLOW_MAX_WORKERS", runtime.GOMAXPROCS(0))
 	if envMaxWorkers <= 0 {
 		envMaxWorkers = max(1, runtime.NumCPU())
 	}
+
+	envRecoveryPolicy = xenv.GetOrDefaultString("CERTIMATE_WORKFLOW_RECOVERY_POLICY", recoveryPolicyResume)
+	if !slices.Contains([]string{recoveryPolicyResume, recoveryPolicyRetry, recoveryPolicyAbort}, envRecoveryPolicy) {
+		envRecoveryPolicy = recoveryPolicyResume
+	}
 }
 
+// 调度器关闭时中断正在执行的任务的原因，被中断的运行将在下次启动时按恢复策略处理。
+var errInterrupted = errors.New("workflow dispatcher is shutting down")
+
 type WorkflowDispatcher interface {
 	GetStatistics() Statistics
 
@@ -37,6 +57,11 @@ type WorkflowDispatcher interface {
 	Shutdown(ctx context.Context) error
 	Start(ctx context.Context, runId string) error
 	Cancel(ctx context.Context, runId string) error
+	CancelWithReason(ctx context.Context, runId string, reason string) error
+
+	// 在调度器持有的触发锁内执行 fn。
+	// 调度器为全局单例，因此定时、Webhook、手动等各个来源的触发共享同一把锁，以保证并发策略的检查与入队是原子的。
+	WithTriggerLock(fn func() error) error
 }
 
 type Statistics struct {
@@ -49,6 +74,8 @@ type workflowDispatcher struct {
 	booted      bool
 	concurrency int
 
+	triggerMtx sync.Mutex
+
 	taskMtx         sync.RWMutex
 	pendingRunQueue []string
 	processingTasks map[string]*taskInfo // Key: RunId
@@ -89,12 +116,31 @@ func (wd *workflowDispatcher) Bootup(ctx context.Context) error {
 	wd.taskMtx.Lock()
 	defer wd.taskMtx.Unlock()
 
-	if err := wd.workflowRunRepo.ResetStatusIfHanging(ctx); err != nil {
+	// 处理上次退出时被中断的任务
+	if err := wd.recoverInterruptedRuns(ctx); err != nil {
+		return err
+	}
+
+	// 等待队列已持久化在运行记录中，重新入队上次退出时尚未执行的任务
+	pendingRuns, err := wd.workflowRunRepo.ListWithExprs(ctx, dbx.HashExp{"status": domain.WorkflowRunStatusTypePending.String()})
+	if err != nil {
 		return err
 	}
 
+	slices.SortFunc(pendingRuns, func(a, b *domain.WorkflowRun) int { return a.CreatedAt.Compare(b.CreatedAt) })
+	for _, pendingRun := range pendingRuns {
+		if !slices.Contains(wd.pendingRunQueue, pendingRun.Id) {
+			wd.pendingRunQueue = append(wd.pendingRunQueue, pendingRun.Id)
+		}
+	}
+
 	wd.booted = true
 
+	if len(wd.pendingRunQueue) > 0 {
+		wd.syslog.Info(fmt.Sprintf("%d workflow run(s) are re-enqueued", len(wd.pendingRunQueue)))
+		go func() { wd.tryNextAsync() }()
+	}
+
 	return nil
 }
 
@@ -107,7 +153,7 @@ func (wd *workflowDispatcher) Shutdown(ctx context.Context) error {
 	defer wd.taskMtx.Unlock()
 
 	for runId, task := range wd.processingTasks {
-		task.cancel()
+		task.cancel(errInterrupted)
 		delete(wd.processingTasks, runId)
 	}
 
@@ -138,13 +184,17 @@ func (wd *workflowDispatcher) Start(ctx context.Context, runId string) error {
 }
 
 func (wd *workflowDispatcher) Cancel(ctx context.Context, runId string) error {
+	return wd.CancelWithReason(ctx, runId, "")
+}
+
+func (wd *workflowDispatcher) CancelWithReason(ctx context.Context, runId string, reason string) error {
 	wd.taskMtx.Lock()
 	defer wd.taskMtx.Unlock()
 
 	workflowRun, err := wd.workflowRunRepo.GetById(ctx, runId)
 	if err != nil {
 		return err
-	} else if workflowRun.Status != domain.WorkflowRunStatusTypePending && workflowRun.Status != domain.WorkflowRunStatusTypeProcessing {
+	} else if workflowRun.Status != domain.WorkflowRunStatusTypePending && workflowRun.Status != domain.WorkflowRunStatusTypeProcessing && workflowRun.Status != domain.WorkflowRunStatusTypeWaiting {
 		return fmt.Errorf("workrun #%s is already completed", workflowRun.Id)
 	}
 
@@ -154,6 +204,7 @@ func (wd *workflowDispatcher) Cancel(ctx context.Context, runId string) error {
 	}
 
 	workflowRun.Status = domain.WorkflowRunStatusTypeCanceled
+	workflowRun.Reason = reason
 	if workflow.LastRunId == workflowRun.Id {
 		_, err := wd.workflowRunRepo.SaveWithCascading(ctx, workflowRun)
 		if err != nil {
@@ -167,7 +218,11 @@ func (wd *workflowDispatcher) Cancel(ctx context.Context, runId string) error {
 	}
 
 	if task, exists := wd.processingTasks[runId]; exists {
-		task.cancel()
+		if reason != "" {
+			task.cancel(errors.New(reason))
+		} else {
+			task.cancel(nil)
+		}
 		delete(wd.processingTasks, runId)
 
 		wd.syslog.Info(fmt.Sprintf("workrun #%s was canceled", task.RunId))
@@ -185,6 +240,40 @@ func (wd *workflowDispatcher) Cancel(ctx context.Context, runId string) error {
 	return nil
 }
 
+func (wd *workflowDispatcher) WithTriggerLock(fn func() error) error {
+	wd.triggerMtx.Lock()
+	defer wd.triggerMtx.Unlock()
+
+	return fn()
+}
+
+func (wd *workflowDispatcher) recoverInterruptedRuns(ctx context.Context) error {
+	if envRecoveryPolicy == recoveryPolicyAbort {
+		return wd.workflowRunRepo.ResetStatusIfHanging(ctx)
+	}
+
+	interruptedRuns, err := wd.workflowRunRepo.ListWithExprs(ctx, dbx.HashExp{"status": domain.WorkflowRunStatusTypeProcessing.String()})
+	if err != nil {
+		return err
+	}
+
+	for _, workflowRun := range interruptedRuns {
+		// 重新置为等待状态，随后与其他等待中的任务一同入队
+		workflowRun.Status = domain.WorkflowRunStatusTypePending
+		if envRecoveryPolicy == recoveryPolicyRetry && workflowRun.Checkpoint != nil {
+			// 从头重新执行，但保留已耗费的执行时间，以免超时限制因重启而失效
+			workflowRun.Checkpoint = &domain.WorkflowRunCheckpoint{ElapsedMilli: workflowRun.Checkpoint.ElapsedMilli}
+		}
+		if _, err := wd.workflowRunRepo.SaveWithCascading(ctx, workflowRun); err != nil {
+			return err
+		}
+
+		wd.syslog.Info(fmt.Sprintf("workflow #%s's run #%s was interrupted, it will be recovered with the '%s' policy", workflowRun.WorkflowId, workflowRun.Id, envRecoveryPolicy))
+	}
+
+	return nil
+}
+
 func (wd *workflowDispatcher) tryExecuteAsync(task *taskInfo) {
 	var workflow *domain.Workflow
 	var workflowRun *domain.WorkflowRun
@@ -216,20 +305,15 @@ func (wd *workflowDispatcher) tryExecuteAsync(task *taskInfo) {
 		go func() { wd.tryNextAsync() }()
 	}()
 
-	// 查询运行实体，并级联更新状态
+	// 查询运行实体
 	if workflowRun, err = wd.workflowRunRepo.GetById(task.ctx, task.RunId); err != nil {
 		if !(errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)) {
 			wd.syslog.Error(fmt.Sprintf("failed to get workrun #%s record", task.RunId), slog.Any("error", err))
 		}
 		return
-	} else {
-		if workflowRun.Status == domain.WorkflowRunStatusTypePending {
-			workflowRun.Status = domain.WorkflowRunStatusTypeProcessing
-			wd.workflowRunRepo.SaveWithCascading(task.ctx, workflowRun)
-		} else {
-			// WTF? That should be impossible!
-			return
-		}
+	} else if workflowRun.Status != domain.WorkflowRunStatusTypePending {
+		// WTF? That should be impossible!
+		return
 	}
 
 	// 查询工作流实体
@@ -239,13 +323,70 @@ func (wd *workflowDispatcher) tryExecuteAsync(task *taskInfo) {
 		return
 	}
 
+	// 出队时再次按并发策略检查，并级联更新状态
+	var skipped bool
+	err = wd.WithTriggerLock(func() error {
+		reason, err := wd.enforceConcurrencyPolicy(task.ctx, workflow, workflowRun)
+		if err != nil {
+			return err
+		}
+
+		if reason != "" {
+			skipped = true
+			workflowRun.Status = domain.WorkflowRunStatusTypeSkipped
+			workflowRun.EndedAt = time.Now()
+			workflowRun.Reason = reason
+		} else {
+			workflowRun.Status = domain.WorkflowRunStatusTypeProcessing
+		}
+		if workflow.LastRunId == workflowRun.Id || !skipped {
+			_, err = wd.workflowRunRepo.SaveWithCascading(task.ctx, workflowRun)
+		} else {
+			_, err = wd.workflowRunRepo.Save(task.ctx, workflowRun)
+		}
+		return err
+	})
+	if err != nil {
+		wd.syslog.Error(fmt.Sprintf("failed to dispatch workrun #%s", workflowRun.Id), slog.Any("error", err))
+		return
+	} else if skipped {
+		wd.syslog.Info(fmt.Sprintf("workflow #%s's run #%s is skipped: %s", workflowRun.WorkflowId, workflowRun.Id, workflowRun.Reason))
+		return
+	}
+
+	// 限制工作流运行的最长执行时间。
+	// 从断点恢复执行时（如审批后继续、进程重启后恢复）只能使用剩余的时间，挂起等待的时间不计入其中。
+	runCtx := task.ctx
+	runStartedAt := time.Now()
+	runElapsed := time.Duration(0)
+	if workflowRun.Checkpoint != nil {
+		runElapsed = time.Duration(workflowRun.Checkpoint.ElapsedMilli) * time.Millisecond
+	}
+	if workflow.Timeout > 0 {
+		var runCancel context.CancelFunc
+		runCtx, runCancel = context.WithTimeoutCause(task.ctx, time.Duration(workflow.Timeout)*time.Second-runElapsed, engine.ErrTimedOut)
+		defer runCancel()
+	}
+	stampElapsed := func(checkpoint *domain.WorkflowRunCheckpoint) *domain.WorkflowRunCheckpoint {
+		if checkpoint != nil {
+			checkpoint.ElapsedMilli = (runElapsed + time.Since(runStartedAt)).Milliseconds()
+		}
+		return checkpoint
+	}
+
 	// 初始化工作流引擎
 	logsBuf := make(domain.WorkflowLogs, 0)
+	logsMtx := sync.Mutex{} // 并行分支中的节点可能会同时写入日志
 	we := engine.NewWorkflowEngine()
 	we.OnEnd(func(ctx context.Context) error {
-		if errmsg := logsBuf.ErrorString(); errmsg == "" {
+		logsMtx.Lock()
+		errmsg := logsBuf.ErrorString()
+		logsMtx.Unlock()
+
+		if errmsg == "" {
 			workflowRun.Status = domain.WorkflowRunStatusTypeSucceeded
 			workflowRun.EndedAt = time.Now()
+			workflowRun.Checkpoint = nil
 		} else {
 			workflowRun.Status = domain.WorkflowRunStatusTypeFailed
 			workflowRun.EndedAt = time.Now()
@@ -257,17 +398,73 @@ func (wd *workflowDispatcher) tryExecuteAsync(task *taskInfo) {
 	})
 	we.OnError(func(ctx context.Context, err error) error {
 		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			// 因调度器关闭而中断时保持运行状态不变，待下次启动时按恢复策略处理
+			if errors.Is(context.Cause(task.ctx), errInterrupted) {
+				var execErr *engine.ExecutionError
+				if errors.As(err, &execErr) {
+					wd.workflowRunRepo.SaveCheckpoint(context.Background(), workflowRun.Id, stampElapsed(execErr.Checkpoint))
+				}
+				return nil
+			}
+
+			if errors.Is(context.Cause(runCtx), engine.ErrTimedOut) {
+				workflowRun.Status = domain.WorkflowRunStatusTypeTimedOut
+				workflowRun.EndedAt = time.Now()
+				workflowRun.Error = fmt.Sprintf("%s: the workflow run did not complete within %d seconds", engine.ErrTimedOut.Error(), workflow.Timeout)
+				var execErr *engine.ExecutionError
+				if errors.As(err, &execErr) {
+					workflowRun.Checkpoint = stampElapsed(execErr.Checkpoint)
+				}
+				wd.workflowRunRepo.SaveWithCascading(context.Background(), workflowRun)
+				return nil
+			}
+
 			workflowRun.Status = domain.WorkflowRunStatusTypeCanceled
+			if cause := context.Cause(task.ctx); cause != nil && !errors.Is(cause, context.Canceled) && !errors.Is(cause, context.DeadlineExceeded) {
+				workflowRun.Reason = cause.Error()
+			}
 			wd.workflowRunRepo.SaveWithCascading(context.Background(), workflowRun)
 		} else {
-			workflowRun.Status = domain.WorkflowRunStatusTypeFailed
+			workflowRun.Status = lo.If(errors.Is(err, engine.ErrTimedOut), domain.WorkflowRunStatusTypeTimedOut).Else(domain.WorkflowRunStatusTypeFailed)
 			workflowRun.EndedAt = time.Now()
 			workflowRun.Error = err.Error()
+			// 保存失败时的执行断点，以便后续从失败节点处恢复执行
+			var execErr *engine.ExecutionError
+			if errors.As(err, &execErr) {
+				workflowRun.Checkpoint = stampElapsed(execErr.Checkpoint)
+			}
 			wd.workflowRunRepo.SaveWithCascading(task.ctx, workflowRun)
 		}
 
 		return nil
 	})
+	we.OnSuspend(func(ctx context.Context, checkpoint *domain.WorkflowRunCheckpoint) error {
+		// 挂起后释放工作协程，待外部事件（如人工审批）后由调度器重新执行
+		workflowRun.Status = domain.WorkflowRunStatusTypeWaiting
+		workflowRun.Checkpoint = stampElapsed(checkpoint)
+		if _, err := wd.workflowRunRepo.SaveWithCascading(task.ctx, workflowRun); err != nil {
+			return err
+		}
+
+		wd.syslog.Info(fmt.Sprintf("workflow #%s's run #%s is waiting at node #%s", task.WorkflowId, task.RunId, checkpoint.NodeId))
+		return nil
+	})
+	checkpointMtx := sync.Mutex{} // 并行分支中的节点可能会同时保存执行进度
+	var checkpointSaved *domain.WorkflowRunCheckpoint
+	we.OnProgress(func(ctx context.Context, checkpoint *domain.WorkflowRunCheckpoint) error {
+		checkpointMtx.Lock()
+		defer checkpointMtx.Unlock()
+
+		// 持久化执行进度，以便进程意外退出后可从断点处恢复执行。
+		// 各分支生成快照与保存快照并非原子的，较早生成的快照可能晚于较新的快照保存，因此需与已保存的进度合并，以免进度回退。
+		checkpoint.Merge(checkpointSaved)
+		if err := wd.workflowRunRepo.SaveCheckpoint(context.Background(), workflowRun.Id, stampElapsed(checkpoint)); err != nil {
+			return err
+		}
+
+		checkpointSaved = checkpoint
+		return nil
+	})
 	we.OnNodeError(func(ctx context.Context, node *engine.Node, err error) error {
 		if errors.Is(err, engine.ErrTerminated) || errors.Is(err, engine.ErrBlocksException) {
 			return nil
@@ -282,7 +479,9 @@ func (wd *workflowDispatcher) tryExecuteAsync(task *taskInfo) {
 		log.Level = int32(slog.LevelError)
 		log.Message = err.Error()
 		log.CreatedAt = time.Now()
+		logsMtx.Lock()
 		logsBuf = append(logsBuf, log)
+		logsMtx.Unlock()
 
 		if _, err := wd.workflowLogRepo.Save(ctx, &log); err != nil {
 			wd.syslog.Error(err.Error())
@@ -301,7 +500,9 @@ func (wd *workflowDispatcher) tryExecuteAsync(task *taskInfo) {
 		log.Message = record.Message
 		log.Data = record.Data()
 		log.CreatedAt = time.Now()
+		logsMtx.Lock()
 		logsBuf = append(logsBuf, log)
+		logsMtx.Unlock()
 
 		if _, err := wd.workflowLogRepo.Save(ctx, &log); err != nil {
 			wd.syslog.Error(err.Error())
@@ -312,18 +513,60 @@ func (wd *workflowDispatcher) tryExecuteAsync(task *taskInfo) {
 
 	// 执行工作流
 	wd.syslog.Info(fmt.Sprintf("workflow #%s's run #%s started", task.WorkflowId, task.RunId))
-	we.Invoke(task.ctx, engine.WorkflowExecution{
+	we.Invoke(runCtx, engine.WorkflowExecution{
 		WorkflowId:          workflow.Id,
 		WorkflowName:        workflow.Name,
 		WorkflowDescription: workflow.Description,
 		RunId:               workflowRun.Id,
 		RunTrigger:          workflowRun.Trigger,
+		RunPayload:          workflowRun.Payload,
 		RunAt:               workflowRun.StartedAt,
+		DryRun:              workflowRun.DryRun,
 		Graph:               workflowRun.Graph,
+		Checkpoint:          workflowRun.Checkpoint,
 	})
 	wd.syslog.Info(fmt.Sprintf("workflow #%s's run #%s stopped", task.WorkflowId, task.RunId))
 }
 
+// 根据工作流的并发策略检查同一工作流下其他未结束的运行。
+// 入队前虽已检查过一次，但进程重启后恢复的、审批后继续的运行等并未经过触发时的检查，因此出队时需再次检查。
+// 返回值非空时表示本次运行应被跳过，其值为跳过原因。
+func (wd *workflowDispatcher) enforceConcurrencyPolicy(ctx context.Context, workflow *domain.Workflow, workflowRun *domain.WorkflowRun) (string, error) {
+	activeRuns, err := wd.workflowRunRepo.ListWithExprs(ctx,
+		dbx.HashExp{
+			"workflowRef": workflow.Id,
+			"status": []any{
+				domain.WorkflowRunStatusTypeProcessing.String(),
+				domain.WorkflowRunStatusTypeWaiting.String(),
+			},
+		},
+		dbx.Not(dbx.HashExp{"id": workflowRun.Id}),
+	)
+	if err != nil {
+		return "", err
+	} else if len(activeRuns) == 0 {
+		return "", nil
+	}
+
+	switch workflow.ConcurrencyPolicy {
+	case domain.WorkflowConcurrencyPolicyTypeAllow:
+		return "", nil
+
+	case domain.WorkflowConcurrencyPolicyTypeCancelPrevious:
+		for _, activeRun := range activeRuns {
+			if err := wd.CancelWithReason(ctx, activeRun.Id, "canceled by a newer run"); err != nil {
+				return "", err
+			}
+		}
+		return "", nil
+
+	default:
+		// 同一工作流的运行本就是串行执行的，此时仍有未结束的运行说明其已被挂起（如等待审批），
+		// skip 与 queue-one 策略下均不应越过它执行
+		return "the previous run has not finished yet", nil
+	}
+}
+
 func (wd *workflowDispatcher) tryNextAsync() {
 	wd.taskMtx.RLock()
 
@@ -350,7 +593,7 @@ func (wd *workflowDispatcher) tryNextAsync() {
 			wd.taskMtx.RUnlock()
 
 			wd.taskMtx.Lock()
-			ctxRun, ctxCancel := context.WithCancel(context.Background())
+			ctxRun, ctxCancel := context.WithCancelCause(context.Background())
 			task := &taskInfo{WorkflowId: workflowRun.WorkflowId, RunId: workflowRun.Id, ctx: ctxRun, cancel: ctxCancel}
 			wd.pendingRunQueue = lo.Filter(wd.pendingRunQueue, func(s string, _ int) bool { return s != pendingRunId })
 			wd.processingTasks[pendingRunId] = task
@@ -9,5 +9,5 @@ type taskInfo struct {
 	RunId      string
 
 	ctx    context.Context
-	cancel context.CancelFunc
+	cancel context.CancelCauseFunc
 }
@@ -8,10 +8,13 @@ type WorkflowContext struct {
 	WorkflowId string
 	RunId      string
 	RunGraph   *Graph
+	DryRun     bool // 是否为试运行，各节点执行器应据此避免产生实际变更
 
 	engine    WorkflowEngine
 	variables VariableManager
 	inputs    InOutManager
+	rollbacks RollbackManager
+	progress  ProgressManager
 
 	ctx context.Context
 }
@@ -23,6 +26,11 @@ func (c *WorkflowContext) SetExecutingWorkflow(workflowId string, runId string,
 	return c
 }
 
+func (c *WorkflowContext) SetDryRun(dryRun bool) *WorkflowContext {
+	c.DryRun = dryRun
+	return c
+}
+
 func (c *WorkflowContext) SetEngine(engine WorkflowEngine) *WorkflowContext {
 	c.engine = engine
 	return c
@@ -38,6 +46,16 @@ func (c *WorkflowContext) SetInputsManager(manager InOutManager) *WorkflowContex
 	return c
 }
 
+func (c *WorkflowContext) SetRollbacksManager(manager RollbackManager) *WorkflowContext {
+	c.rollbacks = manager
+	return c
+}
+
+func (c *WorkflowContext) SetProgressManager(manager ProgressManager) *WorkflowContext {
+	c.progress = manager
+	return c
+}
+
 func (c *WorkflowContext) SetContext(ctx context.Context) *WorkflowContext {
 	c.ctx = ctx
 	return c
@@ -52,10 +70,13 @@ func (c *WorkflowContext) Clone() *WorkflowContext {
 		WorkflowId: c.WorkflowId,
 		RunId:      c.RunId,
 		RunGraph:   c.RunGraph,
+		DryRun:     c.DryRun,
 
 		engine:    c.engine,
 		variables: c.variables,
 		inputs:    c.inputs,
+		rollbacks: c.rollbacks,
+		progress:  c.progress,
 
 		ctx: c.ctx,
 	}
@@ -16,6 +16,11 @@ type certificateRepository interface {
 	Save(ctx context.Context, certificate *domain.Certificate) (*domain.Certificate, error)
 }
 
+type certificateDeploymentRepository interface {
+	GetCurrentByTargetKey(ctx context.Context, targetKey string) (*domain.CertificateDeployment, error)
+	Save(ctx context.Context, certificateDeployment *domain.CertificateDeployment) (*domain.CertificateDeployment, error)
+}
+
 type workflowOutputRepository interface {
 	GetByWorkflowIdAndNodeId(ctx context.Context, workflowId string, workflowNodeId string) (*domain.WorkflowOutput, error)
 	Save(ctx context.Context, workflowOutput *domain.WorkflowOutput) (*domain.WorkflowOutput, error)
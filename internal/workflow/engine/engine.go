@@ -23,8 +23,11 @@ type WorkflowExecution struct {
 	WorkflowDescription string
 	RunId               string
 	RunTrigger          domain.WorkflowTriggerType
+	RunPayload          map[string]any
 	RunAt               time.Time
+	DryRun              bool
 	Graph               *Graph
+	Checkpoint          *domain.WorkflowRunCheckpoint // 执行断点，非空时将从断点处恢复执行
 }
 
 type WorkflowEngine interface {
@@ -33,6 +36,8 @@ type WorkflowEngine interface {
 	OnStart(callback func(ctx context.Context) error)
 	OnEnd(callback func(ctx context.Context) error)
 	OnError(callback func(ctx context.Context, err error) error)
+	OnSuspend(callback func(ctx context.Context, checkpoint *domain.WorkflowRunCheckpoint) error)
+	OnProgress(callback func(ctx context.Context, checkpoint *domain.WorkflowRunCheckpoint) error)
 	OnNodeStart(callback func(ctx context.Context, node *Node) error)
 	OnNodeEnd(callback func(ctx context.Context, node *Node, res *NodeExecutionResult) error)
 	OnNodeError(callback func(ctx context.Context, node *Node, err error) error)
@@ -40,12 +45,14 @@ type WorkflowEngine interface {
 }
 
 type workflowEngine struct {
-	executors map[NodeType]NodeExecutor
+	executors map[NodeType]func() NodeExecutor // 每次执行节点时都创建新的执行器实例，以支持并行执行
 
 	hooksMtx           sync.RWMutex
 	onStartHooks       [](func(ctx context.Context) error)
 	onEndHooks         [](func(ctx context.Context) error)
 	onErrorHooks       [](func(ctx context.Context, err error) error)
+	onSuspendHooks     [](func(ctx context.Context, checkpoint *domain.WorkflowRunCheckpoint) error)
+	onProgressHooks    [](func(ctx context.Context, checkpoint *domain.WorkflowRunCheckpoint) error)
 	onNodeStartHooks   [](func(ctx context.Context, node *Node) error)
 	onNodeEndHooks     [](func(ctx context.Context, node *Node, res *NodeExecutionResult) error)
 	onNodeErrorHooks   [](func(ctx context.Context, node *Node, err error) error)
@@ -72,23 +79,62 @@ func (we *workflowEngine) Invoke(ctx context.Context, execution WorkflowExecutio
 	wfIOs := newInOutManager()
 
 	wfVars := newVariableManager()
+
+	wfProgress := newProgressManager()
+	if execution.Checkpoint != nil {
+		wfProgress = restoreProgress(execution.Checkpoint, wfVars, wfIOs)
+	}
+
 	wfVars.Set(stateVarKeyWorkflowId, execution.WorkflowId, stateValTypeString)
 	wfVars.Set(stateVarKeyWorkflowName, execution.WorkflowName, stateValTypeString)
 	wfVars.Set(stateVarKeyWorkflowDescription, execution.WorkflowDescription, stateValTypeString)
 	wfVars.Set(stateVarKeyRunId, execution.RunId, stateValTypeString)
 	wfVars.Set(stateVarKeyRunTrigger, execution.RunTrigger, stateValTypeString)
+	wfVars.Set(stateVarKeyRunDryRun, execution.DryRun, stateValTypeBoolean)
+	for _, variable := range flattenRunPayload(execution.RunPayload) {
+		wfVars.Add(variable)
+	}
 	wfVars.Set(stateVarKeyErrorNodeId, "", stateValTypeString)
 	wfVars.Set(stateVarKeyErrorNodeName, "", stateValTypeString)
 	wfVars.Set(stateVarKeyErrorMessage, "", stateValTypeString)
 
+	wfRollbacks := newRollbackManager()
+
 	wfCtx := (&WorkflowContext{}).
 		SetExecutingWorkflow(execution.WorkflowId, execution.RunId, execution.Graph).
+		SetDryRun(execution.DryRun).
 		SetEngine(we).
 		SetInputsManager(wfIOs).
 		SetVariablesManager(wfVars).
+		SetRollbacksManager(wfRollbacks).
+		SetProgressManager(wfProgress).
 		SetContext(ctx)
+	if execution.Checkpoint != nil {
+		we.restoreRollbacks(wfCtx, execution.Checkpoint)
+	}
 	if err := we.executeBlocks(wfCtx, execution.Graph.Nodes); err != nil {
+		if errors.Is(err, ErrSuspended) {
+			if err := we.fireOnSuspendHooks(ctx, wfProgress.Snapshot(wfVars, wfIOs, wfRollbacks)); err != nil {
+				we.syslog.Error("workflow engine: the suspended actions are skipped, because the checkpoint could not be saved", slog.String("workflowId", execution.WorkflowId), slog.String("runId", execution.RunId))
+				return nil
+			}
+
+			we.executeSuspendedActions(wfCtx)
+			return nil
+		}
+
 		if !errors.Is(err, ErrTerminated) {
+			// 已回滚的节点及其所在的分支需重新执行，因此不能视为已完成
+			for _, node := range we.executeRollbacks(wfCtx) {
+				wfProgress.Revert(lo.Map(findNodePath(execution.Graph.Nodes, node.Id), func(n *Node, _ int) string { return n.Id })...)
+			}
+
+			checkpoint := wfProgress.Snapshot(wfVars, wfIOs, wfRollbacks)
+			if state, ok := wfVars.Get(stateVarKeyErrorNodeId); ok {
+				checkpoint.NodeId = state.ValueString()
+			}
+
+			err = &ExecutionError{Err: err, Checkpoint: checkpoint}
 			we.fireOnErrorHooks(ctx, err)
 			return err
 		}
@@ -117,6 +163,18 @@ func (we *workflowEngine) OnError(callback func(ctx context.Context, err error)
 	we.onErrorHooks = append(we.onErrorHooks, callback)
 }
 
+func (we *workflowEngine) OnSuspend(callback func(ctx context.Context, checkpoint *domain.WorkflowRunCheckpoint) error) {
+	we.hooksMtx.Lock()
+	defer we.hooksMtx.Unlock()
+	we.onSuspendHooks = append(we.onSuspendHooks, callback)
+}
+
+func (we *workflowEngine) OnProgress(callback func(ctx context.Context, checkpoint *domain.WorkflowRunCheckpoint) error) {
+	we.hooksMtx.Lock()
+	defer we.hooksMtx.Unlock()
+	we.onProgressHooks = append(we.onProgressHooks, callback)
+}
+
 func (we *workflowEngine) OnNodeStart(callback func(ctx context.Context, node *Node) error) {
 	we.hooksMtx.Lock()
 	defer we.hooksMtx.Unlock()
@@ -142,24 +200,23 @@ func (we *workflowEngine) OnNodeLogging(callback func(ctx context.Context, node
 }
 
 func (we *workflowEngine) executeNode(wfCtx *WorkflowContext, node *Node) error {
-	executor, ok := we.executors[node.Type]
-	if !ok {
+	var executor NodeExecutor
+	if newExecutor, ok := we.executors[node.Type]; !ok {
 		err := fmt.Errorf("workflow engine: no executor registered for node type: '%s'", node.Type)
 		return err
 	} else {
-		logger := slog.New(logging.NewHookHandler(nil, &logging.HookHandlerOptions{
-			Level: slog.LevelDebug,
-			WriteFunc: func(ctx context.Context, record logging.Record) error {
-				we.fireOnNodeLoggingHooks(ctx, node, record)
-				return nil
-			},
-		}))
-		executor.SetLogger(logger)
+		executor = newExecutor()
+		executor.SetLogger(we.newNodeLogger(node))
 	}
 
 	wfCtx.variables.SetScoped(node.Id, stateVarKeyNodeId, node.Id, stateValTypeString)
 	wfCtx.variables.SetScoped(node.Id, stateVarKeyNodeName, node.Data.Name, stateValTypeString)
 
+	// 从断点恢复执行时，已执行完成的节点直接跳过
+	if wfCtx.progress.IsCompleted(node.Id) {
+		return nil
+	}
+
 	// 节点已禁用，直接跳过执行
 	if node.Data.Disabled {
 		return nil
@@ -168,8 +225,25 @@ func (we *workflowEngine) executeNode(wfCtx *WorkflowContext, node *Node) error
 	we.fireOnNodeStartHooks(wfCtx.ctx, node)
 
 	execCtx := newNodeExecutionContext(wfCtx, node)
-	execRes, err := executor.Execute(execCtx)
-	if err != nil && !errors.Is(err, ErrTerminated) {
+	if nodeCfg, err := resolveNodeConfig(wfCtx.ctx, node.Data.Config); err != nil {
+		err = fmt.Errorf("failed to resolve secrets of node '%s': %w", node.Id, err)
+		we.fireOnNodeErrorHooks(wfCtx.ctx, node, err)
+		return err
+	} else {
+		execCtx.NodeConfig = nodeCfg
+	}
+	if node.Data.Timeout > 0 {
+		ctx, cancel := context.WithTimeout(wfCtx.ctx, time.Duration(node.Data.Timeout)*time.Second)
+		defer cancel()
+		execCtx.SetContext(ctx)
+	}
+
+	execRes, err := we.invokeExecutor(executor, execCtx)
+	if err != nil && wfCtx.ctx.Err() == nil && errors.Is(execCtx.Context().Err(), context.DeadlineExceeded) {
+		// 节点自身执行超时（而非工作流运行被取消），按一般的节点执行失败处理，以便被 TryCatch 节点捕获
+		err = fmt.Errorf("%w: the node did not complete within %d seconds", ErrTimedOut, node.Data.Timeout)
+	}
+	if err != nil && !errors.Is(err, ErrTerminated) && !errors.Is(err, ErrSuspended) {
 		if !errors.Is(err, ErrBlocksException) {
 			wfCtx.variables.Set(stateVarKeyErrorNodeId, node.Id, stateValTypeString)
 			wfCtx.variables.Set(stateVarKeyErrorNodeName, node.Data.Name, stateValTypeString)
@@ -195,8 +269,9 @@ func (we *workflowEngine) executeNode(wfCtx *WorkflowContext, node *Node) error
 			}
 		}
 
+		// 试运行时不持久化节点输出，以免影响后续正式运行时的跳过判断
 		execOutputs := lo.Filter(execRes.Outputs, func(state InOutState, _ int) bool { return state.Persistent })
-		if execRes.outputForced || len(execOutputs) > 0 {
+		if !execCtx.DryRun && (execRes.outputForced || len(execOutputs) > 0) {
 			output := &domain.WorkflowOutput{
 				WorkflowId: execCtx.WorkflowId,
 				RunId:      execCtx.RunId,
@@ -222,15 +297,71 @@ func (we *workflowEngine) executeNode(wfCtx *WorkflowContext, node *Node) error
 		if execRes.Terminated {
 			return ErrTerminated
 		}
+
+		if execRes.Suspended {
+			if execRes.OnSuspended != nil {
+				wfCtx.progress.Suspend(node.Id, SuspendedAction{Node: node, Run: execRes.OnSuspended})
+			} else {
+				wfCtx.progress.Suspend(node.Id)
+			}
+			return ErrSuspended
+		}
 	}
 
-	if err != nil && errors.Is(err, ErrTerminated) {
+	if err != nil && (errors.Is(err, ErrTerminated) || errors.Is(err, ErrSuspended)) {
 		return err
 	}
 
+	wfCtx.progress.Complete(node.Id)
+	we.fireOnProgressHooks(wfCtx.ctx, wfCtx.progress.Snapshot(wfCtx.variables, wfCtx.inputs, wfCtx.rollbacks))
+
 	return nil
 }
 
+// 执行节点执行器，并在上下文取消（如节点或工作流运行超时）时立即返回。
+// 部分执行器调用的第三方接口可能不响应上下文取消，此时不再等待其返回，其后续的执行结果将被丢弃。
+func (we *workflowEngine) invokeExecutor(executor NodeExecutor, execCtx *NodeExecutionContext) (*NodeExecutionResult, error) {
+	// 容器节点仅编排其子节点的执行，子节点会各自响应上下文取消，因此需等待其返回，以免子节点在后台继续修改执行状态
+	switch execCtx.Node.Type {
+	case NodeTypeCondition, NodeTypeBranchBlock, NodeTypeTryCatch, NodeTypeTryBlock, NodeTypeCatchBlock, NodeTypeParallel, NodeTypeParallelBlock:
+		return executor.Execute(execCtx)
+	}
+
+	type result struct {
+		res *NodeExecutionResult
+		err error
+	}
+
+	done := make(chan result, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- result{err: fmt.Errorf("workflow engine: node executor panic: %v", r)}
+				slog.Error(fmt.Sprintf("workflow engine: node executor panic: %v, stack trace: %s", r, string(debug.Stack())), slog.String("nodeId", execCtx.Node.Id))
+			}
+		}()
+
+		res, err := executor.Execute(execCtx)
+		done <- result{res: res, err: err}
+	}()
+
+	select {
+	case r := <-done:
+		return r.res, r.err
+
+	case <-execCtx.Context().Done():
+		// 给予响应上下文取消的执行器一段时间以完成清理
+		select {
+		case r := <-done:
+			return r.res, r.err
+		case <-time.After(5 * time.Second):
+		}
+
+		we.syslog.Warn(fmt.Sprintf("workflow engine: node #%s did not respond to the cancellation, it is abandoned", execCtx.Node.Id), slog.String("workflowId", execCtx.WorkflowId), slog.String("runId", execCtx.RunId))
+		return nil, execCtx.Context().Err()
+	}
+}
+
 func (we *workflowEngine) executeBlocks(wfCtx *WorkflowContext, blocks []*Node) error {
 	errs := make([]error, 0)
 
@@ -246,7 +377,7 @@ func (we *workflowEngine) executeBlocks(wfCtx *WorkflowContext, blocks []*Node)
 			// 如果当前节点是 TryCatch 节点、且在 CatchBlock 分支中没有 End 节点，
 			// 则暂存错误，但继续执行下一个节点，直到当前 Blocks 全部执行完毕。
 			if node.Type == NodeTypeTryCatch {
-				if !errors.Is(err, ErrTerminated) {
+				if !errors.Is(err, ErrTerminated) && !errors.Is(err, ErrSuspended) {
 					errs = append(errs, err)
 					continue
 				}
@@ -265,6 +396,71 @@ func (we *workflowEngine) executeBlocks(wfCtx *WorkflowContext, blocks []*Node)
 	return nil
 }
 
+// 工作流执行失败时，按逆序执行已登记的回滚动作。
+// 返回已执行回滚的节点。
+func (we *workflowEngine) executeRollbacks(wfCtx *WorkflowContext) []*Node {
+	if wfCtx.rollbacks == nil {
+		return nil
+	}
+
+	nodes := make([]*Node, 0)
+
+	// 即使工作流已被取消，也应尽力完成回滚
+	ctx := context.WithoutCancel(wfCtx.ctx)
+	for _, action := range wfCtx.rollbacks.Drain() {
+		logger := we.newNodeLogger(action.Node)
+		if err := action.Run(ctx, logger); err != nil {
+			logger.Error("rollback failed", slog.Any("error", err))
+		}
+
+		nodes = append(nodes, action.Node)
+	}
+
+	return nodes
+}
+
+// 从执行断点中重建已登记的回滚动作。
+func (we *workflowEngine) restoreRollbacks(wfCtx *WorkflowContext, checkpoint *domain.WorkflowRunCheckpoint) {
+	for _, rollback := range checkpoint.Rollbacks {
+		node, ok := wfCtx.RunGraph.GetNodeById(rollback.NodeId)
+		if !ok {
+			continue
+		}
+
+		newExecutor, ok := we.executors[node.Type]
+		if !ok {
+			continue
+		}
+
+		executor := newExecutor()
+		executor.SetLogger(we.newNodeLogger(node))
+		if restorer, ok := executor.(rollbackRestorer); ok {
+			wfCtx.rollbacks.Push(restorer.RestoreRollback(wfCtx, node, rollback.Data))
+		}
+	}
+}
+
+// 工作流挂起且断点已保存后，执行已登记的挂起后动作。
+func (we *workflowEngine) executeSuspendedActions(wfCtx *WorkflowContext) {
+	ctx := context.WithoutCancel(wfCtx.ctx)
+	for _, action := range wfCtx.progress.DrainSuspended() {
+		logger := we.newNodeLogger(action.Node)
+		if err := action.Run(ctx, logger); err != nil {
+			logger.Warn(err.Error())
+		}
+	}
+}
+
+func (we *workflowEngine) newNodeLogger(node *Node) *slog.Logger {
+	return slog.New(logging.NewHookHandler(nil, &logging.HookHandlerOptions{
+		Level: slog.LevelDebug,
+		WriteFunc: func(ctx context.Context, record logging.Record) error {
+			we.fireOnNodeLoggingHooks(ctx, node, record)
+			return nil
+		},
+	}))
+}
+
 func (we *workflowEngine) fireOnStartHooks(ctx context.Context) {
 	we.hooksMtx.RLock()
 	defer we.hooksMtx.RUnlock()
@@ -295,6 +491,29 @@ func (we *workflowEngine) fireOnErrorHooks(ctx context.Context, err error) {
 	}
 }
 
+func (we *workflowEngine) fireOnSuspendHooks(ctx context.Context, checkpoint *domain.WorkflowRunCheckpoint) error {
+	we.hooksMtx.RLock()
+	defer we.hooksMtx.RUnlock()
+	var errs []error
+	for _, cb := range we.onSuspendHooks {
+		if cbErr := cb(ctx, checkpoint); cbErr != nil {
+			we.syslog.Error("workflow engine: error in onSuspend hook", slog.Any("error", cbErr))
+			errs = append(errs, cbErr)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (we *workflowEngine) fireOnProgressHooks(ctx context.Context, checkpoint *domain.WorkflowRunCheckpoint) {
+	we.hooksMtx.RLock()
+	defer we.hooksMtx.RUnlock()
+	for _, cb := range we.onProgressHooks {
+		if cbErr := cb(ctx, checkpoint); cbErr != nil {
+			we.syslog.Error("workflow engine: error in onProgress hook", slog.Any("error", cbErr))
+		}
+	}
+}
+
 func (we *workflowEngine) fireOnNodeStartHooks(ctx context.Context, node *Node) {
 	we.hooksMtx.RLock()
 	defer we.hooksMtx.RUnlock()
@@ -337,22 +556,25 @@ func (we *workflowEngine) fireOnNodeLoggingHooks(ctx context.Context, node *Node
 
 func NewWorkflowEngine() WorkflowEngine {
 	engine := &workflowEngine{
-		executors:    make(map[NodeType]NodeExecutor),
+		executors:    make(map[NodeType]func() NodeExecutor),
 		wfoutputRepo: repository.NewWorkflowOutputRepository(),
 		syslog:       app.GetLogger(),
 	}
-	engine.executors[NodeTypeStart] = newStartNodeExecutor()
-	engine.executors[NodeTypeEnd] = newEndNodeExecutor()
-	engine.executors[NodeTypeDelay] = newDelayNodeExecutor()
-	engine.executors[NodeTypeCondition] = newConditionNodeExecutor()
-	engine.executors[NodeTypeBranchBlock] = newBranchBlockNodeExecutor()
-	engine.executors[NodeTypeTryCatch] = newTryCatchNodeExecutor()
-	engine.executors[NodeTypeTryBlock] = newTryBlockNodeExecutor()
-	engine.executors[NodeTypeCatchBlock] = newCatchBlockNodeExecutor()
-	engine.executors[NodeTypeBizApply] = newBizApplyNodeExecutor()
-	engine.executors[NodeTypeBizUpload] = newBizUploadNodeExecutor()
-	engine.executors[NodeTypeBizMonitor] = newBizMonitorNodeExecutor()
-	engine.executors[NodeTypeBizDeploy] = newBizDeployNodeExecutor()
-	engine.executors[NodeTypeBizNotify] = newBizNotifyNodeExecutor()
+	engine.executors[NodeTypeStart] = newStartNodeExecutor
+	engine.executors[NodeTypeEnd] = newEndNodeExecutor
+	engine.executors[NodeTypeDelay] = newDelayNodeExecutor
+	engine.executors[NodeTypeApproval] = newApprovalNodeExecutor
+	engine.executors[NodeTypeCondition] = newConditionNodeExecutor
+	engine.executors[NodeTypeBranchBlock] = newBranchBlockNodeExecutor
+	engine.executors[NodeTypeTryCatch] = newTryCatchNodeExecutor
+	engine.executors[NodeTypeTryBlock] = newTryBlockNodeExecutor
+	engine.executors[NodeTypeCatchBlock] = newCatchBlockNodeExecutor
+	engine.executors[NodeTypeParallel] = newParallelNodeExecutor
+	engine.executors[NodeTypeParallelBlock] = newParallelBlockNodeExecutor
+	engine.executors[NodeTypeBizApply] = newBizApplyNodeExecutor
+	engine.executors[NodeTypeBizUpload] = newBizUploadNodeExecutor
+	engine.executors[NodeTypeBizMonitor] = newBizMonitorNodeExecutor
+	engine.executors[NodeTypeBizDeploy] = newBizDeployNodeExecutor
+	engine.executors[NodeTypeBizNotify] = newBizNotifyNodeExecutor
 	return engine
 }
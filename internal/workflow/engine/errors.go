@@ -2,11 +2,31 @@ package engine
 
 import (
 	"fmt"
+
+	"github.com/certimate-go/certimate/internal/domain"
 )
 
 var (
 	// 表示工作流引擎执行被中断，可能已结束
 	ErrTerminated = fmt.Errorf("workflow engine: execution was terminated")
+	// 表示工作流引擎执行被挂起，需等待外部事件（如人工审批）后恢复
+	ErrSuspended = fmt.Errorf("workflow engine: execution was suspended")
 	// 表示工作流引擎在执行子节点时发生异常
 	ErrBlocksException = fmt.Errorf("workflow engine: error occurred when executing blocks")
+	// 表示节点或工作流运行执行超时
+	ErrTimedOut = fmt.Errorf("workflow engine: execution timed out")
 )
+
+// 表示工作流执行失败，并携带失败时的执行断点，可用于从失败节点处恢复执行。
+type ExecutionError struct {
+	Err        error
+	Checkpoint *domain.WorkflowRunCheckpoint
+}
+
+func (e *ExecutionError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ExecutionError) Unwrap() error {
+	return e.Err
+}
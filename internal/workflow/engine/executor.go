@@ -2,8 +2,12 @@ package engine
 
 import (
 	"context"
+	"encoding/json"
 	"log/slog"
 	"sync"
+
+	"github.com/certimate-go/certimate/internal/domain"
+	"github.com/certimate-go/certimate/internal/secrets"
 )
 
 type NodeExecutor interface {
@@ -23,7 +27,8 @@ func (e *nodeExecutor) SetLogger(logger *slog.Logger) {
 type NodeExecutionContext struct {
 	WorkflowContext
 
-	Node *Node
+	Node       *Node
+	NodeConfig domain.WorkflowNodeConfig // 节点配置，其中引用的外部密钥已被解析。仅用于执行，不应被持久化或写入日志
 }
 
 func (c *NodeExecutionContext) SetExecutingWorkflow(workflowId string, runId string, runGraph *Graph) *NodeExecutionContext {
@@ -33,6 +38,12 @@ func (c *NodeExecutionContext) SetExecutingWorkflow(workflowId string, runId str
 
 func (c *NodeExecutionContext) SetExecutingNode(node *Node) *NodeExecutionContext {
 	c.Node = node
+	c.NodeConfig = node.Data.Config
+	return c
+}
+
+func (c *NodeExecutionContext) SetDryRun(dryRun bool) *NodeExecutionContext {
+	c.WorkflowContext.SetDryRun(dryRun)
 	return c
 }
 
@@ -51,18 +62,55 @@ func (c *NodeExecutionContext) SetInputsManager(inputs InOutManager) *NodeExecut
 	return c
 }
 
+func (c *NodeExecutionContext) SetRollbacksManager(rollbacks RollbackManager) *NodeExecutionContext {
+	c.WorkflowContext.SetRollbacksManager(rollbacks)
+	return c
+}
+
+func (c *NodeExecutionContext) SetProgressManager(progress ProgressManager) *NodeExecutionContext {
+	c.WorkflowContext.SetProgressManager(progress)
+	return c
+}
+
 func (c *NodeExecutionContext) SetContext(ctx context.Context) *NodeExecutionContext {
 	c.WorkflowContext.SetContext(ctx)
 	return c
 }
 
+// 解析节点配置中引用的外部密钥。返回的是副本，不会修改工作流图中的原始配置。
+func resolveNodeConfig(ctx context.Context, config domain.WorkflowNodeConfig) (domain.WorkflowNodeConfig, error) {
+	data, err := json.Marshal(config)
+	if err != nil {
+		return nil, err
+	}
+
+	if !secrets.HasReference(string(data)) {
+		return config, nil
+	}
+
+	resolved := make(map[string]any)
+	if err := json.Unmarshal(data, &resolved); err != nil {
+		return nil, err
+	}
+
+	resolved, err = secrets.ResolveMap(ctx, resolved)
+	if err != nil {
+		return nil, err
+	}
+
+	return domain.WorkflowNodeConfig(resolved), nil
+}
+
 func newNodeExecutionContext(wfCtx *WorkflowContext, node *Node) *NodeExecutionContext {
 	return (&NodeExecutionContext{}).
 		SetExecutingWorkflow(wfCtx.WorkflowId, wfCtx.RunId, wfCtx.RunGraph).
 		SetExecutingNode(node).
+		SetDryRun(wfCtx.DryRun).
 		SetEngine(wfCtx.engine).
 		SetVariablesManager(wfCtx.variables).
 		SetInputsManager(wfCtx.inputs).
+		SetRollbacksManager(wfCtx.rollbacks).
+		SetProgressManager(wfCtx.progress).
 		SetContext(wfCtx.ctx)
 }
 
@@ -70,6 +118,9 @@ type NodeExecutionResult struct {
 	node *Node
 
 	Terminated bool // 是否终止执行（通常由 End 节点主动触发）
+	Suspended  bool // 是否挂起执行（通常由 Approval 节点主动触发）
+
+	OnSuspended func(ctx context.Context, logger *slog.Logger) error // 挂起状态持久化后执行的动作，仅在挂起执行时有效
 
 	variablesMtx sync.Mutex
 	Variables    []VariableState
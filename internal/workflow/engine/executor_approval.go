@@ -0,0 +1,214 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pocketbase/pocketbase/tools/security"
+
+	"github.com/certimate-go/certimate/internal/app"
+	"github.com/certimate-go/certimate/internal/domain"
+	"github.com/certimate-go/certimate/internal/notify"
+	"github.com/certimate-go/certimate/internal/repository"
+)
+
+const (
+	defaultApprovalSubject = "[Certimate] Approval Required: {{ $workflow.name }}"
+	defaultApprovalMessage = "The workflow \"{{ $workflow.name }}\" is waiting for approval at node \"{{ $approval.nodeName }}\".\n\n" +
+		"Approve: {{ $approval.approveUrl }}\n" +
+		"Reject: {{ $approval.rejectUrl }}\n\n" +
+		"This request will expire at {{ datetime $approval.expiresAt }}."
+)
+
+type ApprovalStatus string
+
+const (
+	ApprovalStatusPending  = ApprovalStatus("pending")
+	ApprovalStatusApproved = ApprovalStatus("approved")
+	ApprovalStatusRejected = ApprovalStatus("rejected")
+	ApprovalStatusExpired  = ApprovalStatus("expired")
+)
+
+// 表示执行断点中挂起的审批请求。
+type PendingApproval struct {
+	NodeId    string
+	Token     string
+	ExpiresAt time.Time
+}
+
+// 从执行断点中获取挂起的审批请求。
+func GetPendingApproval(checkpoint *domain.WorkflowRunCheckpoint) (*PendingApproval, bool) {
+	if checkpoint == nil || checkpoint.NodeId == "" {
+		return nil, false
+	}
+
+	status, ok := checkpoint.GetVariable(checkpoint.NodeId, stateVarKeyApprovalStatus)
+	if !ok || status.Value != string(ApprovalStatusPending) {
+		return nil, false
+	}
+
+	approval := &PendingApproval{NodeId: checkpoint.NodeId}
+	if token, ok := checkpoint.GetVariable(checkpoint.NodeId, stateVarKeyApprovalToken); ok {
+		approval.Token, _ = token.Value.(string)
+	}
+	if expiresAt, ok := checkpoint.GetVariable(checkpoint.NodeId, stateVarKeyApprovalExpiresAt); ok {
+		approval.ExpiresAt, _ = restoreStateValue(expiresAt.Value, stateValTypeDateTime).(time.Time)
+	}
+
+	return approval, true
+}
+
+// 将审批结果记录到执行断点中，恢复执行时审批节点将据此继续执行或失败。
+func ResolvePendingApproval(checkpoint *domain.WorkflowRunCheckpoint, status ApprovalStatus, comment string) {
+	checkpoint.SetVariable(checkpoint.NodeId, stateVarKeyApprovalStatus, string(status), stateValTypeString)
+	if comment != "" {
+		checkpoint.SetVariable(checkpoint.NodeId, stateVarKeyApprovalComment, comment, stateValTypeString)
+	}
+}
+
+/**
+ * Variables:
+ *   - "approval.status": string
+ *   - "approval.expiresAt": datetime
+ *   - "approval.comment": string
+ */
+type approvalNodeExecutor struct {
+	nodeExecutor
+
+	accessRepo accessRepository
+}
+
+func (ne *approvalNodeExecutor) Execute(execCtx *NodeExecutionContext) (*NodeExecutionResult, error) {
+	execRes := newNodeExecutionResult(execCtx.Node)
+
+	nodeCfg := execCtx.NodeConfig.AsApproval()
+
+	// 试运行时不会挂起，视为审批通过
+	if execCtx.DryRun {
+		ne.logger.Info("dry run: the approval is treated as approved")
+		return execRes, nil
+	}
+
+	// 从断点恢复执行时，根据审批结果决定是否继续
+	if state, ok := execCtx.variables.GetScoped(execCtx.Node.Id, stateVarKeyApprovalStatus); ok {
+		comment := ""
+		if commentState, ok := execCtx.variables.GetScoped(execCtx.Node.Id, stateVarKeyApprovalComment); ok {
+			comment = commentState.ValueString()
+		}
+
+		switch ApprovalStatus(state.ValueString()) {
+		case ApprovalStatusApproved:
+			ne.logger.Info("the approval was approved", slog.String("comment", comment))
+			return execRes, nil
+
+		case ApprovalStatusRejected:
+			if comment != "" {
+				return execRes, fmt.Errorf("the approval was rejected: %s", comment)
+			}
+			return execRes, fmt.Errorf("the approval was rejected")
+
+		case ApprovalStatusExpired:
+			return execRes, fmt.Errorf("the approval has timed out")
+		}
+
+		ne.logger.Info("still waiting for approval ...")
+		execRes.Suspended = true
+		return execRes, nil
+	}
+
+	token := security.RandomString(40)
+	expiresAt := time.Now().Add(time.Duration(nodeCfg.Timeout) * time.Second)
+	execRes.AddVariableWithScope(execCtx.Node.Id, stateVarKeyApprovalStatus, string(ApprovalStatusPending), stateValTypeString)
+	execRes.AddVariableWithScope(execCtx.Node.Id, stateVarKeyApprovalToken, token, stateValTypeString)
+	execRes.AddVariableWithScope(execCtx.Node.Id, stateVarKeyApprovalExpiresAt, expiresAt, stateValTypeDateTime)
+
+	// 推送审批通知，须在断点及审批令牌保存后再推送，以免审批请求先于其可被处理之前送达
+	if nodeCfg.Provider != "" {
+		tmplData := buildNotifyTemplateData(execCtx)
+		execRes.OnSuspended = func(ctx context.Context, logger *slog.Logger) error {
+			if err := ne.sendNotification(ctx, execCtx, tmplData, token, expiresAt); err != nil {
+				return fmt.Errorf("could not send approval notification, please approve or reject it in the console: %w", err)
+			}
+
+			return nil
+		}
+	}
+
+	ne.logger.Info(fmt.Sprintf("waiting for approval until %s ...", expiresAt.Format(time.RFC3339)))
+	execRes.Suspended = true
+	return execRes, nil
+}
+
+func (ne *approvalNodeExecutor) sendNotification(ctx context.Context, execCtx *NodeExecutionContext, tmplData map[string]any, token string, expiresAt time.Time) error {
+	nodeCfg := execCtx.NodeConfig.AsApproval()
+
+	// 读取通知提供商授权
+	providerAccessConfig := make(map[string]any)
+	if nodeCfg.ProviderAccessId != "" {
+		if access, err := ne.accessRepo.GetById(ctx, nodeCfg.ProviderAccessId); err != nil {
+			return fmt.Errorf("failed to get access #%s record: %w", nodeCfg.ProviderAccessId, err)
+		} else {
+			providerAccessConfig = access.Config
+		}
+	}
+
+	// 审批链接需要外部可访问，因此依赖于应用设置中的 AppURL
+	appUrl := strings.TrimRight(app.GetApp().Settings().Meta.AppURL, "/")
+	if appUrl == "" {
+		ne.logger.Warn("the application url is not configured, the approval links may be unreachable")
+	}
+	runUrl := fmt.Sprintf("%s/api/workflows/%s/runs/%s", appUrl, url.PathEscape(execCtx.WorkflowId), url.PathEscape(execCtx.RunId))
+
+	// 渲染通知模板
+	tmplData["approval"] = map[string]any{
+		"nodeId":     execCtx.Node.Id,
+		"nodeName":   execCtx.Node.Data.Name,
+		"approveUrl": runUrl + "/approve?token=" + url.QueryEscape(token),
+		"rejectUrl":  runUrl + "/reject?token=" + url.QueryEscape(token),
+		"expiresAt":  expiresAt,
+	}
+
+	subjectTmpl := nodeCfg.Subject
+	if subjectTmpl == "" {
+		subjectTmpl = defaultApprovalSubject
+	}
+	subject, err := notify.RenderTemplate(subjectTmpl, notify.MessageFormatPlain, tmplData)
+	if err != nil {
+		return fmt.Errorf("failed to render subject template: %w", err)
+	}
+
+	messageTmpl := nodeCfg.Message
+	if messageTmpl == "" {
+		messageTmpl = defaultApprovalMessage
+	}
+	message, err := notify.RenderTemplate(messageTmpl, notify.GetMessageFormat(domain.NotificationProviderType(nodeCfg.Provider), nodeCfg.ProviderConfig), tmplData)
+	if err != nil {
+		return fmt.Errorf("failed to render message template: %w", err)
+	}
+
+	// 推送通知
+	notifier := notify.NewClient(notify.WithLogger(ne.logger))
+	notifyReq := &notify.SendNotificationRequest{
+		Provider:               domain.NotificationProviderType(nodeCfg.Provider),
+		ProviderAccessConfig:   providerAccessConfig,
+		ProviderExtendedConfig: nodeCfg.ProviderConfig,
+		Subject:                subject,
+		Message:                message,
+	}
+	if _, err := notifier.SendNotification(ctx, notifyReq); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func newApprovalNodeExecutor() NodeExecutor {
+	return &approvalNodeExecutor{
+		nodeExecutor: nodeExecutor{logger: slog.Default()},
+		accessRepo:   repository.NewAccessRepository(),
+	}
+}
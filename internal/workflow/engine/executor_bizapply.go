@@ -2,6 +2,7 @@ package engine
 
 import (
 	"crypto/x509"
+	"errors"
 	"fmt"
 	"log/slog"
 	"maps"
@@ -62,8 +63,13 @@ type bizApplyNodeExecutor struct {
 func (ne *bizApplyNodeExecutor) Execute(execCtx *NodeExecutionContext) (*NodeExecutionResult, error) {
 	execRes := newNodeExecutionResult(execCtx.Node)
 
-	nodeCfg := execCtx.Node.Data.Config.AsBizApply()
-	ne.logger.Info("ready to request certificate ...", slog.Any("config", nodeCfg))
+	nodeCfg := execCtx.NodeConfig.AsBizApply()
+	ne.logger.Info("ready to request certificate ...", slog.Any("config", execCtx.Node.Data.Config.AsBizApply()))
+
+	// 试运行时不会签发正式证书，也不会保存证书实体
+	if execCtx.DryRun {
+		return ne.executeDryRun(execCtx, execRes, &nodeCfg)
+	}
 
 	// 查询上次执行结果
 	lastOutput, lastCertificate, err := ne.getLastOutputArtifacts(execCtx)
@@ -116,6 +122,9 @@ func (ne *bizApplyNodeExecutor) Execute(execCtx *NodeExecutionContext) (*NodeExe
 		WorkflowRunId:      execCtx.RunId,
 		WorkflowNodeId:     execCtx.Node.Id,
 	}
+	if lastCertificate != nil {
+		certificate.PredecessorId = lastCertificate.Id
+	}
 	certificate.PopulateFromPEM(obtainResp.FullChainCertificate, obtainResp.PrivateKey)
 	if certificate, err := ne.certificateRepo.Save(execCtx.Context(), certificate); err != nil {
 		ne.logger.Warn("could not save certificate")
@@ -124,10 +133,15 @@ func (ne *bizApplyNodeExecutor) Execute(execCtx *NodeExecutionContext) (*NodeExe
 		ne.logger.Info("certificate saved", slog.String("recordId", certificate.Id))
 	}
 
-	// 保存 ARI 替换状态
-	if lastCertificate != nil && obtainResp.ARIReplaced {
-		lastCertificate.IsRenewed = true
-		ne.certificateRepo.Save(execCtx.Context(), lastCertificate)
+	// 保存证书替换关系及 ARI 替换状态
+	if lastCertificate != nil {
+		lastCertificate.ReplacedById = certificate.Id
+		if obtainResp.ARIReplaced {
+			lastCertificate.IsRenewed = true
+		}
+		if _, err := ne.certificateRepo.Save(execCtx.Context(), lastCertificate); err != nil {
+			ne.logger.Warn("could not update last certificate", slog.String("recordId", lastCertificate.Id), slog.Any("error", err))
+		}
 	}
 
 	// 节点输出
@@ -138,6 +152,58 @@ func (ne *bizApplyNodeExecutor) Execute(execCtx *NodeExecutionContext) (*NodeExe
 	return execRes, nil
 }
 
+func (ne *bizApplyNodeExecutor) executeDryRun(execCtx *NodeExecutionContext, execRes *NodeExecutionResult, nodeCfg *domain.WorkflowNodeConfigForBizApply) (*NodeExecutionResult, error) {
+	execRes.AddVariableWithScope(execCtx.Node.Id, stateVarKeyNodeSkipped, false, stateValTypeBoolean)
+
+	// 优先向证书颁发机构的测试环境申请证书
+	obtainResp, err := ne.execObtainCertificate(execCtx, nodeCfg, nil)
+	if err == nil {
+		certificate := &domain.Certificate{}
+		certificate.PopulateFromPEM(obtainResp.FullChainCertificate, obtainResp.PrivateKey)
+		ne.setVariablesOfResult(execCtx, execRes, certificate)
+
+		execRes.AddVariableWithScope(execCtx.Node.Id, stateVarKeyNodeDryRunChecked, true, stateValTypeBoolean)
+		ne.logger.Info("dry run: certificate obtained from the staging environment, it will not be saved")
+		return execRes, nil
+	} else if !errors.Is(err, certacme.ErrStagingNotSupported) {
+		return execRes, err
+	}
+
+	// 证书颁发机构不提供测试环境时，仅校验质询提供商
+	ne.logger.Warn("dry run: the ca provider does not provide a staging environment, certificate issuance will NOT be tested and only the challenge provider will be checked")
+
+	providerAccessConfig := make(map[string]any)
+	if nodeCfg.ProviderAccessId != "" {
+		if access, err := ne.accessRepo.GetById(execCtx.Context(), nodeCfg.ProviderAccessId); err != nil {
+			return execRes, fmt.Errorf("failed to get access #%s record: %w", nodeCfg.ProviderAccessId, err)
+		} else {
+			providerAccessConfig = access.Config
+		}
+	}
+
+	checked, err := certacme.CheckChallengeProvider(execCtx.Context(), &certacme.ObtainCertificateRequest{
+		ChallengeType:          nodeCfg.ChallengeType,
+		Provider:               domain.ACMEChallengeProviderType(nodeCfg.Provider),
+		ProviderAccessConfig:   providerAccessConfig,
+		ProviderExtendedConfig: nodeCfg.ProviderConfig,
+		DnsPropagationTimeout:  nodeCfg.DnsPropagationTimeout,
+		DnsTTL:                 nodeCfg.DnsTTL,
+	})
+	if err != nil {
+		ne.logger.Warn("could not check challenge provider")
+		return execRes, err
+	}
+
+	// 不支持校验的质询提供商不视为通过，而是明确报告为未校验
+	execRes.AddVariableWithScope(execCtx.Node.Id, stateVarKeyNodeDryRunChecked, checked, stateValTypeBoolean)
+	if checked {
+		ne.logger.Info("dry run: challenge provider checked")
+	} else {
+		ne.logger.Warn(fmt.Sprintf("dry run: NOT CHECKED, the challenge provider '%s' does not support checking, only its configuration was validated", nodeCfg.Provider))
+	}
+	return execRes, nil
+}
+
 func (ne *bizApplyNodeExecutor) getLastOutputArtifacts(execCtx *NodeExecutionContext) (*domain.WorkflowOutput, *domain.Certificate, error) {
 	lastOutput, err := ne.wfoutputRepo.GetByWorkflowIdAndNodeId(execCtx.Context(), execCtx.WorkflowId, execCtx.Node.Id)
 	if err != nil && !domain.IsRecordNotFoundError(err) {
@@ -287,6 +353,7 @@ func (ne *bizApplyNodeExecutor) execObtainCertificate(execCtx *NodeExecutionCont
 		CAProviderAccessConfig:   caAccessConfig,
 		CAProviderExtendedConfig: nodeCfg.CAProviderConfig,
 		CertifierKeyAlgorithm:    keyAlgorithm,
+		UseStaging:               execCtx.DryRun,
 	}
 	acmeCfg, err := certacme.CreateACMEConfig(execCtx.Context(), acmeOpts)
 	if err != nil {
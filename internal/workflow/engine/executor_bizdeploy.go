@@ -1,10 +1,14 @@
 package engine
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
 	"maps"
+	"net"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/certimate-go/certimate/internal/certmgmt"
 	"github.com/certimate-go/certimate/internal/domain"
@@ -15,6 +19,9 @@ import (
  * Inputs:
  *   - ref: "certificate": string
  *
+ * Outputs:
+ *   - value: "attempts": number (only if retry policy is set)
+ *
  * Variables:
  *   - "node.skipped": boolean
  */
@@ -23,14 +30,20 @@ type bizDeployNodeExecutor struct {
 
 	accessRepo      accessRepository
 	certificateRepo certificateRepository
+	certdeployRepo  certificateDeploymentRepository
 	wfoutputRepo    workflowOutputRepository
 }
 
 func (ne *bizDeployNodeExecutor) Execute(execCtx *NodeExecutionContext) (*NodeExecutionResult, error) {
 	execRes := newNodeExecutionResult(execCtx.Node)
 
-	nodeCfg := execCtx.Node.Data.Config.AsBizDeploy()
-	ne.logger.Info("ready to deploy certificate ...", slog.Any("config", nodeCfg))
+	nodeCfg := execCtx.NodeConfig.AsBizDeploy()
+	ne.logger.Info("ready to deploy certificate ...", slog.Any("config", execCtx.Node.Data.Config.AsBizDeploy()))
+
+	// 试运行时仅预检部署目标，不会部署证书
+	if execCtx.DryRun {
+		return ne.executeDryRun(execCtx, execRes, &nodeCfg)
+	}
 
 	// 查询上次执行结果
 	lastOutput, err := ne.getLastOutputArtifacts(execCtx)
@@ -97,18 +110,274 @@ func (ne *bizDeployNodeExecutor) Execute(execCtx *NodeExecutionContext) (*NodeEx
 		CertificatePEM:         inputCertificate.Certificate,
 		PrivateKeyPEM:          inputCertificate.PrivateKey,
 	}
-	if _, err := deployer.DeployCertificate(execCtx.Context(), deployReq); err != nil {
+	attempts, err := ne.executeWithRetry(execCtx, func() error {
+		_, err := deployer.DeployCertificate(execCtx.Context(), deployReq)
+		return err
+	})
+	if err != nil {
 		ne.logger.Warn("could not deploy certificate")
 		return execRes, err
 	}
 
+	// 登记回滚动作
+	if nodeCfg.RollbackOnFailure {
+		ne.registerRollback(execCtx, inputCertificate, lastOutput)
+	}
+
+	// 验证部署结果
+	if nodeCfg.Verification != nil {
+		if err := ne.execVerifyDeployment(execCtx, nodeCfg.Verification, inputCertificate); err != nil {
+			ne.logger.Warn("could not verify deployment")
+			return execRes, err
+		}
+	}
+
+	// 保存部署记录，其中保存的是未解析外部密钥的原始配置
+	rawNodeCfg := execCtx.Node.Data.Config.AsBizDeploy()
+	if err := ne.saveDeployment(execCtx.Context(), execCtx.WorkflowId, execCtx.RunId, execCtx.Node.Id, &rawNodeCfg, inputCertificate); err != nil {
+		ne.logger.Warn("could not save deployment record", slog.Any("error", err))
+	}
+
 	// 节点输出
 	execRes.outputForced = true
+	ne.setOuputsOfResult(execCtx, execRes, attempts)
 
 	ne.logger.Info("deployment completed")
 	return execRes, nil
 }
 
+func (ne *bizDeployNodeExecutor) executeDryRun(execCtx *NodeExecutionContext, execRes *NodeExecutionResult, nodeCfg *domain.WorkflowNodeConfigForBizDeploy) (*NodeExecutionResult, error) {
+	execRes.AddVariableWithScope(execCtx.Node.Id, stateVarKeyNodeSkipped, false, stateValTypeBoolean)
+
+	// 读取部署提供商授权
+	providerAccessConfig := make(map[string]any)
+	if nodeCfg.ProviderAccessId != "" {
+		if access, err := ne.accessRepo.GetById(execCtx.Context(), nodeCfg.ProviderAccessId); err != nil {
+			return execRes, fmt.Errorf("failed to get access #%s record: %w", nodeCfg.ProviderAccessId, err)
+		} else {
+			providerAccessConfig = access.Config
+		}
+	}
+
+	// 预检部署
+	deployer := certmgmt.NewClient(certmgmt.WithLogger(ne.logger))
+	checkResp, err := deployer.CheckDeployment(execCtx.Context(), &certmgmt.CheckDeploymentRequest{
+		Provider:               domain.DeploymentProviderType(nodeCfg.Provider),
+		ProviderAccessConfig:   providerAccessConfig,
+		ProviderExtendedConfig: nodeCfg.ProviderConfig,
+	})
+	if err != nil {
+		ne.logger.Warn("could not check deployment")
+		return execRes, err
+	}
+
+	// 不支持预检的部署提供商不视为通过，而是明确报告为未校验
+	execRes.AddVariableWithScope(execCtx.Node.Id, stateVarKeyNodeDryRunChecked, checkResp.Checked, stateValTypeBoolean)
+	if checkResp.Checked {
+		ne.logger.Info("dry run: deployment target checked")
+	} else {
+		ne.logger.Warn(fmt.Sprintf("dry run: NOT CHECKED, the deployment provider '%s' does not support checking, only its configuration was validated", nodeCfg.Provider))
+	}
+	return execRes, nil
+}
+
+func (ne *bizDeployNodeExecutor) registerRollback(execCtx *NodeExecutionContext, inputCertificate *domain.Certificate, lastOutput *domain.WorkflowOutput) {
+	previousCertificateId, err := ne.findPreviousCertificateId(execCtx, lastOutput)
+	if err != nil {
+		ne.logger.Warn("could not find the previous certificate, rollback will be unavailable", slog.Any("error", err))
+		return
+	}
+	if previousCertificateId == inputCertificate.Id {
+		return
+	}
+
+	execCtx.rollbacks.Push(ne.RestoreRollback(&execCtx.WorkflowContext, execCtx.Node, map[string]any{
+		"certificateId": previousCertificateId,
+	}))
+
+	ne.logger.Info(fmt.Sprintf("rollback registered, the previous certificate #%s will be redeployed if the workflow run fails", previousCertificateId))
+}
+
+func (ne *bizDeployNodeExecutor) findPreviousCertificateId(execCtx *NodeExecutionContext, lastOutput *domain.WorkflowOutput) (string, error) {
+	// 优先从部署记录中查找部署目标当前持有的证书，即本次部署前的证书。
+	// 不依赖上次的节点输出，因此即使上次申请节点被跳过、或上次部署已被回滚，也能找到正确的证书。
+	nodeCfg := execCtx.Node.Data.Config.AsBizDeploy()
+	target := &domain.CertificateDeployment{
+		Provider:         nodeCfg.Provider,
+		ProviderAccessId: nodeCfg.ProviderAccessId,
+		ProviderConfig:   nodeCfg.ProviderConfig,
+	}
+	currentDeployment, err := ne.certdeployRepo.GetCurrentByTargetKey(execCtx.Context(), target.ComputeTargetKey())
+	if err == nil {
+		return currentDeployment.CertificateId, nil
+	} else if !domain.IsRecordNotFoundError(err) {
+		return "", fmt.Errorf("failed to get the current deployment: %w", err)
+	}
+
+	// 升级前部署的目标没有部署记录，此时从上次成功的节点输出中查找当次执行所部署的证书
+	if lastOutput == nil || !lastOutput.Succeeded {
+		return "", fmt.Errorf("no previous deployment or succeeded node output found")
+	}
+
+	lastNodeCfg := lastOutput.NodeConfig.AsBizDeploy()
+	lastTarget := &domain.CertificateDeployment{
+		Provider:         lastNodeCfg.Provider,
+		ProviderAccessId: lastNodeCfg.ProviderAccessId,
+		ProviderConfig:   lastNodeCfg.ProviderConfig,
+	}
+	if lastTarget.ComputeTargetKey() != target.ComputeTargetKey() {
+		return "", fmt.Errorf("the deployment target changed since the last run #%s", lastOutput.RunId)
+	}
+
+	lastCertificate, err := ne.certificateRepo.GetByWorkflowRunIdAndNodeId(execCtx.Context(), lastOutput.RunId, lastNodeCfg.CertificateOutputNodeId)
+	if err != nil {
+		return "", fmt.Errorf("failed to get the certificate deployed in the last run #%s: %w", lastOutput.RunId, err)
+	}
+
+	ne.logger.Info(fmt.Sprintf("no deployment record found, using the certificate #%s deployed in the last run #%s", lastCertificate.Id, lastOutput.RunId))
+	return lastCertificate.Id, nil
+}
+
+func (ne *bizDeployNodeExecutor) RestoreRollback(wfCtx *WorkflowContext, node *Node, data map[string]any) RollbackAction {
+	workflowId := wfCtx.WorkflowId
+	runId := wfCtx.RunId
+	previousCertificateId, _ := data["certificateId"].(string)
+
+	return RollbackAction{
+		Node: node,
+		Data: data,
+		Run: func(ctx context.Context, logger *slog.Logger) error {
+			nodeCfg := node.Data.Config.AsBizDeploy()
+			resolvedNodeConfig, err := resolveNodeConfig(ctx, node.Data.Config)
+			if err != nil {
+				return fmt.Errorf("failed to resolve secrets of node '%s': %w", node.Id, err)
+			}
+			resolvedNodeCfg := resolvedNodeConfig.AsBizDeploy()
+
+			previousCertificate, err := ne.certificateRepo.GetById(ctx, previousCertificateId)
+			if err != nil {
+				return fmt.Errorf("failed to get previous certificate #%s record: %w", previousCertificateId, err)
+			}
+
+			logger.Info(fmt.Sprintf("the workflow run failed, rolling back to the previous certificate #%s (serial='%s') ...", previousCertificate.Id, previousCertificate.SerialNumber))
+
+			providerAccessConfig := make(map[string]any)
+			if nodeCfg.ProviderAccessId != "" {
+				if access, err := ne.accessRepo.GetById(ctx, nodeCfg.ProviderAccessId); err != nil {
+					return fmt.Errorf("failed to get access #%s record: %w", nodeCfg.ProviderAccessId, err)
+				} else {
+					providerAccessConfig = access.Config
+				}
+			}
+
+			deployer := certmgmt.NewClient(certmgmt.WithLogger(logger))
+			if _, err := deployer.DeployCertificate(ctx, &certmgmt.DeployCertificateRequest{
+				Provider:               domain.DeploymentProviderType(nodeCfg.Provider),
+				ProviderAccessConfig:   providerAccessConfig,
+				ProviderExtendedConfig: resolvedNodeCfg.ProviderConfig,
+				CertificatePEM:         previousCertificate.Certificate,
+				PrivateKeyPEM:          previousCertificate.PrivateKey,
+			}); err != nil {
+				return err
+			}
+
+			if err := ne.saveDeployment(ctx, workflowId, runId, node.Id, &nodeCfg, previousCertificate); err != nil {
+				logger.Warn("could not save deployment record", slog.Any("error", err))
+			}
+
+			// 回滚后将本次输出标记为失败，以免下次执行时被跳过
+			output, err := ne.wfoutputRepo.GetByWorkflowIdAndNodeId(ctx, workflowId, node.Id)
+			if err == nil && output.RunId == runId {
+				output.Succeeded = false
+				if _, err := ne.wfoutputRepo.Save(ctx, output); err != nil {
+					logger.Warn("could not update node output", slog.Any("error", err))
+				}
+			}
+
+			logger.Info("rollback completed")
+			return nil
+		},
+	}
+}
+
+func (ne *bizDeployNodeExecutor) saveDeployment(ctx context.Context, workflowId, runId, nodeId string, nodeCfg *domain.WorkflowNodeConfigForBizDeploy, certificate *domain.Certificate) error {
+	deployment := &domain.CertificateDeployment{
+		CertificateId:    certificate.Id,
+		SerialNumber:     certificate.SerialNumber,
+		Provider:         nodeCfg.Provider,
+		ProviderAccessId: nodeCfg.ProviderAccessId,
+		ProviderConfig:   nodeCfg.ProviderConfig,
+		IsCurrent:        true,
+		WorkflowId:       workflowId,
+		WorkflowRunId:    runId,
+		WorkflowNodeId:   nodeId,
+	}
+	deployment.TargetKey = deployment.ComputeTargetKey()
+	_, err := ne.certdeployRepo.Save(ctx, deployment)
+	return err
+}
+
+func (ne *bizDeployNodeExecutor) execVerifyDeployment(execCtx *NodeExecutionContext, verification *domain.WorkflowNodeConfigForBizDeployVerification, certificate *domain.Certificate) error {
+	ne.logger.Info(fmt.Sprintf("verifying that %d target(s) serve the new certificate (serial='%s') ...", len(verification.Probes), certificate.SerialNumber))
+
+	ctx, cancel := context.WithTimeout(execCtx.Context(), time.Duration(verification.Timeout)*time.Second)
+	defer cancel()
+
+	// 轮询各探测目标，直至其提供的证书序列号与新证书一致，或超时
+	pendings := verification.Probes
+	for {
+		remains := make([]domain.WorkflowNodeConfigForBizDeployVerificationProbe, 0, len(pendings))
+		for _, probe := range pendings {
+			addr := net.JoinHostPort(probe.Host, strconv.Itoa(int(probe.Port)))
+			certs, err := retrieveServerCertificates(ctx, addr, probe.Domain, probe.RequestPath)
+			if err != nil {
+				ne.logger.Info(fmt.Sprintf("could not retrieve certificate at %s (domain: %s): %s", addr, probe.Domain, err.Error()))
+				remains = append(remains, probe)
+				continue
+			} else if len(certs) == 0 {
+				ne.logger.Info(fmt.Sprintf("no ssl certificates retrieved at %s (domain: %s)", addr, probe.Domain))
+				remains = append(remains, probe)
+				continue
+			}
+
+			servedSerial := strings.ToUpper(certs[0].SerialNumber.Text(16))
+			if strings.EqualFold(servedSerial, certificate.SerialNumber) {
+				ne.logger.Info(fmt.Sprintf("the new certificate is served at %s (domain: %s)", addr, probe.Domain))
+			} else {
+				ne.logger.Info(fmt.Sprintf("the old certificate (serial='%s') is still served at %s (domain: %s)", servedSerial, addr, probe.Domain))
+				remains = append(remains, probe)
+			}
+		}
+
+		pendings = remains
+		if len(pendings) == 0 {
+			ne.logger.Info("deployment verified")
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			if err := execCtx.Context().Err(); err != nil {
+				return err
+			}
+
+			targets := make([]string, 0, len(pendings))
+			for _, probe := range pendings {
+				targets = append(targets, fmt.Sprintf("%s:%d (domain: %s)", probe.Host, probe.Port, probe.Domain))
+			}
+			return fmt.Errorf("deployment verification timed out, the new certificate is still not served at: %s", strings.Join(targets, ", "))
+
+		case <-time.After(time.Duration(verification.Interval) * time.Second):
+		}
+	}
+}
+
+func (ne *bizDeployNodeExecutor) setOuputsOfResult(execCtx *NodeExecutionContext, execRes *NodeExecutionResult, attempts int) {
+	if execCtx.Node.Data.Retry != nil {
+		execRes.AddOutputWithPersistent(stateIOTypeValue, "attempts", attempts, stateValTypeNumber)
+	}
+}
+
 func (ne *bizDeployNodeExecutor) getLastOutputArtifacts(execCtx *NodeExecutionContext) (*domain.WorkflowOutput, error) {
 	lastOutput, err := ne.wfoutputRepo.GetByWorkflowIdAndNodeId(execCtx.Context(), execCtx.WorkflowId, execCtx.Node.Id)
 	if err != nil && !domain.IsRecordNotFoundError(err) {
@@ -145,6 +414,7 @@ func newBizDeployNodeExecutor() NodeExecutor {
 		nodeExecutor:    nodeExecutor{logger: slog.Default()},
 		accessRepo:      repository.NewAccessRepository(),
 		certificateRepo: repository.NewCertificateRepository(),
+		certdeployRepo:  repository.NewCertificateDeploymentRepository(),
 		wfoutputRepo:    repository.NewWorkflowOutputRepository(),
 	}
 }
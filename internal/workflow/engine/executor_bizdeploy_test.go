@@ -0,0 +1,276 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/certimate-go/certimate/internal/domain"
+)
+
+type deployMockCertificateRepository struct {
+	certificates map[string]*domain.Certificate
+}
+
+func (r *deployMockCertificateRepository) GetById(ctx context.Context, id string) (*domain.Certificate, error) {
+	if certificate, ok := r.certificates[id]; ok {
+		return certificate, nil
+	}
+	return nil, domain.ErrRecordNotFound
+}
+
+func (r *deployMockCertificateRepository) GetByWorkflowRunIdAndNodeId(ctx context.Context, workflowRunId string, workflowNodeId string) (*domain.Certificate, error) {
+	for _, certificate := range r.certificates {
+		if certificate.WorkflowRunId == workflowRunId && certificate.WorkflowNodeId == workflowNodeId {
+			return certificate, nil
+		}
+	}
+	return nil, domain.ErrRecordNotFound
+}
+
+func (r *deployMockCertificateRepository) Save(ctx context.Context, certificate *domain.Certificate) (*domain.Certificate, error) {
+	r.certificates[certificate.Id] = certificate
+	return certificate, nil
+}
+
+// 与实际的存储实现一致，保存当前部署记录时会将同一部署目标的其他记录标记为非当前。
+type deployMockCertificateDeploymentRepository struct {
+	mtx         sync.Mutex
+	certdeploys []*domain.CertificateDeployment
+}
+
+func (r *deployMockCertificateDeploymentRepository) GetCurrentByTargetKey(ctx context.Context, targetKey string) (*domain.CertificateDeployment, error) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	for _, certdeploy := range r.certdeploys {
+		if certdeploy.TargetKey == targetKey && certdeploy.IsCurrent {
+			return certdeploy, nil
+		}
+	}
+	return nil, domain.ErrRecordNotFound
+}
+
+func (r *deployMockCertificateDeploymentRepository) Save(ctx context.Context, certificateDeployment *domain.CertificateDeployment) (*domain.CertificateDeployment, error) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	if certificateDeployment.IsCurrent {
+		for _, certdeploy := range r.certdeploys {
+			if certdeploy.TargetKey == certificateDeployment.TargetKey {
+				certdeploy.IsCurrent = false
+			}
+		}
+	}
+
+	certificateDeployment.Id = fmt.Sprintf("deployment_%d", len(r.certdeploys)+1)
+	r.certdeploys = append(r.certdeploys, certificateDeployment)
+	return certificateDeployment, nil
+}
+
+type deployMockWorkflowOutputRepository struct {
+	mtx     sync.Mutex
+	outputs map[string]*domain.WorkflowOutput
+}
+
+func (r *deployMockWorkflowOutputRepository) GetByWorkflowIdAndNodeId(ctx context.Context, workflowId string, workflowNodeId string) (*domain.WorkflowOutput, error) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	if output, ok := r.outputs[workflowNodeId]; ok {
+		return output, nil
+	}
+	return nil, domain.ErrRecordNotFound
+}
+
+func (r *deployMockWorkflowOutputRepository) Save(ctx context.Context, workflowOutput *domain.WorkflowOutput) (*domain.WorkflowOutput, error) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	workflowOutput.UpdatedAt = time.Now()
+	r.outputs[workflowOutput.NodeId] = workflowOutput
+	return workflowOutput, nil
+}
+
+func TestBizDeploySavesDeployments(t *testing.T) {
+	oldCertPEM, oldKeyPEM := newDryRunTestCertificate(t)
+	newCertPEM, newKeyPEM := newDryRunTestCertificate(t)
+	oldCertificate := (&domain.Certificate{Meta: domain.Meta{Id: "cert_old"}, WorkflowRunId: "run_prev", WorkflowNodeId: "upload"}).PopulateFromPEM(oldCertPEM, oldKeyPEM)
+	newCertificate := (&domain.Certificate{Meta: domain.Meta{Id: "cert_new", CreatedAt: time.Now()}}).PopulateFromPEM(newCertPEM, newKeyPEM)
+
+	newTestEngine := func(outputDir string, failAfterDeploy bool) (*workflowEngine, *deployMockCertificateDeploymentRepository, *deployMockWorkflowOutputRepository, *Graph, *domain.CertificateDeployment) {
+		providerConfig := map[string]any{
+			"filePathForCrt": filepath.Join(outputDir, "cert.pem"),
+			"filePathForKey": filepath.Join(outputDir, "key.pem"),
+		}
+
+		// 部署目标当前持有旧证书
+		previous := &domain.CertificateDeployment{
+			CertificateId:  oldCertificate.Id,
+			SerialNumber:   oldCertificate.SerialNumber,
+			Provider:       string(domain.DeploymentProviderTypeLocal),
+			ProviderConfig: providerConfig,
+			IsCurrent:      true,
+		}
+		previous.TargetKey = previous.ComputeTargetKey()
+
+		certificateRepo := &deployMockCertificateRepository{certificates: map[string]*domain.Certificate{oldCertificate.Id: oldCertificate, newCertificate.Id: newCertificate}}
+		certdeployRepo := &deployMockCertificateDeploymentRepository{certdeploys: []*domain.CertificateDeployment{previous}}
+		wfoutputRepo := &deployMockWorkflowOutputRepository{outputs: make(map[string]*domain.WorkflowOutput)}
+
+		engine := &workflowEngine{
+			executors:    make(map[NodeType]func() NodeExecutor),
+			wfoutputRepo: wfoutputRepo,
+			syslog:       slog.Default(),
+		}
+		engine.executors[NodeTypeStart] = newStartNodeExecutor
+		engine.executors[NodeTypeEnd] = newEndNodeExecutor
+		engine.executors[NodeTypeBizUpload] = func() NodeExecutor {
+			return &testNodeExecutor{nodeExecutor: nodeExecutor{logger: slog.Default()}, execute: func(execCtx *NodeExecutionContext) (*NodeExecutionResult, error) {
+				execRes := newNodeExecutionResult(execCtx.Node)
+				execRes.AddOutput(stateIOTypeRef, "certificate", fmt.Sprintf("%s#%s", domain.CollectionNameCertificate, newCertificate.Id), stateValTypeString)
+				return execRes, nil
+			}}
+		}
+		engine.executors[NodeTypeBizDeploy] = func() NodeExecutor {
+			return &bizDeployNodeExecutor{nodeExecutor: nodeExecutor{logger: slog.Default()}, certificateRepo: certificateRepo, certdeployRepo: certdeployRepo, wfoutputRepo: wfoutputRepo}
+		}
+		engine.executors[NodeTypeBizNotify] = func() NodeExecutor {
+			return &testNodeExecutor{nodeExecutor: nodeExecutor{logger: slog.Default()}, execute: func(execCtx *NodeExecutionContext) (*NodeExecutionResult, error) {
+				return newNodeExecutionResult(execCtx.Node), errors.New("something went wrong")
+			}}
+		}
+
+		nodes := []*Node{
+			{Id: "start", Type: NodeTypeStart, Data: domain.WorkflowNodeData{Name: "start"}},
+			{Id: "upload", Type: NodeTypeBizUpload, Data: domain.WorkflowNodeData{Name: "upload"}},
+			{
+				Id:   "deploy",
+				Type: NodeTypeBizDeploy,
+				Data: domain.WorkflowNodeData{
+					Name: "deploy",
+					Config: domain.WorkflowNodeConfig{
+						"certificateOutputNodeId": "upload",
+						"provider":                string(domain.DeploymentProviderTypeLocal),
+						"providerConfig":          providerConfig,
+						"rollbackOnFailure":       true,
+					},
+				},
+			},
+		}
+		if failAfterDeploy {
+			nodes = append(nodes, &Node{Id: "notify", Type: NodeTypeBizNotify, Data: domain.WorkflowNodeData{Name: "notify"}})
+		}
+		nodes = append(nodes, &Node{Id: "end", Type: NodeTypeEnd, Data: domain.WorkflowNodeData{Name: "end"}})
+
+		return engine, certdeployRepo, wfoutputRepo, &Graph{Nodes: nodes}, previous
+	}
+
+	assertDeployment := func(t *testing.T, certdeploy *domain.CertificateDeployment, certificate *domain.Certificate, targetKey string, isCurrent bool) {
+		t.Helper()
+
+		if certdeploy.CertificateId != certificate.Id || certdeploy.SerialNumber != certificate.SerialNumber {
+			t.Errorf("expected the deployment of certificate '%s', got '%s'", certificate.Id, certdeploy.CertificateId)
+		}
+		if certdeploy.Provider != string(domain.DeploymentProviderTypeLocal) || certdeploy.TargetKey != targetKey {
+			t.Errorf("expected the deployment to the same target, got provider '%s' and target key '%s'", certdeploy.Provider, certdeploy.TargetKey)
+		}
+		if certdeploy.WorkflowId != "wf" || certdeploy.WorkflowRunId != "run" || certdeploy.WorkflowNodeId != "deploy" {
+			t.Errorf("expected the deployment to refer to the workflow run, got %s/%s/%s", certdeploy.WorkflowId, certdeploy.WorkflowRunId, certdeploy.WorkflowNodeId)
+		}
+		if certdeploy.IsCurrent != isCurrent {
+			t.Errorf("expected the deployment of certificate '%s' to be current: %v, got %v", certificate.Id, isCurrent, certdeploy.IsCurrent)
+		}
+	}
+
+	assertDeployedFile := func(t *testing.T, outputDir string, certPEM string) {
+		t.Helper()
+
+		data, err := os.ReadFile(filepath.Join(outputDir, "cert.pem"))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if strings.TrimSpace(string(data)) != strings.TrimSpace(certPEM) {
+			t.Errorf("expected the certificate file to be %s", certPEM)
+		}
+	}
+
+	t.Run("Save deployment", func(t *testing.T) {
+		outputDir := t.TempDir()
+		engine, certdeployRepo, _, graph, previous := newTestEngine(outputDir, false)
+
+		err := engine.Invoke(context.Background(), WorkflowExecution{WorkflowId: "wf", RunId: "run", Graph: graph})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if len(certdeployRepo.certdeploys) != 2 {
+			t.Fatalf("expected 1 deployment to be saved, got %d", len(certdeployRepo.certdeploys)-1)
+		}
+		assertDeployment(t, certdeployRepo.certdeploys[1], newCertificate, previous.TargetKey, true)
+		if previous.IsCurrent {
+			t.Error("expected the previous deployment not to be current")
+		}
+		assertDeployedFile(t, outputDir, newCertPEM)
+	})
+
+	t.Run("Save deployment on rollback", func(t *testing.T) {
+		outputDir := t.TempDir()
+		engine, certdeployRepo, wfoutputRepo, graph, previous := newTestEngine(outputDir, true)
+
+		err := engine.Invoke(context.Background(), WorkflowExecution{WorkflowId: "wf", RunId: "run", Graph: graph})
+		if err == nil {
+			t.Fatal("expected error, got nil")
+		}
+
+		// 先记录新证书的部署，回滚后再记录旧证书的部署，并以旧证书为部署目标当前持有的证书
+		if len(certdeployRepo.certdeploys) != 3 {
+			t.Fatalf("expected 2 deployments to be saved, got %d", len(certdeployRepo.certdeploys)-1)
+		}
+		assertDeployment(t, certdeployRepo.certdeploys[1], newCertificate, previous.TargetKey, false)
+		assertDeployment(t, certdeployRepo.certdeploys[2], oldCertificate, previous.TargetKey, true)
+		if previous.IsCurrent {
+			t.Error("expected the original deployment not to be current")
+		}
+		assertDeployedFile(t, outputDir, oldCertPEM)
+
+		if output, ok := wfoutputRepo.outputs["deploy"]; !ok || output.Succeeded {
+			t.Error("expected the output of the rolled back node to be marked as failed")
+		}
+	})
+
+	t.Run("Rollback without deployment records", func(t *testing.T) {
+		outputDir := t.TempDir()
+		engine, certdeployRepo, wfoutputRepo, graph, previous := newTestEngine(outputDir, true)
+
+		// 升级前部署的目标没有部署记录，只有上次成功执行的节点输出
+		certdeployRepo.certdeploys = nil
+		wfoutputRepo.outputs["deploy"] = &domain.WorkflowOutput{
+			Meta:       domain.Meta{Id: "output_prev", UpdatedAt: time.Now().Add(-time.Hour)},
+			WorkflowId: "wf",
+			RunId:      "run_prev",
+			NodeId:     "deploy",
+			NodeConfig: graph.Nodes[2].Data.Config,
+			Succeeded:  true,
+		}
+
+		err := engine.Invoke(context.Background(), WorkflowExecution{WorkflowId: "wf", RunId: "run", Graph: graph})
+		if err == nil {
+			t.Fatal("expected error, got nil")
+		}
+
+		if len(certdeployRepo.certdeploys) != 2 {
+			t.Fatalf("expected 2 deployments to be saved, got %d", len(certdeployRepo.certdeploys))
+		}
+		assertDeployment(t, certdeployRepo.certdeploys[0], newCertificate, previous.TargetKey, false)
+		assertDeployment(t, certdeployRepo.certdeploys[1], oldCertificate, previous.TargetKey, true)
+		assertDeployedFile(t, outputDir, oldCertPEM)
+	})
+}
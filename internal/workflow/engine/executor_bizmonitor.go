@@ -1,6 +1,7 @@
 package engine
 
 import (
+	"context"
 	"crypto/x509"
 	"fmt"
 	"log/slog"
@@ -37,8 +38,8 @@ type bizMonitorNodeExecutor struct {
 func (ne *bizMonitorNodeExecutor) Execute(execCtx *NodeExecutionContext) (*NodeExecutionResult, error) {
 	execRes := newNodeExecutionResult(execCtx.Node)
 
-	nodeCfg := execCtx.Node.Data.Config.AsBizMonitor()
-	ne.logger.Info("ready to monitor certificate ...", slog.Any("config", nodeCfg))
+	nodeCfg := execCtx.NodeConfig.AsBizMonitor()
+	ne.logger.Info("ready to monitor certificate ...", slog.Any("config", execCtx.Node.Data.Config.AsBizMonitor()))
 
 	targetAddr := net.JoinHostPort(nodeCfg.Host, strconv.Itoa(int(nodeCfg.Port)))
 	if nodeCfg.Port == 0 {
@@ -120,6 +121,18 @@ func (ne *bizMonitorNodeExecutor) Execute(execCtx *NodeExecutionContext) (*NodeE
 }
 
 func (ne *bizMonitorNodeExecutor) execRetrieveCertificates(execCtx *NodeExecutionContext, addr, domain, requestPath string) ([]*x509.Certificate, error) {
+	certs, err := retrieveServerCertificates(execCtx.Context(), addr, domain, requestPath)
+	if err != nil {
+		ne.logger.Warn(err.Error())
+		return nil, err
+	}
+
+	return certs, nil
+}
+
+// 向目标地址发起 HTTPS 请求，并返回服务端提供的证书链。
+// 证书链中的第一个证书即为服务器证书。
+func retrieveServerCertificates(ctx context.Context, addr, domain, requestPath string) ([]*x509.Certificate, error) {
 	transport := xhttp.NewDefaultTransport()
 	transport.DisableKeepAlives = true
 	transport.TLSClientConfig = xtls.NewInsecureConfig()
@@ -134,20 +147,16 @@ func (ne *bizMonitorNodeExecutor) execRetrieveCertificates(execCtx *NodeExecutio
 	}
 
 	url := fmt.Sprintf("https://%s/%s", addr, strings.TrimPrefix(requestPath, "/"))
-	req, err := http.NewRequestWithContext(execCtx.Context(), http.MethodHead, url, nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
 	if err != nil {
-		err = fmt.Errorf("failed to create http request: %w", err)
-		ne.logger.Warn(err.Error())
-		return nil, err
+		return nil, fmt.Errorf("failed to create http request: %w", err)
 	}
 
 	req.Header.Set("Host", domain)
 	req.Header.Set("User-Agent", app.AppUserAgent)
 	resp, err := client.Do(req)
 	if err != nil {
-		err = fmt.Errorf("failed to send http request: %w", err)
-		ne.logger.Warn(err.Error())
-		return nil, err
+		return nil, fmt.Errorf("failed to send http request: %w", err)
 	}
 	defer resp.Body.Close()
 
@@ -7,11 +7,17 @@ import (
 	"strings"
 	"time"
 
+	"github.com/samber/lo"
+
 	"github.com/certimate-go/certimate/internal/domain"
 	"github.com/certimate-go/certimate/internal/notify"
 	"github.com/certimate-go/certimate/internal/repository"
 )
 
+/**
+ * Outputs:
+ *   - value: "attempts": number (only if retry policy is set)
+ */
 type bizNotifyNodeExecutor struct {
 	nodeExecutor
 
@@ -21,8 +27,8 @@ type bizNotifyNodeExecutor struct {
 func (ne *bizNotifyNodeExecutor) Execute(execCtx *NodeExecutionContext) (*NodeExecutionResult, error) {
 	execRes := newNodeExecutionResult(execCtx.Node)
 
-	nodeCfg := execCtx.Node.Data.Config.AsBizNotify()
-	ne.logger.Info("ready to send notification ...", slog.Any("config", nodeCfg))
+	nodeCfg := execCtx.NodeConfig.AsBizNotify()
+	ne.logger.Info("ready to send notification ...", slog.Any("config", execCtx.Node.Data.Config.AsBizNotify()))
 
 	// 检测是否可以跳过本次执行
 	if skippable, reason := ne.checkCanSkip(execCtx); skippable {
@@ -41,29 +47,23 @@ func (ne *bizNotifyNodeExecutor) Execute(execCtx *NodeExecutionContext) (*NodeEx
 	}
 
 	// 渲染通知模板
-	reMustache := regexp.MustCompile(`\{\{\s*(\$[^\s]+)\s*\}\}`)
-	reMustacheReplacer := func(match string) string {
-		mustache := strings.TrimSpace(match[2 : len(match)-2])
-		if mustache == "" {
-			return match
-		}
-
-		key := mustache[1:]
-		if key == "" {
-			return match
-		} else if key == "now" {
-			return time.Now().Format(time.RFC3339)
-		}
-
-		// TODO: 支持作用域变量
-		if state, ok := execCtx.variables.Get(key); ok {
-			return state.ValueString()
-		}
+	tmplData := buildNotifyTemplateData(execCtx)
+	subject, err := notify.RenderTemplate(nodeCfg.Subject, notify.MessageFormatPlain, tmplData)
+	if err != nil {
+		ne.logger.Warn("failed to render subject template, fallback to legacy mode", slog.String("error", err.Error()))
+		subject = ne.renderLegacyTemplate(execCtx, nodeCfg.Subject)
+	}
+	message, err := notify.RenderTemplate(nodeCfg.Message, notify.GetMessageFormat(domain.NotificationProviderType(nodeCfg.Provider), nodeCfg.ProviderConfig), tmplData)
+	if err != nil {
+		ne.logger.Warn("failed to render message template, fallback to legacy mode", slog.String("error", err.Error()))
+		message = ne.renderLegacyTemplate(execCtx, nodeCfg.Message)
+	}
 
-		return match
+	// 试运行时仅渲染通知内容，不会推送
+	if execCtx.DryRun {
+		ne.logger.Info("dry run: notification rendered, it will not be sent", slog.String("subject", subject), slog.String("message", message))
+		return execRes, nil
 	}
-	subject := reMustache.ReplaceAllStringFunc(nodeCfg.Subject, reMustacheReplacer)
-	message := reMustache.ReplaceAllStringFunc(nodeCfg.Message, reMustacheReplacer)
 
 	// 推送通知
 	notifier := notify.NewClient(notify.WithLogger(ne.logger))
@@ -74,11 +74,20 @@ func (ne *bizNotifyNodeExecutor) Execute(execCtx *NodeExecutionContext) (*NodeEx
 		Subject:                subject,
 		Message:                message,
 	}
-	if _, err := notifier.SendNotification(execCtx.Context(), notifyReq); err != nil {
+	attempts, err := ne.executeWithRetry(execCtx, func() error {
+		_, err := notifier.SendNotification(execCtx.Context(), notifyReq)
+		return err
+	})
+	if err != nil {
 		ne.logger.Warn("could not send notification")
 		return execRes, err
 	}
 
+	// 节点输出
+	if execCtx.Node.Data.Retry != nil {
+		execRes.AddOutputWithPersistent(stateIOTypeValue, "attempts", attempts, stateValTypeNumber)
+	}
+
 	ne.logger.Info("notification completed")
 	return execRes, nil
 }
@@ -105,6 +114,93 @@ func (ne *bizNotifyNodeExecutor) checkCanSkip(execCtx *NodeExecutionContext) (_s
 	return true, "all the previous nodes have been skipped"
 }
 
+func buildNotifyTemplateData(execCtx *NodeExecutionContext) map[string]any {
+	data := make(map[string]any)
+	nodes := make(map[string]any)
+	certificates := make([]map[string]any, 0)
+	certificatesIndex := make(map[string]int)
+
+	for _, state := range execCtx.variables.All() {
+		if state.Scope == "" {
+			setTemplateDataValue(data, state.Key, state.Value)
+			continue
+		}
+
+		node, ok := nodes[state.Scope].(map[string]any)
+		if !ok {
+			node = make(map[string]any)
+			nodes[state.Scope] = node
+		}
+		setTemplateDataValue(node, state.Key, state.Value)
+
+		// 收集本次运行中各节点处理过的证书，以便在模板中遍历
+		if key, ok := strings.CutPrefix(state.Key, "certificate."); ok {
+			index, ok := certificatesIndex[state.Scope]
+			if !ok {
+				index = len(certificates)
+				certificatesIndex[state.Scope] = index
+				certificates = append(certificates, map[string]any{"nodeId": state.Scope})
+			}
+			certificates[index][key] = state.Value
+		}
+	}
+
+	data["nodes"] = nodes
+	data["certificates"] = lo.Filter(certificates, func(certificate map[string]any, _ int) bool {
+		if nodeName, ok := execCtx.variables.GetScoped(certificate["nodeId"].(string), stateVarKeyNodeName); ok {
+			certificate["nodeName"] = nodeName.Value
+		}
+		return certificate["commonName"] != nil && certificate["commonName"] != ""
+	})
+	data["now"] = time.Now()
+
+	return data
+}
+
+func (ne *bizNotifyNodeExecutor) renderLegacyTemplate(execCtx *NodeExecutionContext, tmpl string) string {
+	reMustache := regexp.MustCompile(`\{\{\s*(\$[^\s]+)\s*\}\}`)
+	reMustacheReplacer := func(match string) string {
+		mustache := strings.TrimSpace(match[2 : len(match)-2])
+		if mustache == "" {
+			return match
+		}
+
+		key := mustache[1:]
+		if key == "" {
+			return match
+		} else if key == "now" {
+			return time.Now().Format(time.RFC3339)
+		}
+
+		if state, ok := execCtx.variables.Get(key); ok {
+			return state.ValueString()
+		}
+
+		return match
+	}
+
+	return reMustache.ReplaceAllStringFunc(tmpl, reMustacheReplacer)
+}
+
+func setTemplateDataValue(data map[string]any, key string, value any) {
+	// 形如 "certificate.daysLeft" 的键将被展开为嵌套结构，以便在模板中使用 `$certificate.daysLeft` 访问
+	parts := strings.Split(key, ".")
+	for _, part := range parts[:len(parts)-1] {
+		child, ok := data[part].(map[string]any)
+		if !ok {
+			if _, exists := data[part]; exists {
+				return
+			}
+
+			child = make(map[string]any)
+			data[part] = child
+		}
+		data = child
+	}
+
+	data[parts[len(parts)-1]] = value
+}
+
 func newBizNotifyNodeExecutor() NodeExecutor {
 	return &bizNotifyNodeExecutor{
 		nodeExecutor: nodeExecutor{logger: slog.Default()},
@@ -49,8 +49,8 @@ const (
 func (ne *bizUploadNodeExecutor) Execute(execCtx *NodeExecutionContext) (*NodeExecutionResult, error) {
 	execRes := newNodeExecutionResult(execCtx.Node)
 
-	nodeCfg := execCtx.Node.Data.Config.AsBizUpload()
-	ne.logger.Info("ready to upload certiticate ...", slog.Any("config", nodeCfg))
+	nodeCfg := execCtx.NodeConfig.AsBizUpload()
+	ne.logger.Info("ready to upload certiticate ...", slog.Any("config", execCtx.Node.Data.Config.AsBizUpload()))
 
 	// 查询上次执行结果
 	lastOutput, lastCertificate, err := ne.getLastOutputArtifacts(execCtx)
@@ -173,6 +173,16 @@ func (ne *bizUploadNodeExecutor) Execute(execCtx *NodeExecutionContext) (*NodeEx
 		}
 	}
 
+	// 试运行时仅校验证书及私钥，不会保存证书实体
+	if execCtx.DryRun {
+		certificate := &domain.Certificate{}
+		certificate.PopulateFromPEM(certPEM, privkeyPEM)
+		ne.setVariablesOfResult(execCtx, execRes, certificate)
+
+		ne.logger.Info("dry run: certificate validated, it will not be saved")
+		return execRes, nil
+	}
+
 	// 保存证书实体
 	certificate := &domain.Certificate{
 		Source:         domain.CertificateSourceTypeUpload,
@@ -180,6 +190,9 @@ func (ne *bizUploadNodeExecutor) Execute(execCtx *NodeExecutionContext) (*NodeEx
 		WorkflowRunId:  execCtx.RunId,
 		WorkflowNodeId: execCtx.Node.Id,
 	}
+	if lastCertificate != nil {
+		certificate.PredecessorId = lastCertificate.Id
+	}
 	certificate.PopulateFromPEM(certPEM, privkeyPEM)
 	if certificate, err := ne.certificateRepo.Save(execCtx.Context(), certificate); err != nil {
 		ne.logger.Warn("could not save certificate")
@@ -188,6 +201,14 @@ func (ne *bizUploadNodeExecutor) Execute(execCtx *NodeExecutionContext) (*NodeEx
 		ne.logger.Info("certificate saved", slog.String("recordId", certificate.Id))
 	}
 
+	// 保存证书替换关系
+	if lastCertificate != nil {
+		lastCertificate.ReplacedById = certificate.Id
+		if _, err := ne.certificateRepo.Save(execCtx.Context(), lastCertificate); err != nil {
+			ne.logger.Warn("could not update last certificate", slog.String("recordId", lastCertificate.Id), slog.Any("error", err))
+		}
+	}
+
 	// 节点输出
 	ne.setOuputsOfResult(execCtx, execRes, certificate, true)
 	ne.setVariablesOfResult(execCtx, execRes, certificate)
@@ -34,7 +34,7 @@ func (ne *conditionNodeExecutor) Execute(execCtx *NodeExecutionContext) (*NodeEx
 
 		err := engine.executeNode(execCtx.Clone(), node)
 		if err != nil {
-			if errors.Is(err, ErrTerminated) {
+			if errors.Is(err, ErrTerminated) || errors.Is(err, ErrSuspended) {
 				return execRes, err
 			}
 			errs = append(errs, err)
@@ -70,8 +70,8 @@ func (ne *branchBlockNodeExecutor) Execute(execCtx *NodeExecutionContext) (*Node
 				acc[state.Scope] = make(map[string]any)
 			}
 
-			// 这里需要把所有值都转换为字符串形式，因为 Expression.Eval 仅支持字符串类型的值
-			acc[state.Scope][state.Key] = state.ValueString()
+			// 这里直接传递原始值，Expression.Eval 会根据值类型自动推断
+			acc[state.Scope][state.Key] = state.Value
 			return acc
 		}, make(map[string]map[string]any))
 
@@ -0,0 +1,209 @@
+package engine
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/certimate-go/certimate/internal/domain"
+)
+
+type dryRunMockRepository struct {
+	t      *testing.T
+	access map[string]*domain.Access
+	saves  atomic.Int32
+}
+
+func (r *dryRunMockRepository) GetById(ctx context.Context, id string) (*domain.Access, error) {
+	if access, ok := r.access[id]; ok {
+		return access, nil
+	}
+	return nil, domain.ErrRecordNotFound
+}
+
+type dryRunMockCertificateRepository struct{ *dryRunMockRepository }
+
+func (r *dryRunMockCertificateRepository) GetById(ctx context.Context, id string) (*domain.Certificate, error) {
+	return nil, domain.ErrRecordNotFound
+}
+
+func (r *dryRunMockCertificateRepository) GetByWorkflowRunIdAndNodeId(ctx context.Context, workflowRunId string, workflowNodeId string) (*domain.Certificate, error) {
+	return nil, domain.ErrRecordNotFound
+}
+
+func (r *dryRunMockCertificateRepository) Save(ctx context.Context, certificate *domain.Certificate) (*domain.Certificate, error) {
+	r.saves.Add(1)
+	r.t.Errorf("expected no certificate to be saved in a dry run")
+	return certificate, nil
+}
+
+type dryRunMockCertificateDeploymentRepository struct{ *dryRunMockRepository }
+
+func (r *dryRunMockCertificateDeploymentRepository) GetCurrentByTargetKey(ctx context.Context, targetKey string) (*domain.CertificateDeployment, error) {
+	return nil, domain.ErrRecordNotFound
+}
+
+func (r *dryRunMockCertificateDeploymentRepository) Save(ctx context.Context, certificateDeployment *domain.CertificateDeployment) (*domain.CertificateDeployment, error) {
+	r.saves.Add(1)
+	r.t.Errorf("expected no deployment record to be saved in a dry run")
+	return certificateDeployment, nil
+}
+
+type dryRunMockWorkflowOutputRepository struct{ *dryRunMockRepository }
+
+func (r *dryRunMockWorkflowOutputRepository) GetByWorkflowIdAndNodeId(ctx context.Context, workflowId string, workflowNodeId string) (*domain.WorkflowOutput, error) {
+	return nil, domain.ErrRecordNotFound
+}
+
+func (r *dryRunMockWorkflowOutputRepository) Save(ctx context.Context, workflowOutput *domain.WorkflowOutput) (*domain.WorkflowOutput, error) {
+	r.saves.Add(1)
+	r.t.Errorf("expected no node output to be saved in a dry run")
+	return workflowOutput, nil
+}
+
+func newDryRunTestCertificate(t *testing.T) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "example.com"},
+		DNSNames:     []string{"example.com"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(24 * time.Hour),
+	}
+	certDER, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDER})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	return string(certPEM), string(keyPEM)
+}
+
+func TestDryRunPersistsNothing(t *testing.T) {
+	var webhookCalls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		webhookCalls.Add(1)
+	}))
+	defer server.Close()
+
+	repo := &dryRunMockRepository{
+		t: t,
+		access: map[string]*domain.Access{
+			"webhook": {Meta: domain.Meta{Id: "webhook"}, Provider: string(domain.AccessProviderTypeWebhook), Reserve: "notif", Config: map[string]any{"url": server.URL}},
+		},
+	}
+	certificateRepo := &dryRunMockCertificateRepository{repo}
+	certdeployRepo := &dryRunMockCertificateDeploymentRepository{repo}
+	wfoutputRepo := &dryRunMockWorkflowOutputRepository{repo}
+
+	engine := &workflowEngine{
+		executors:    make(map[NodeType]func() NodeExecutor),
+		wfoutputRepo: wfoutputRepo,
+		syslog:       slog.Default(),
+	}
+	engine.executors[NodeTypeStart] = newStartNodeExecutor
+	engine.executors[NodeTypeEnd] = newEndNodeExecutor
+	engine.executors[NodeTypeBizUpload] = func() NodeExecutor {
+		return &bizUploadNodeExecutor{nodeExecutor: nodeExecutor{logger: slog.Default()}, certificateRepo: certificateRepo, wfoutputRepo: wfoutputRepo}
+	}
+	engine.executors[NodeTypeBizDeploy] = func() NodeExecutor {
+		return &bizDeployNodeExecutor{nodeExecutor: nodeExecutor{logger: slog.Default()}, accessRepo: repo, certificateRepo: certificateRepo, certdeployRepo: certdeployRepo, wfoutputRepo: wfoutputRepo}
+	}
+	engine.executors[NodeTypeBizNotify] = func() NodeExecutor {
+		return &bizNotifyNodeExecutor{nodeExecutor: nodeExecutor{logger: slog.Default()}, accessRepo: repo}
+	}
+
+	var deployChecked atomic.Value
+	engine.OnNodeEnd(func(ctx context.Context, node *Node, res *NodeExecutionResult) error {
+		if node.Id == "deploy" {
+			vars := &variableManager{states: res.Variables}
+			if state, ok := vars.GetScoped(node.Id, stateVarKeyNodeDryRunChecked); ok {
+				deployChecked.Store(state.Value)
+			}
+		}
+		return nil
+	})
+
+	certPEM, keyPEM := newDryRunTestCertificate(t)
+	outputDir := t.TempDir()
+	graph := &Graph{
+		Nodes: []*Node{
+			{Id: "start", Type: NodeTypeStart, Data: domain.WorkflowNodeData{Name: "start"}},
+			{
+				Id:   "upload",
+				Type: NodeTypeBizUpload,
+				Data: domain.WorkflowNodeData{
+					Name:   "upload",
+					Config: domain.WorkflowNodeConfig{"source": BizUploadSourceForm, "certificate": certPEM, "privateKey": keyPEM},
+				},
+			},
+			{
+				Id:   "deploy",
+				Type: NodeTypeBizDeploy,
+				Data: domain.WorkflowNodeData{
+					Name: "deploy",
+					Config: domain.WorkflowNodeConfig{
+						"certificateOutputNodeId": "upload",
+						"provider":                string(domain.DeploymentProviderTypeLocal),
+						"providerConfig": map[string]any{
+							"filePathForCrt": filepath.Join(outputDir, "cert.pem"),
+							"filePathForKey": filepath.Join(outputDir, "key.pem"),
+						},
+					},
+				},
+			},
+			{
+				Id:   "notify",
+				Type: NodeTypeBizNotify,
+				Data: domain.WorkflowNodeData{
+					Name:   "notify",
+					Config: domain.WorkflowNodeConfig{"provider": string(domain.NotificationProviderTypeWebhook), "providerAccessId": "webhook", "subject": "test", "message": "{{ $certificate.commonName }}"},
+				},
+			},
+			{Id: "end", Type: NodeTypeEnd, Data: domain.WorkflowNodeData{Name: "end"}},
+		},
+	}
+
+	err := engine.Invoke(context.Background(), WorkflowExecution{WorkflowId: "wf", RunId: "run", DryRun: true, Graph: graph})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if saves := repo.saves.Load(); saves != 0 {
+		t.Errorf("expected nothing to be persisted, got %d saves", saves)
+	}
+	if calls := webhookCalls.Load(); calls != 0 {
+		t.Errorf("expected no notification to be sent, got %d calls", calls)
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, "cert.pem")); !os.IsNotExist(err) {
+		t.Errorf("expected the certificate not to be deployed")
+	}
+
+	// 本地部署不支持预检，应报告为未校验，而非通过
+	if checked := deployChecked.Load(); checked != false {
+		t.Errorf("expected the deployment to be reported as not checked, got %v", checked)
+	}
+}
@@ -0,0 +1,188 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+
+	"github.com/samber/lo"
+)
+
+type parallelNodeExecutor struct {
+	nodeExecutor
+}
+
+func (ne *parallelNodeExecutor) Execute(execCtx *NodeExecutionContext) (*NodeExecutionResult, error) {
+	var engine *workflowEngine
+	if we, ok := execCtx.engine.(*workflowEngine); !ok {
+		panic("unreachable")
+	} else {
+		engine = we
+	}
+
+	execRes := newNodeExecutionResult(execCtx.Node)
+
+	nodeCfg := execCtx.Node.Data.Config.AsParallel()
+	blocks := lo.Filter(execCtx.Node.Blocks, func(n *Node, _ int) bool { return n.Type == NodeTypeParallelBlock })
+	if len(blocks) == 0 {
+		return execRes, nil
+	}
+
+	concurrency := nodeCfg.Concurrency
+	if concurrency <= 0 || concurrency > len(blocks) {
+		concurrency = len(blocks)
+	}
+	ne.logger.Info(fmt.Sprintf("run %d branches in parallel (concurrency: %d)", len(blocks), concurrency))
+
+	ctx, cancel := context.WithCancel(execCtx.Context())
+	defer cancel()
+
+	var wg sync.WaitGroup
+	var terminated atomic.Bool
+	var suspended atomic.Bool
+	var failed atomic.Bool
+	sem := make(chan struct{}, concurrency)
+	errs := make([]error, len(blocks))
+
+	// 各分支使用独立的变量作用域，待全部分支结束后再按分支顺序合并，
+	// 以免 "node.skipped"、"error.nodeId" 等全局变量在分支之间相互覆盖。
+	varsBase := execCtx.variables.All()
+	varsForks := make([]VariableManager, len(blocks))
+	for i := range blocks {
+		varsForks[i] = forkVariableManager(execCtx.variables)
+	}
+
+	for i, node := range blocks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			// 条件、异常捕获等容器节点不经由执行器协程执行，需在此捕获分支中的 panic，以免导致整个进程崩溃
+			defer func() {
+				if r := recover(); r != nil {
+					errs[i] = fmt.Errorf("workflow engine: branch '%s' panic: %v", node.Data.Name, r)
+					failed.Store(true)
+					slog.Error(fmt.Sprintf("workflow engine: branch panic: %v, stack trace: %s", r, string(debug.Stack())), slog.String("nodeId", node.Id))
+
+					if nodeCfg.FailFast {
+						cancel()
+					}
+				}
+			}()
+
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				errs[i] = ctx.Err()
+				return
+			}
+
+			// 获取到信号量后再检查一次，避免在取消后仍开始执行新分支
+			if ctx.Err() != nil {
+				errs[i] = ctx.Err()
+				return
+			}
+
+			err := engine.executeNode(execCtx.Clone().SetVariablesManager(varsForks[i]).SetContext(ctx), node)
+			if err != nil {
+				if errors.Is(err, ErrTerminated) {
+					terminated.Store(true)
+					cancel()
+					return
+				}
+
+				// 挂起的分支不影响其余分支继续执行，待全部分支结束后再挂起
+				if errors.Is(err, ErrSuspended) {
+					suspended.Store(true)
+					return
+				}
+
+				errs[i] = err
+				if !errors.Is(err, context.Canceled) {
+					failed.Store(true)
+					ne.logger.Warn(fmt.Sprintf("branch '%s' failed: %s", node.Data.Name, err.Error()))
+
+					if nodeCfg.FailFast {
+						cancel()
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	for _, fork := range varsForks {
+		joinVariableManager(execCtx.variables, varsBase, fork)
+	}
+
+	if terminated.Load() {
+		return execRes, ErrTerminated
+	}
+
+	if err := execCtx.Context().Err(); err != nil {
+		return execRes, err
+	}
+
+	if failed.Load() {
+		branchErrs := make([]error, 0)
+		for i, err := range errs {
+			if err == nil {
+				continue
+			}
+
+			// 因快速失败而被取消的分支不计入异常
+			if errors.Is(err, context.Canceled) {
+				ne.logger.Info(fmt.Sprintf("branch '%s' was canceled, because another branch failed", blocks[i].Data.Name))
+				continue
+			}
+
+			branchErrs = append(branchErrs, err)
+		}
+
+		return execRes, fmt.Errorf("%w: %w", ErrBlocksException, errors.Join(branchErrs...))
+	}
+
+	if suspended.Load() {
+		return execRes, ErrSuspended
+	}
+
+	return execRes, nil
+}
+
+func newParallelNodeExecutor() NodeExecutor {
+	return &parallelNodeExecutor{
+		nodeExecutor: nodeExecutor{logger: slog.Default()},
+	}
+}
+
+type parallelBlockNodeExecutor struct {
+	nodeExecutor
+}
+
+func (ne *parallelBlockNodeExecutor) Execute(execCtx *NodeExecutionContext) (*NodeExecutionResult, error) {
+	var engine *workflowEngine
+	if we, ok := execCtx.engine.(*workflowEngine); !ok {
+		panic("unreachable")
+	} else {
+		engine = we
+	}
+
+	execRes := newNodeExecutionResult(execCtx.Node)
+
+	if err := engine.executeBlocks(execCtx.Clone(), execCtx.Node.Blocks); err != nil {
+		return execRes, fmt.Errorf("%w: %w", ErrBlocksException, err)
+	}
+
+	return execRes, nil
+}
+
+func newParallelBlockNodeExecutor() NodeExecutor {
+	return &parallelBlockNodeExecutor{
+		nodeExecutor: nodeExecutor{logger: slog.Default()},
+	}
+}
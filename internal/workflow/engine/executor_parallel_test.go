@@ -0,0 +1,214 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/certimate-go/certimate/internal/domain"
+)
+
+type testNodeExecutor struct {
+	nodeExecutor
+
+	execute func(execCtx *NodeExecutionContext) (*NodeExecutionResult, error)
+}
+
+func (ne *testNodeExecutor) Execute(execCtx *NodeExecutionContext) (*NodeExecutionResult, error) {
+	return ne.execute(execCtx)
+}
+
+func newTestParallelEngine(execute func(execCtx *NodeExecutionContext) (*NodeExecutionResult, error)) *workflowEngine {
+	engine := &workflowEngine{
+		executors: make(map[NodeType]func() NodeExecutor),
+		syslog:    slog.Default(),
+	}
+	engine.executors[NodeTypeStart] = newStartNodeExecutor
+	engine.executors[NodeTypeEnd] = newEndNodeExecutor
+	engine.executors[NodeTypeParallel] = newParallelNodeExecutor
+	engine.executors[NodeTypeParallelBlock] = newParallelBlockNodeExecutor
+	engine.executors[NodeTypeBizDeploy] = func() NodeExecutor {
+		return &testNodeExecutor{nodeExecutor: nodeExecutor{logger: slog.Default()}, execute: execute}
+	}
+	return engine
+}
+
+func newTestParallelGraph(branches int, failFast bool) *Graph {
+	blocks := make([]*Node, 0, branches)
+	for i := 0; i < branches; i++ {
+		blocks = append(blocks, &Node{
+			Id:   fmt.Sprintf("branch%d", i),
+			Type: NodeTypeParallelBlock,
+			Data: domain.WorkflowNodeData{Name: fmt.Sprintf("branch%d", i)},
+			Blocks: []*Node{
+				{Id: fmt.Sprintf("deploy%d", i), Type: NodeTypeBizDeploy, Data: domain.WorkflowNodeData{Name: fmt.Sprintf("deploy%d", i)}},
+			},
+		})
+	}
+
+	return &Graph{
+		Nodes: []*Node{
+			{Id: "start", Type: NodeTypeStart, Data: domain.WorkflowNodeData{Name: "start"}},
+			{Id: "parallel", Type: NodeTypeParallel, Data: domain.WorkflowNodeData{Name: "parallel", Config: domain.WorkflowNodeConfig{"failFast": failFast}}, Blocks: blocks},
+			{Id: "join", Type: NodeTypeBizDeploy, Data: domain.WorkflowNodeData{Name: "join"}},
+			{Id: "end", Type: NodeTypeEnd, Data: domain.WorkflowNodeData{Name: "end"}},
+		},
+	}
+}
+
+func TestParallelNodeFanOutAndJoin(t *testing.T) {
+	var executedMtx sync.Mutex
+	executed := make(map[string]bool)
+	var joined []VariableState
+
+	engine := newTestParallelEngine(func(execCtx *NodeExecutionContext) (*NodeExecutionResult, error) {
+		execRes := newNodeExecutionResult(execCtx.Node)
+
+		executedMtx.Lock()
+		executed[execCtx.Node.Id] = true
+		executedMtx.Unlock()
+
+		if execCtx.Node.Id == "join" {
+			joined = execCtx.variables.All()
+			return execRes, nil
+		}
+
+		// 仅第二个分支跳过执行
+		skipped := execCtx.Node.Id == "deploy1"
+		execRes.AddVariable("test.branch", execCtx.Node.Id, stateValTypeString)
+		execRes.AddVariableWithScope(execCtx.Node.Id, stateVarKeyNodeSkipped, skipped, stateValTypeBoolean)
+		return execRes, nil
+	})
+
+	err := engine.Invoke(context.Background(), WorkflowExecution{WorkflowId: "wf", RunId: "run", Graph: newTestParallelGraph(3, false)})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, nodeId := range []string{"deploy0", "deploy1", "deploy2", "join"} {
+		if !executed[nodeId] {
+			t.Errorf("expected node '%s' to be executed", nodeId)
+		}
+	}
+
+	vars := &variableManager{states: joined}
+	for i := 0; i < 3; i++ {
+		state, ok := vars.GetScoped(fmt.Sprintf("deploy%d", i), stateVarKeyNodeSkipped)
+		if !ok {
+			t.Errorf("expected the scoped variable of node 'deploy%d' to be merged", i)
+			continue
+		}
+		if state.Value != (i == 1) {
+			t.Errorf("expected the scoped variable of node 'deploy%d' to be %v, got %v", i, i == 1, state.Value)
+		}
+	}
+
+	// 全局变量按分支顺序合并，以最后一个分支为准
+	if state, ok := vars.Get("test.branch"); !ok || state.Value != "deploy2" {
+		t.Errorf("expected the global variable to be merged from the last branch, got %v", state)
+	}
+}
+
+func TestParallelNodeBranchFailure(t *testing.T) {
+	var executedMtx sync.Mutex
+	executed := make(map[string]bool)
+
+	engine := newTestParallelEngine(func(execCtx *NodeExecutionContext) (*NodeExecutionResult, error) {
+		execRes := newNodeExecutionResult(execCtx.Node)
+
+		executedMtx.Lock()
+		executed[execCtx.Node.Id] = true
+		executedMtx.Unlock()
+
+		if execCtx.Node.Id == "deploy0" {
+			return execRes, errors.New("mock error")
+		}
+		return execRes, nil
+	})
+
+	err := engine.Invoke(context.Background(), WorkflowExecution{WorkflowId: "wf", RunId: "run", Graph: newTestParallelGraph(2, false)})
+	if !errors.Is(err, ErrBlocksException) {
+		t.Fatalf("expected a blocks exception, got %v", err)
+	}
+
+	if !executed["deploy1"] {
+		t.Errorf("expected the other branch to keep running")
+	}
+	if executed["join"] {
+		t.Errorf("expected the node after the parallel node not to be executed")
+	}
+
+	var execErr *ExecutionError
+	if !errors.As(err, &execErr) {
+		t.Fatalf("expected an execution error, got %T", err)
+	}
+	if execErr.Checkpoint.NodeId != "deploy0" {
+		t.Errorf("expected the failed node to be 'deploy0', got '%s'", execErr.Checkpoint.NodeId)
+	}
+	if state, ok := execErr.Checkpoint.GetVariable("", stateVarKeyErrorMessage); !ok || state.Value != "mock error" {
+		t.Errorf("expected the error message to be merged from the failed branch, got %v", state)
+	}
+}
+
+func TestParallelNodeFailFast(t *testing.T) {
+	engine := newTestParallelEngine(func(execCtx *NodeExecutionContext) (*NodeExecutionResult, error) {
+		execRes := newNodeExecutionResult(execCtx.Node)
+
+		if execCtx.Node.Id == "deploy0" {
+			return execRes, errors.New("mock error")
+		}
+
+		// 其余分支一直等待，直至被取消
+		<-execCtx.Context().Done()
+		return execRes, execCtx.Context().Err()
+	})
+
+	err := engine.Invoke(context.Background(), WorkflowExecution{WorkflowId: "wf", RunId: "run", Graph: newTestParallelGraph(2, true)})
+	if !errors.Is(err, ErrBlocksException) {
+		t.Fatalf("expected a blocks exception, got %v", err)
+	}
+}
+
+func TestParallelNodeBranchPanic(t *testing.T) {
+	var executedMtx sync.Mutex
+	executed := make(map[string]bool)
+
+	engine := newTestParallelEngine(func(execCtx *NodeExecutionContext) (*NodeExecutionResult, error) {
+		executedMtx.Lock()
+		executed[execCtx.Node.Id] = true
+		executedMtx.Unlock()
+
+		return newNodeExecutionResult(execCtx.Node), nil
+	})
+
+	// 容器节点直接在分支协程中执行，其中的 panic 不会被执行器捕获
+	engine.executors[NodeTypeCondition] = func() NodeExecutor {
+		return &testNodeExecutor{nodeExecutor: nodeExecutor{logger: slog.Default()}, execute: func(execCtx *NodeExecutionContext) (*NodeExecutionResult, error) {
+			panic("mock panic")
+		}}
+	}
+
+	graph := newTestParallelGraph(2, false)
+	graph.Nodes[1].Blocks[0].Blocks = []*Node{
+		{Id: "condition0", Type: NodeTypeCondition, Data: domain.WorkflowNodeData{Name: "condition0"}},
+	}
+
+	err := engine.Invoke(context.Background(), WorkflowExecution{WorkflowId: "wf", RunId: "run", Graph: graph})
+	if !errors.Is(err, ErrBlocksException) {
+		t.Fatalf("expected a blocks exception, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "mock panic") {
+		t.Errorf("expected the panic to be reported as the branch error, got %v", err)
+	}
+
+	if !executed["deploy1"] {
+		t.Errorf("expected the other branch to keep running")
+	}
+	if executed["join"] {
+		t.Errorf("expected the node after the parallel node not to be executed")
+	}
+}
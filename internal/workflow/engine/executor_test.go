@@ -0,0 +1,49 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/certimate-go/certimate/internal/domain"
+	"github.com/certimate-go/certimate/internal/secrets"
+)
+
+type testSecretsBackend struct{}
+
+func (b *testSecretsBackend) Resolve(ctx context.Context, ref string) (string, error) {
+	if ref == "missing" {
+		return "", fmt.Errorf("secret '%s' not found", ref)
+	}
+
+	return "resolved-" + ref, nil
+}
+
+func TestResolveNodeConfig(t *testing.T) {
+	secrets.RegisterBackend("test", &testSecretsBackend{})
+
+	config := domain.WorkflowNodeConfig{
+		"provider": "ssh",
+		"providerConfig": map[string]any{
+			"pfxPassword": "${secret:test:pfx}",
+		},
+	}
+
+	resolved, err := resolveNodeConfig(context.Background(), config)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if v := resolved.AsBizDeploy().ProviderConfig["pfxPassword"]; v != "resolved-pfx" {
+		t.Errorf("expected the secret to be resolved, got %v", v)
+	}
+
+	// 不应修改工作流图中的原始配置，以免密钥被持久化
+	if v := config.AsBizDeploy().ProviderConfig["pfxPassword"]; v != "${secret:test:pfx}" {
+		t.Errorf("expected the original config to be kept, got %v", v)
+	}
+
+	if _, err := resolveNodeConfig(context.Background(), domain.WorkflowNodeConfig{"message": "${secret:test:missing}"}); err == nil {
+		t.Errorf("expected an error for the missing secret")
+	}
+}
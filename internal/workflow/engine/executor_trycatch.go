@@ -34,7 +34,7 @@ func (ne *tryCatchNodeExecutor) Execute(execCtx *NodeExecutionContext) (*NodeExe
 
 		err := engine.executeNode(execCtx.Clone(), node)
 		if err != nil {
-			if errors.Is(err, ErrTerminated) {
+			if errors.Is(err, ErrTerminated) || errors.Is(err, ErrSuspended) {
 				return execRes, err
 			}
 			tryErrs = append(tryErrs, err)
@@ -53,7 +53,7 @@ func (ne *tryCatchNodeExecutor) Execute(execCtx *NodeExecutionContext) (*NodeExe
 
 			err := engine.executeNode(execCtx.Clone(), node)
 			if err != nil {
-				if errors.Is(err, ErrTerminated) {
+				if errors.Is(err, ErrTerminated) || errors.Is(err, ErrSuspended) {
 					return execRes, err
 				}
 				catchErrs = append(catchErrs, err)
@@ -0,0 +1,196 @@
+package engine
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/certimate-go/certimate/internal/domain"
+)
+
+// 挂起后动作，在挂起状态持久化之后执行。
+// 挂起前执行的副作用（如推送审批通知）在断点保存失败时将无从响应，因此应延迟到此时执行。
+type SuspendedAction struct {
+	Node *Node
+	Run  func(ctx context.Context, logger *slog.Logger) error
+}
+
+// 执行进度，用于在挂起后从断点恢复执行。
+type ProgressManager interface {
+	// 判断节点是否已执行完成。
+	IsCompleted(nodeId string) bool
+	// 标记节点已执行完成。
+	Complete(nodeId string)
+	// 撤销节点已执行完成的标记。
+	Revert(nodeIds ...string)
+	// 标记节点已挂起执行，并登记挂起后动作。
+	Suspend(nodeId string, actions ...SuspendedAction)
+	// 取出全部挂起后动作，并清空已登记的动作。
+	DrainSuspended() []SuspendedAction
+
+	// 生成当前执行进度及状态的快照。
+	Snapshot(variables VariableManager, inputs InOutManager, rollbacks RollbackManager) *domain.WorkflowRunCheckpoint
+}
+
+type progressManager struct {
+	mtx              sync.RWMutex
+	completed        []string
+	suspendedNodeId  string
+	suspendedActions []SuspendedAction
+}
+
+var _ ProgressManager = (*progressManager)(nil)
+
+func (m *progressManager) IsCompleted(nodeId string) bool {
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+
+	return slices.Contains(m.completed, nodeId)
+}
+
+func (m *progressManager) Complete(nodeId string) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	if !slices.Contains(m.completed, nodeId) {
+		m.completed = append(m.completed, nodeId)
+	}
+}
+
+func (m *progressManager) Revert(nodeIds ...string) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	m.completed = slices.DeleteFunc(m.completed, func(nodeId string) bool {
+		return slices.Contains(nodeIds, nodeId)
+	})
+}
+
+func (m *progressManager) Suspend(nodeId string, actions ...SuspendedAction) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	m.suspendedNodeId = nodeId
+	m.suspendedActions = append(m.suspendedActions, actions...)
+}
+
+func (m *progressManager) DrainSuspended() []SuspendedAction {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	actions := m.suspendedActions
+	m.suspendedActions = nil
+	return actions
+}
+
+func (m *progressManager) Snapshot(variables VariableManager, inputs InOutManager, rollbacks RollbackManager) *domain.WorkflowRunCheckpoint {
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+
+	checkpoint := &domain.WorkflowRunCheckpoint{
+		NodeId:           m.suspendedNodeId,
+		CompletedNodeIds: slices.Clone(m.completed),
+		Variables:        make([]*domain.WorkflowRunCheckpointVariable, 0),
+		Inputs:           make([]*domain.WorkflowRunCheckpointInOut, 0),
+	}
+	for _, state := range variables.All() {
+		checkpoint.Variables = append(checkpoint.Variables, &domain.WorkflowRunCheckpointVariable{
+			Scope:     state.Scope,
+			Key:       state.Key,
+			Value:     state.Value,
+			ValueType: state.ValueType,
+		})
+	}
+	for _, state := range inputs.All() {
+		checkpoint.Inputs = append(checkpoint.Inputs, &domain.WorkflowRunCheckpointInOut{
+			NodeId:     state.NodeId,
+			Type:       state.Type,
+			Name:       state.Name,
+			Value:      state.Value,
+			ValueType:  state.ValueType,
+			Persistent: state.Persistent,
+		})
+	}
+	if rollbacks != nil {
+		for _, action := range rollbacks.All() {
+			checkpoint.Rollbacks = append(checkpoint.Rollbacks, &domain.WorkflowRunCheckpointRollback{
+				NodeId: action.Node.Id,
+				Data:   action.Data,
+			})
+		}
+	}
+
+	return checkpoint
+}
+
+func newProgressManager() ProgressManager {
+	return &progressManager{
+		completed: make([]string, 0),
+	}
+}
+
+// 从快照中恢复执行进度及状态。
+func restoreProgress(checkpoint *domain.WorkflowRunCheckpoint, variables VariableManager, inputs InOutManager) ProgressManager {
+	progress := &progressManager{
+		completed: slices.Clone(checkpoint.CompletedNodeIds),
+	}
+
+	for _, state := range checkpoint.Variables {
+		variables.Add(VariableState{
+			Scope:     state.Scope,
+			Key:       state.Key,
+			Value:     restoreStateValue(state.Value, state.ValueType),
+			ValueType: state.ValueType,
+		})
+	}
+	for _, state := range checkpoint.Inputs {
+		inputs.Add(InOutState{
+			NodeId:     state.NodeId,
+			Type:       state.Type,
+			Name:       state.Name,
+			Value:      restoreStateValue(state.Value, state.ValueType),
+			ValueType:  state.ValueType,
+			Persistent: state.Persistent,
+		})
+	}
+
+	return progress
+}
+
+// 查找从根节点到指定节点的路径（含指定节点本身）。
+func findNodePath(blocks []*Node, nodeId string) []*Node {
+	for _, node := range blocks {
+		if node.Id == nodeId {
+			return []*Node{node}
+		}
+
+		if path := findNodePath(node.Blocks, nodeId); len(path) > 0 {
+			return append([]*Node{node}, path...)
+		}
+	}
+
+	return nil
+}
+
+// 快照经 JSON 序列化后会丢失原始值类型，需根据值类型还原。
+func restoreStateValue(value any, valueType string) any {
+	switch valueType {
+	case stateValTypeNumber:
+		if v, ok := value.(float64); ok && v == math.Trunc(v) {
+			return int64(v)
+		}
+
+	case stateValTypeDateTime:
+		if v, ok := value.(string); ok {
+			if t, err := time.Parse(time.RFC3339Nano, v); err == nil {
+				return t
+			}
+			return time.Time{}
+		}
+	}
+
+	return value
+}
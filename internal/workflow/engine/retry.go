@@ -0,0 +1,124 @@
+package engine
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"math/rand/v2"
+	"regexp"
+	"time"
+
+	"github.com/certimate-go/certimate/internal/domain"
+)
+
+const (
+	defaultRetryInterval = 5 * time.Second
+)
+
+// 等待重试间隔，测试时可替换。
+var retryAfter = time.After
+
+// 按节点配置的重试策略执行函数。
+// 每次尝试的序号、开始时间、耗时及错误信息均会记录到运行日志中。
+//
+// 入参：
+//   - execCtx: 节点执行上下文。
+//   - fn: 待执行的函数。
+//
+// 出参：
+//   - attempts: 实际尝试次数。
+//   - err: 最后一次执行的错误。
+func (e *nodeExecutor) executeWithRetry(execCtx *NodeExecutionContext, fn func() error) (attempts int, err error) {
+	policy := execCtx.Node.Data.Retry
+	if policy == nil || policy.MaxAttempts <= 1 {
+		return 1, fn()
+	}
+
+	// 正则表达式已在保存工作流时校验过，此处的错误仅作兜底
+	retryablePatterns, err := policy.CompileRetryableErrors()
+	if err != nil {
+		return 0, err
+	}
+
+	for attempts = 1; attempts <= policy.MaxAttempts; attempts++ {
+		startedAt := time.Now()
+		err = fn()
+		attemptAttrs := []any{
+			slog.Int("attempt", attempts),
+			slog.Int("maxAttempts", policy.MaxAttempts),
+			slog.Time("startedAt", startedAt),
+			slog.Duration("elapsed", time.Since(startedAt)),
+		}
+
+		if err == nil {
+			e.logger.Info(fmt.Sprintf("attempt %d/%d succeeded", attempts, policy.MaxAttempts), attemptAttrs...)
+			return attempts, nil
+		}
+
+		attemptAttrs = append(attemptAttrs, slog.String("error", err.Error()))
+
+		if attempts == policy.MaxAttempts {
+			e.logger.Warn(fmt.Sprintf("attempt %d/%d failed, no more retries", attempts, policy.MaxAttempts), attemptAttrs...)
+			break
+		}
+
+		if !isRetryableError(err, retryablePatterns) {
+			e.logger.Warn(fmt.Sprintf("attempt %d/%d failed with a non-retryable error", attempts, policy.MaxAttempts), attemptAttrs...)
+			break
+		}
+
+		wait := calcRetryBackoff(policy, attempts)
+		e.logger.Warn(fmt.Sprintf("attempt %d/%d failed, retry in %s", attempts, policy.MaxAttempts, wait), attemptAttrs...)
+
+		select {
+		case <-execCtx.Context().Done():
+			return attempts, execCtx.Context().Err()
+		case <-retryAfter(wait):
+		}
+	}
+
+	return attempts, err
+}
+
+func isRetryableError(err error, patterns []*regexp.Regexp) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+
+	errmsg := err.Error()
+	for _, re := range patterns {
+		if re.MatchString(errmsg) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func calcRetryBackoff(policy *domain.WorkflowNodeRetryPolicy, attempts int) time.Duration {
+	interval := time.Duration(policy.Interval) * time.Second
+	if interval <= 0 {
+		interval = defaultRetryInterval
+	}
+
+	wait := interval
+	if policy.Backoff == domain.WorkflowNodeRetryBackoffTypeExponential {
+		waitF := float64(interval) * math.Pow(2, float64(attempts-1))
+		if waitF >= float64(math.MaxInt64) {
+			wait = time.Duration(math.MaxInt64)
+		} else {
+			wait = time.Duration(waitF)
+		}
+		if policy.MaxInterval > 0 {
+			wait = min(wait, time.Duration(policy.MaxInterval)*time.Second)
+		}
+	}
+
+	// 抖动范围为 [wait/2, wait]
+	if policy.Jitter && wait > 1 {
+		half := wait / 2
+		wait = half + rand.N(wait-half+1)
+	}
+
+	return wait
+}
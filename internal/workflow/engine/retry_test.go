@@ -0,0 +1,154 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/certimate-go/certimate/internal/domain"
+)
+
+func TestCalcRetryBackoff(t *testing.T) {
+	testCases := []struct {
+		name     string
+		policy   *domain.WorkflowNodeRetryPolicy
+		attempts int
+		want     time.Duration
+	}{
+		{
+			name:     "default interval",
+			policy:   &domain.WorkflowNodeRetryPolicy{},
+			attempts: 3,
+			want:     defaultRetryInterval,
+		},
+		{
+			name:     "fixed",
+			policy:   &domain.WorkflowNodeRetryPolicy{Backoff: domain.WorkflowNodeRetryBackoffTypeFixed, Interval: 10},
+			attempts: 3,
+			want:     10 * time.Second,
+		},
+		{
+			name:     "exponential",
+			policy:   &domain.WorkflowNodeRetryPolicy{Backoff: domain.WorkflowNodeRetryBackoffTypeExponential, Interval: 2},
+			attempts: 4,
+			want:     16 * time.Second,
+		},
+		{
+			name:     "exponential with max interval",
+			policy:   &domain.WorkflowNodeRetryPolicy{Backoff: domain.WorkflowNodeRetryBackoffTypeExponential, Interval: 2, MaxInterval: 5},
+			attempts: 4,
+			want:     5 * time.Second,
+		},
+		{
+			name:     "exponential overflow",
+			policy:   &domain.WorkflowNodeRetryPolicy{Backoff: domain.WorkflowNodeRetryBackoffTypeExponential, Interval: 1, MaxInterval: 60},
+			attempts: 1000,
+			want:     60 * time.Second,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := calcRetryBackoff(tc.policy, tc.attempts); got != tc.want {
+				t.Errorf("expected %s, got %s", tc.want, got)
+			}
+		})
+	}
+
+	t.Run("jitter", func(t *testing.T) {
+		policy := &domain.WorkflowNodeRetryPolicy{Interval: 10, Jitter: true}
+		for i := 0; i < 100; i++ {
+			if got := calcRetryBackoff(policy, 1); got < 5*time.Second || got > 10*time.Second {
+				t.Fatalf("expected the backoff in [5s, 10s], got %s", got)
+			}
+		}
+	})
+}
+
+func TestExecuteWithRetry(t *testing.T) {
+	waits := make([]time.Duration, 0)
+	retryAfter = func(d time.Duration) <-chan time.Time {
+		waits = append(waits, d)
+		ch := make(chan time.Time, 1)
+		ch <- time.Now()
+		return ch
+	}
+	defer func() { retryAfter = time.After }()
+
+	newExecCtx := func(policy *domain.WorkflowNodeRetryPolicy) *NodeExecutionContext {
+		node := &Node{Id: "deploy", Type: NodeTypeBizDeploy, Data: domain.WorkflowNodeData{Retry: policy}}
+		return (&NodeExecutionContext{}).SetExecutingNode(node).SetContext(context.Background())
+	}
+
+	policy := &domain.WorkflowNodeRetryPolicy{
+		MaxAttempts:     3,
+		Backoff:         domain.WorkflowNodeRetryBackoffTypeExponential,
+		Interval:        1,
+		RetryableErrors: []string{"(?i)timeout", "rate limit"},
+	}
+
+	testCases := []struct {
+		name         string
+		errs         []error
+		wantAttempts int
+		wantErr      bool
+		wantWaits    []time.Duration
+	}{
+		{
+			name:         "succeeded at once",
+			errs:         []error{nil},
+			wantAttempts: 1,
+			wantWaits:    []time.Duration{},
+		},
+		{
+			name:         "retryable error then succeeded",
+			errs:         []error{errors.New("i/o Timeout"), errors.New("rate limit exceeded"), nil},
+			wantAttempts: 3,
+			wantWaits:    []time.Duration{time.Second, 2 * time.Second},
+		},
+		{
+			name:         "retryable error exhausted",
+			errs:         []error{errors.New("timeout"), errors.New("timeout"), errors.New("timeout")},
+			wantAttempts: 3,
+			wantErr:      true,
+			wantWaits:    []time.Duration{time.Second, 2 * time.Second},
+		},
+		{
+			name:         "non-retryable error",
+			errs:         []error{errors.New("permission denied"), nil},
+			wantAttempts: 1,
+			wantErr:      true,
+			wantWaits:    []time.Duration{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			waits = waits[:0]
+
+			calls := 0
+			executor := &nodeExecutor{logger: slog.Default()}
+			attempts, err := executor.executeWithRetry(newExecCtx(policy), func() error {
+				err := tc.errs[calls]
+				calls++
+				return err
+			})
+			if (err != nil) != tc.wantErr {
+				t.Errorf("expected error: %v, got %v", tc.wantErr, err)
+			}
+			if attempts != tc.wantAttempts || calls != tc.wantAttempts {
+				t.Errorf("expected %d attempts, got %d (called %d times)", tc.wantAttempts, attempts, calls)
+			}
+			if len(waits) != len(tc.wantWaits) {
+				t.Fatalf("expected waits %v, got %v", tc.wantWaits, waits)
+			}
+			for i := range waits {
+				if waits[i] != tc.wantWaits[i] {
+					t.Errorf("expected waits %v, got %v", tc.wantWaits, waits)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package engine
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"sync"
+)
+
+// 回滚动作，用于在工作流执行失败时撤销节点已生效的变更。
+type RollbackAction struct {
+	Node *Node
+	Data map[string]any // 执行回滚所需的数据，将随执行断点持久化，以便从断点恢复执行后重建回滚动作
+	Run  func(ctx context.Context, logger *slog.Logger) error
+}
+
+// 可从执行断点中重建回滚动作的节点执行器。
+type rollbackRestorer interface {
+	RestoreRollback(wfCtx *WorkflowContext, node *Node, data map[string]any) RollbackAction
+}
+
+type RollbackManager interface {
+	// 登记回滚动作。
+	Push(action RollbackAction)
+	// 按登记顺序的逆序取出全部回滚动作，并清空已登记的动作。
+	Drain() []RollbackAction
+	// 按登记顺序获取全部回滚动作。
+	All() []RollbackAction
+}
+
+type rollbackManager struct {
+	actionsMtx sync.Mutex
+	actions    []RollbackAction
+}
+
+var _ RollbackManager = (*rollbackManager)(nil)
+
+func (m *rollbackManager) Push(action RollbackAction) {
+	m.actionsMtx.Lock()
+	defer m.actionsMtx.Unlock()
+
+	m.actions = append(m.actions, action)
+}
+
+func (m *rollbackManager) Drain() []RollbackAction {
+	m.actionsMtx.Lock()
+	defer m.actionsMtx.Unlock()
+
+	actions := m.actions
+	m.actions = make([]RollbackAction, 0)
+	slices.Reverse(actions)
+	return actions
+}
+
+func (m *rollbackManager) All() []RollbackAction {
+	m.actionsMtx.Lock()
+	defer m.actionsMtx.Unlock()
+
+	return slices.Clone(m.actions)
+}
+
+func newRollbackManager() RollbackManager {
+	return &rollbackManager{
+		actions: make([]RollbackAction, 0),
+	}
+}
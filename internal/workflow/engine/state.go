@@ -1,9 +1,13 @@
 package engine
 
 import (
+	"encoding/json"
 	"fmt"
+	"math"
+	"reflect"
 	"slices"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 )
@@ -155,6 +159,25 @@ func newVariableManager() VariableManager {
 	}
 }
 
+// 以现有变量为初始值创建一个独立的变量管理器，用于并行分支，避免各分支之间相互覆盖变量。
+func forkVariableManager(m VariableManager) VariableManager {
+	return &variableManager{
+		states: m.All(),
+	}
+}
+
+// 将分支变量管理器中相较于初始值新增或变更的变量合并回原变量管理器。
+func joinVariableManager(m VariableManager, base []VariableState, fork VariableManager) {
+	for _, state := range fork.All() {
+		unchanged := slices.ContainsFunc(base, func(item VariableState) bool {
+			return item.Scope == state.Scope && item.Key == state.Key && reflect.DeepEqual(item.Value, state.Value)
+		})
+		if !unchanged {
+			m.Add(state)
+		}
+	}
+}
+
 type InOutState struct {
 	NodeId     string
 	Type       string
@@ -278,6 +301,44 @@ func newInOutManager() InOutManager {
 	}
 }
 
+// 将触发数据展开为全局变量，嵌套对象的字段路径以 "." 连接。
+func flattenRunPayload(payload map[string]any) []VariableState {
+	states := make([]VariableState, 0)
+
+	var walk func(prefix string, value any)
+	walk = func(prefix string, value any) {
+		switch v := value.(type) {
+		case nil:
+			return
+		case map[string]any:
+			for k, sv := range v {
+				walk(prefix+k+".", sv)
+			}
+			return
+		}
+
+		key := strings.TrimSuffix(prefix, ".")
+		switch v := value.(type) {
+		case string:
+			states = append(states, VariableState{Key: key, Value: v, ValueType: stateValTypeString})
+		case bool:
+			states = append(states, VariableState{Key: key, Value: v, ValueType: stateValTypeBoolean})
+		case float64:
+			if v == math.Trunc(v) {
+				states = append(states, VariableState{Key: key, Value: int64(v), ValueType: stateValTypeNumber})
+			} else {
+				states = append(states, VariableState{Key: key, Value: strconv.FormatFloat(v, 'f', -1, 64), ValueType: stateValTypeString})
+			}
+		default:
+			raw, _ := json.Marshal(v)
+			states = append(states, VariableState{Key: key, Value: string(raw), ValueType: stateValTypeString})
+		}
+	}
+	walk(stateVarKeyRunPayloadPrefix, payload)
+
+	return states
+}
+
 const (
 	stateValTypeBoolean  = "boolean"
 	stateValTypeDateTime = "datetime"
@@ -286,7 +347,8 @@ const (
 )
 
 const (
-	stateIOTypeRef = "ref"
+	stateIOTypeRef   = "ref"
+	stateIOTypeValue = "value"
 )
 
 const (
@@ -295,12 +357,19 @@ const (
 	stateVarKeyWorkflowDescription        = "workflow.description"        // ValueType: "string"
 	stateVarKeyRunId                      = "run.id"                      // ValueType: "string"
 	stateVarKeyRunTrigger                 = "run.trigger"                 // ValueType: "string"
+	stateVarKeyRunPayloadPrefix           = "run.payload."                // 前缀，后接触发数据中的字段路径，如 "run.payload.ref"
+	stateVarKeyRunDryRun                  = "run.dryRun"                  // ValueType: "boolean"
 	stateVarKeyNodeId                     = "node.id"                     // ValueType: "string"
 	stateVarKeyNodeName                   = "node.name"                   // ValueType: "string"
 	stateVarKeyNodeSkipped                = "node.skipped"                // ValueType: "boolean"
+	stateVarKeyNodeDryRunChecked          = "node.dryRunChecked"          // ValueType: "boolean"。试运行时表示节点是否实际校验了外部目标（如部署目标、质询提供商）
 	stateVarKeyErrorNodeId                = "error.nodeId"                // ValueType: "string"
 	stateVarKeyErrorNodeName              = "error.nodeName"              // ValueType: "string"
 	stateVarKeyErrorMessage               = "error.message"               // ValueType: "string"
+	stateVarKeyApprovalStatus             = "approval.status"             // ValueType: "string"。可取值 "pending"、"approved"、"rejected"、"expired"
+	stateVarKeyApprovalToken              = "approval.token"              // ValueType: "string"
+	stateVarKeyApprovalExpiresAt          = "approval.expiresAt"          // ValueType: "datetime"
+	stateVarKeyApprovalComment            = "approval.comment"            // ValueType: "string"
 	stateVarKeyCertificateDomain          = "certificate.domain"          // 已废弃，仅为兼容旧版而保留，请使用 [stateVarKeyCertificateCommonName]
 	stateVarKeyCertificateDomains         = "certificate.domains"         // 已废弃，仅为兼容旧版而保留，请使用 [stateVarKeyCertificateSubjectAltNames]
 	stateVarKeyCertificateCommonName      = "certificate.commonName"      // ValueType: "string"
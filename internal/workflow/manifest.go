@@ -0,0 +1,345 @@
+package workflow
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/tools/cron"
+	"github.com/samber/lo"
+
+	"github.com/certimate-go/certimate/internal/domain"
+	"github.com/certimate-go/certimate/internal/domain/dtos"
+	xmaps "github.com/certimate-go/certimate/pkg/utils/maps"
+	xyaml "github.com/certimate-go/certimate/pkg/utils/yaml"
+)
+
+func (s *WorkflowService) ExportWorkflow(ctx context.Context, req *dtos.WorkflowExportReq) (*dtos.WorkflowExportResp, error) {
+	workflow, err := s.workflowRepo.GetById(ctx, req.WorkflowId)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest, err := s.ExportWorkflowManifest(ctx, workflow)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := marshalWorkflowManifest(manifest, req.Format)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dtos.WorkflowExportResp{
+		Format:  lo.CoalesceOrEmpty(req.Format, "yaml"),
+		Content: string(content),
+	}, nil
+}
+
+func (s *WorkflowService) ImportWorkflow(ctx context.Context, req *dtos.WorkflowImportReq) (*dtos.WorkflowImportResp, error) {
+	manifest, err := unmarshalWorkflowManifest([]byte(req.Content))
+	if err != nil {
+		return nil, err
+	}
+
+	workflow := &domain.Workflow{}
+	if req.WorkflowId != "" {
+		workflow, err = s.workflowRepo.GetById(ctx, req.WorkflowId)
+		if err != nil {
+			return nil, err
+		} else if workflow.ManagedBy != "" {
+			return nil, domain.NewError(403, fmt.Sprintf("workflow is managed by '%s' and cannot be modified", workflow.ManagedBy))
+		}
+	}
+
+	if err := s.ApplyWorkflowManifest(ctx, workflow, manifest, req.Inputs); err != nil {
+		return nil, err
+	}
+
+	workflow, err = s.SaveWorkflow(ctx, workflow)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dtos.WorkflowImportResp{WorkflowId: workflow.Id}, nil
+}
+
+// 将工作流清单应用到指定的工作流模型上，但不会持久化到数据库中。
+func (s *WorkflowService) ApplyWorkflowManifest(ctx context.Context, workflow *domain.Workflow, manifest *domain.WorkflowManifest, inputs map[string]string) error {
+	if manifest.Trigger == domain.WorkflowTriggerTypeScheduled {
+		if _, err := cron.NewSchedule(manifest.TriggerCron); err != nil {
+			return domain.NewError(400, fmt.Sprintf("invalid workflow manifest: invalid trigger cron: %s", err.Error()))
+		}
+	}
+
+	graph, err := s.resolveWorkflowManifestGraph(ctx, manifest, inputs)
+	if err != nil {
+		return err
+	}
+
+	workflow.Name = manifest.Name
+	workflow.Description = manifest.Description
+	workflow.Trigger = manifest.Trigger
+	workflow.TriggerCron = lo.If(manifest.Trigger == domain.WorkflowTriggerTypeScheduled, manifest.TriggerCron).Else("")
+	workflow.Enabled = manifest.Enabled
+	workflow.ConcurrencyPolicy = manifest.ConcurrencyPolicy
+	workflow.Timeout = manifest.Timeout
+	workflow.GraphDraft = graph
+	workflow.GraphContent = graph
+	workflow.HasDraft = false
+	workflow.HasContent = true
+	if workflow.Trigger == domain.WorkflowTriggerTypeWebhook && workflow.TriggerWebhookSecret == "" {
+		workflow.TriggerWebhookSecret = generateWebhookSecret()
+	}
+
+	return nil
+}
+
+// 保存工作流，并同步更新定时任务。
+func (s *WorkflowService) SaveWorkflow(ctx context.Context, workflow *domain.Workflow) (*domain.Workflow, error) {
+	workflow, err := s.workflowRepo.Save(ctx, workflow)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.SyncWorkflowSchedule(ctx, workflow); err != nil {
+		return nil, err
+	}
+
+	return workflow, nil
+}
+
+// 按工作流当前的触发方式及启用状态，同步更新其定时任务。
+func (s *WorkflowService) SyncWorkflowSchedule(ctx context.Context, workflow *domain.Workflow) error {
+	return syncWorkflowJob(s, workflow.Id, workflow.Enabled, workflow.Trigger, workflow.TriggerCron)
+}
+
+// 将工作流转换为可移植的工作流清单。
+func (s *WorkflowService) ExportWorkflowManifest(ctx context.Context, workflow *domain.Workflow) (*domain.WorkflowManifest, error) {
+	if workflow.GraphContent == nil || len(workflow.GraphContent.Nodes) == 0 {
+		return nil, fmt.Errorf("workflow graph content is empty")
+	}
+
+	graph, err := cloneWorkflowGraph(workflow.GraphContent)
+	if err != nil {
+		return nil, err
+	}
+
+	// 查询所有被引用的授权记录
+	accessIds := make([]any, 0)
+	walkWorkflowNodes(graph.Nodes, func(node *domain.WorkflowNode) {
+		for _, field := range domain.WorkflowNodeAccessRefFields {
+			if accessId := xmaps.GetString(node.Data.Config, field); accessId != "" {
+				accessIds = append(accessIds, accessId)
+			}
+		}
+	})
+
+	accessesById := make(map[string]*domain.Access)
+	accessNameCounts := make(map[string]int)
+	if len(accessIds) > 0 {
+		accesses, err := s.accessRepo.ListWithExprs(ctx, dbx.In("id", accessIds...), dbx.HashExp{"deleted": ""})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, access := range accesses {
+			accessesById[access.Id] = access
+			accessNameCounts[access.Name]++
+		}
+	}
+
+	// 将授权记录 ID 替换为名称引用，将敏感字段替换为输入占位符
+	manifestAccesses := make([]*domain.WorkflowManifestAccess, 0)
+	manifestInputs := make([]string, 0)
+	var errs []error
+	walkWorkflowNodes(graph.Nodes, func(node *domain.WorkflowNode) {
+		for _, field := range domain.WorkflowNodeAccessRefFields {
+			accessId := xmaps.GetString(node.Data.Config, field)
+			if accessId == "" {
+				continue
+			}
+
+			access, ok := accessesById[accessId]
+			if !ok {
+				errs = append(errs, fmt.Errorf("access #%s referenced by node '%s' not found", accessId, node.Id))
+				continue
+			}
+
+			// 授权记录按名称引用，因此同一工作流中引用的授权名称不能重复
+			if accessNameCounts[access.Name] > 1 {
+				errs = append(errs, fmt.Errorf("access name '%s' is ambiguous, please rename the accesses before exporting", access.Name))
+				continue
+			}
+			if !lo.ContainsBy(manifestAccesses, func(a *domain.WorkflowManifestAccess) bool { return a.Name == access.Name }) {
+				manifestAccesses = append(manifestAccesses, &domain.WorkflowManifestAccess{Name: access.Name, Provider: access.Provider})
+			}
+
+			node.Data.Config[field] = domain.BuildWorkflowManifestPlaceholder(domain.WorkflowManifestPlaceholderKindAccess, access.Name)
+		}
+
+		for _, field := range domain.GetWorkflowNodeSensitiveFields(node) {
+			if value, ok := node.Data.Config.GetPath(field); !ok || value == "" {
+				continue
+			}
+
+			input := fmt.Sprintf("%s.%s", node.Id, field)
+			manifestInputs = append(manifestInputs, input)
+			node.Data.Config.SetPath(field, domain.BuildWorkflowManifestPlaceholder(domain.WorkflowManifestPlaceholderKindInput, input))
+		}
+	})
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	manifest := &domain.WorkflowManifest{
+		Version:           domain.ManifestVersionV1,
+		Kind:              domain.ManifestKindWorkflow,
+		Name:              workflow.Name,
+		Description:       workflow.Description,
+		Trigger:           workflow.Trigger,
+		TriggerCron:       lo.If(workflow.Trigger == domain.WorkflowTriggerTypeScheduled, workflow.TriggerCron).Else(""),
+		Enabled:           workflow.Enabled,
+		ConcurrencyPolicy: workflow.ConcurrencyPolicy,
+		Timeout:           workflow.Timeout,
+		Accesses:          manifestAccesses,
+		Inputs:            manifestInputs,
+		Graph:             graph,
+	}
+	return manifest, nil
+}
+
+func (s *WorkflowService) resolveWorkflowManifestGraph(ctx context.Context, manifest *domain.WorkflowManifest, inputs map[string]string) (*domain.WorkflowGraph, error) {
+	graph, err := cloneWorkflowGraph(manifest.Graph)
+	if err != nil {
+		return nil, err
+	}
+
+	// 查询所有按名称引用的授权记录
+	accessNames := make([]any, 0)
+	walkWorkflowNodes(graph.Nodes, func(node *domain.WorkflowNode) {
+		node.Data.Config.Walk(func(_ string, value any) {
+			if kind, name, ok := domain.ParseWorkflowManifestPlaceholder(value); ok && kind == domain.WorkflowManifestPlaceholderKindAccess {
+				accessNames = append(accessNames, name)
+			}
+		})
+	})
+
+	accessesByName := make(map[string][]*domain.Access)
+	if len(accessNames) > 0 {
+		accesses, err := s.accessRepo.ListWithExprs(ctx, dbx.In("name", accessNames...), dbx.HashExp{"deleted": ""})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, access := range accesses {
+			// 如果清单中声明了授权提供商，则只匹配提供商一致的授权记录
+			if declared, ok := lo.Find(manifest.Accesses, func(a *domain.WorkflowManifestAccess) bool { return a.Name == access.Name }); ok && declared.Provider != "" && declared.Provider != access.Provider {
+				continue
+			}
+
+			accessesByName[access.Name] = append(accessesByName[access.Name], access)
+		}
+	}
+
+	// 将占位符替换为实际值
+	var errs []error
+	walkWorkflowNodes(graph.Nodes, func(node *domain.WorkflowNode) {
+		node.Data.Config.Walk(func(field string, value any) {
+			kind, name, ok := domain.ParseWorkflowManifestPlaceholder(value)
+			if !ok {
+				return
+			}
+
+			switch kind {
+			case domain.WorkflowManifestPlaceholderKindAccess:
+				candidates := accessesByName[name]
+				if len(candidates) == 0 {
+					errs = append(errs, fmt.Errorf("access '%s' referenced by node '%s' not found", name, node.Id))
+				} else if len(candidates) > 1 {
+					errs = append(errs, fmt.Errorf("access '%s' referenced by node '%s' is ambiguous, %d accesses have the same name", name, node.Id, len(candidates)))
+				} else {
+					node.Data.Config.SetPath(field, candidates[0].Id)
+				}
+
+			case domain.WorkflowManifestPlaceholderKindInput:
+				if input, ok := inputs[name]; !ok || input == "" {
+					errs = append(errs, fmt.Errorf("input '%s' required by node '%s' is missing", name, node.Id))
+				} else {
+					node.Data.Config.SetPath(field, input)
+				}
+			}
+		})
+	})
+	if len(errs) > 0 {
+		return nil, domain.NewError(400, fmt.Sprintf("failed to resolve workflow manifest: %s", errors.Join(errs...).Error()))
+	}
+
+	if err := graph.Verify(); err != nil {
+		return nil, domain.NewError(400, fmt.Sprintf("workflow graph is invalid: %s", err.Error()))
+	}
+
+	return graph, nil
+}
+
+func marshalWorkflowManifest(manifest *domain.WorkflowManifest, format string) ([]byte, error) {
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	switch strings.ToLower(format) {
+	case "", "yaml", "yml":
+		return xyaml.FromJSON(data)
+	case "json":
+		return data, nil
+	default:
+		return nil, domain.NewError(400, fmt.Sprintf("unsupported manifest format '%s'", format))
+	}
+}
+
+func unmarshalWorkflowManifest(content []byte) (*domain.WorkflowManifest, error) {
+	// JSON 是 YAML 的子集，统一按 YAML 解析
+	data, err := xyaml.ToJSON(content)
+	if err != nil {
+		return nil, domain.NewError(400, fmt.Sprintf("invalid workflow manifest: %s", err.Error()))
+	}
+
+	manifest := &domain.WorkflowManifest{}
+	if err := json.Unmarshal(data, manifest); err != nil {
+		return nil, domain.NewError(400, fmt.Sprintf("invalid workflow manifest: %s", err.Error()))
+	}
+
+	if err := manifest.Verify(); err != nil {
+		return nil, domain.NewError(400, fmt.Sprintf("invalid workflow manifest: %s", err.Error()))
+	}
+
+	return manifest, nil
+}
+
+func cloneWorkflowGraph(graph *domain.WorkflowGraph) (*domain.WorkflowGraph, error) {
+	data, err := json.Marshal(graph)
+	if err != nil {
+		return nil, err
+	}
+
+	cloned := &domain.WorkflowGraph{}
+	if err := json.Unmarshal(data, cloned); err != nil {
+		return nil, err
+	}
+
+	return cloned, nil
+}
+
+func walkWorkflowNodes(nodes []*domain.WorkflowNode, fn func(node *domain.WorkflowNode)) {
+	for _, node := range nodes {
+		fn(node)
+
+		if len(node.Blocks) > 0 {
+			walkWorkflowNodes(node.Blocks, fn)
+		}
+	}
+}
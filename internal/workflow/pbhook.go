@@ -12,6 +12,8 @@ import (
 func registerWorkflowRecordEvents() {
 	pb := app.GetApp()
 	pb.OnRecordCreateRequest(domain.CollectionNameWorkflow).BindFunc(func(e *core.RecordRequestEvent) error {
+		onWorkflowRecordBeforeSave(e.Record)
+
 		if err := e.Next(); err != nil {
 			return err
 		}
@@ -24,6 +26,8 @@ func registerWorkflowRecordEvents() {
 		return nil
 	})
 	pb.OnRecordUpdateRequest(domain.CollectionNameWorkflow).BindFunc(func(e *core.RecordRequestEvent) error {
+		onWorkflowRecordBeforeSave(e.Record)
+
 		if err := e.Next(); err != nil {
 			return err
 		}
@@ -49,26 +53,21 @@ func registerWorkflowRecordEvents() {
 	})
 }
 
-func onWorkflowRecordCreateOrUpdate(_ context.Context, _ core.App, record *core.Record) error {
-	scheduler := app.GetScheduler()
+func onWorkflowRecordBeforeSave(record *core.Record) {
+	// 如果是 Webhook 触发但未设置密钥，则自动生成
+	trigger := record.GetString("trigger")
+	triggerWebhookSecret := record.GetString("triggerWebhookSecret")
+	if trigger == domain.WorkflowTriggerTypeWebhook.String() && triggerWebhookSecret == "" {
+		record.Set("triggerWebhookSecret", generateWebhookSecret())
+	}
+}
 
+func onWorkflowRecordCreateOrUpdate(_ context.Context, _ core.App, record *core.Record) error {
 	// 向数据库插入/更新时，同时更新定时任务
 	enabled := record.GetBool("enabled")
-	trigger := record.GetString("trigger")
+	trigger := domain.WorkflowTriggerType(record.GetString("trigger"))
 	triggerCron := record.GetString("triggerCron")
-
-	// 如果非定时触发或未启用，移除定时任务
-	if !enabled || trigger != domain.WorkflowTriggerTypeScheduled.String() {
-		scheduler.Remove(buildPbJobKey(record.Id))
-		return nil
-	}
-
-	// 反之，重新添加定时任务
-	if err := registerWorkflowJob(thisSvcInst(), record.Id, triggerCron); err != nil {
-		return err
-	}
-
-	return nil
+	return syncWorkflowJob(thisSvcInst(), record.Id, enabled, trigger, triggerCron)
 }
 
 func onWorkflowRecordDelete(_ context.Context, _ core.App, record *core.Record) error {
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"time"
 
 	"github.com/pocketbase/pocketbase/tools/cron"
 	"github.com/samber/lo"
@@ -11,8 +12,12 @@ import (
 	"github.com/certimate-go/certimate/internal/app"
 	"github.com/certimate-go/certimate/internal/domain"
 	"github.com/certimate-go/certimate/internal/domain/dtos"
+	xenv "github.com/certimate-go/certimate/pkg/utils/env"
 )
 
+// 补偿错过的定时触发的时间窗口（单位：分钟），早于此窗口的触发将被忽略；为 0 时不补偿。
+var envMisfireThreshold = xenv.GetOrDefaultInt("CERTIMATE_WORKFLOW_MISFIRE_THRESHOLD", 1440)
+
 func registerWorkflowJob(workflowSrv *WorkflowService, workflowId string, triggerCron string) error {
 	scheduler := app.GetScheduler()
 
@@ -42,6 +47,73 @@ func registerWorkflowJob(workflowSrv *WorkflowService, workflowId string, trigge
 	return nil
 }
 
+// 根据工作流的触发方式及启用状态，注册或移除定时任务。
+func syncWorkflowJob(workflowSrv *WorkflowService, workflowId string, enabled bool, trigger domain.WorkflowTriggerType, triggerCron string) error {
+	// 如果非定时触发或未启用，移除定时任务
+	if !enabled || trigger != domain.WorkflowTriggerTypeScheduled {
+		app.GetScheduler().Remove(buildPbJobKey(workflowId))
+		return nil
+	}
+
+	// 反之，重新添加定时任务
+	return registerWorkflowJob(workflowSrv, workflowId, triggerCron)
+}
+
+// 补偿进程停止期间错过的定时触发。
+// 期间错过多次触发时，每个工作流也只补偿执行一次。
+func catchUpMisfiredWorkflowJobs(workflowSrv *WorkflowService, workflows []*domain.Workflow) {
+	if envMisfireThreshold <= 0 {
+		return
+	}
+
+	now := time.Now()
+	for _, workflow := range workflows {
+		if workflow.LastRunStatus == domain.WorkflowRunStatusTypePending || workflow.LastRunStatus == domain.WorkflowRunStatusTypeProcessing || workflow.LastRunStatus == domain.WorkflowRunStatusTypeWaiting {
+			continue
+		}
+
+		since := now.Add(-time.Duration(envMisfireThreshold) * time.Minute)
+		if workflow.CreatedAt.After(since) {
+			since = workflow.CreatedAt
+		}
+		if workflow.LastRunTime.After(since) {
+			since = workflow.LastRunTime
+		}
+
+		misfiredAt, ok := findLastScheduledTime(workflow.TriggerCron, since, now)
+		if !ok {
+			continue
+		}
+
+		app.GetLogger().Info(fmt.Sprintf("workflow #%s missed the scheduled trigger at %s, catching up ...", workflow.Id, misfiredAt.Format(time.RFC3339)))
+
+		_, err := workflowSrv.StartRun(context.Background(), &dtos.WorkflowStartRunReq{
+			WorkflowId: workflow.Id,
+			RunTrigger: domain.WorkflowTriggerTypeScheduled,
+		})
+		if err != nil {
+			app.GetLogger().Warn(fmt.Sprintf("failed to catch up scheduled run for workflow #%s", workflow.Id), slog.Any("error", err))
+		}
+	}
+}
+
+// 查找指定时间范围内（不含起始时间）最近一次应触发的时间。
+// 调度器自启动后的下一分钟才开始计时，因此当前分钟也应视为已错过。
+func findLastScheduledTime(triggerCron string, since time.Time, until time.Time) (time.Time, bool) {
+	schedule, err := cron.NewSchedule(triggerCron)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	for t := until.Truncate(time.Minute); t.After(since); t = t.Add(-time.Minute) {
+		if schedule.IsDue(cron.NewMoment(t.In(time.Local))) {
+			return t, true
+		}
+	}
+
+	return time.Time{}, false
+}
+
 func buildPbJobKey(workflowId string) string {
 	return fmt.Sprintf("workflow#%s", workflowId)
 }
@@ -2,31 +2,39 @@ package workflow
 
 import (
 	"context"
+	"crypto/subtle"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log/slog"
 	"time"
 
 	"github.com/pocketbase/dbx"
+	"github.com/samber/lo"
 
 	"github.com/certimate-go/certimate/internal/app"
 	"github.com/certimate-go/certimate/internal/domain"
 	"github.com/certimate-go/certimate/internal/domain/dtos"
 	"github.com/certimate-go/certimate/internal/settings"
 	"github.com/certimate-go/certimate/internal/workflow/dispatcher"
+	"github.com/certimate-go/certimate/internal/workflow/engine"
 )
 
+var errApprovalResolved = domain.NewError(400, "workflow run has already been approved, rejected or expired")
+
 type WorkflowService struct {
 	dispatcher dispatcher.WorkflowDispatcher
 
+	accessRepo      accessRepository
 	workflowRepo    workflowRepository
 	workflowRunRepo workflowRunRepository
 }
 
-func NewWorkflowService(workflowRepo workflowRepository, workflowRunRepo workflowRunRepository) *WorkflowService {
+func NewWorkflowService(accessRepo accessRepository, workflowRepo workflowRepository, workflowRunRepo workflowRunRepository) *WorkflowService {
 	srv := &WorkflowService{
 		dispatcher: dispatcher.GetSingletonDispatcher(),
 
+		accessRepo:      accessRepo,
 		workflowRepo:    workflowRepo,
 		workflowRunRepo: workflowRunRepo,
 	}
@@ -44,6 +52,11 @@ func (s *WorkflowService) InitSchedule(ctx context.Context) error {
 		panic(err)
 	}
 
+	// 每分钟检查等待审批的工作流运行是否已超时
+	app.GetScheduler().MustAdd("expireWorkflowApprovals", "* * * * *", func() {
+		s.expireWaitingRuns(context.Background())
+	})
+
 	// 注册工作流后台任务
 	{
 		workflows, err := s.workflowRepo.ListEnabledScheduled(ctx)
@@ -60,6 +73,9 @@ func (s *WorkflowService) InitSchedule(ctx context.Context) error {
 		if len(errs) > 0 {
 			return errors.Join(errs...)
 		}
+
+		// 补偿进程停止期间错过的定时触发
+		catchUpMisfiredWorkflowJobs(s, workflows)
 	}
 
 	return nil
@@ -80,32 +96,110 @@ func (s *WorkflowService) StartRun(ctx context.Context, req *dtos.WorkflowStartR
 		return nil, err
 	}
 
-	if req.RunTrigger == domain.WorkflowTriggerTypeManual && (workflow.LastRunStatus == domain.WorkflowRunStatusTypePending || workflow.LastRunStatus == domain.WorkflowRunStatusTypeProcessing) {
-		return nil, fmt.Errorf("workflow is already pending or processing")
-	} else if workflow.GraphContent == nil {
+	if workflow.GraphContent == nil {
 		return nil, fmt.Errorf("workflow graph content is empty")
 	} else if err := workflow.GraphContent.Verify(); err != nil {
 		return nil, fmt.Errorf("workflow graph content is invalid: %w", err)
 	}
 
-	workflowRun := &domain.WorkflowRun{
-		WorkflowId: workflow.Id,
-		Status:     domain.WorkflowRunStatusTypePending,
-		Trigger:    req.RunTrigger,
-		StartedAt:  time.Now(),
-		Graph:      workflow.GraphContent.Clone(),
+	// 并发策略的检查与入队须在调度器持有的触发锁内完成，以免并发触发时重复入队
+	var resp *dtos.WorkflowStartRunResp
+	err = s.dispatcher.WithTriggerLock(func() error {
+		// 按并发策略处理未结束的运行
+		if reason, err := s.applyConcurrencyPolicy(ctx, workflow); err != nil {
+			return err
+		} else if reason != "" {
+			// 手动触发时直接拒绝，由操作者自行决定是否取消未结束的运行
+			if req.RunTrigger == domain.WorkflowTriggerTypeManual {
+				return fmt.Errorf("workflow is already pending, processing or waiting")
+			}
+
+			skippedRun := &domain.WorkflowRun{
+				WorkflowId: workflow.Id,
+				Status:     domain.WorkflowRunStatusTypeSkipped,
+				Trigger:    req.RunTrigger,
+				Payload:    req.RunPayload,
+				DryRun:     req.DryRun,
+				StartedAt:  time.Now(),
+				EndedAt:    time.Now(),
+				Graph:      workflow.GraphContent.Clone(),
+				Reason:     reason,
+			}
+			// 不级联更新，以免覆盖工作流最后一次运行的状态
+			if ret, err := s.workflowRunRepo.Save(ctx, skippedRun); err != nil {
+				return err
+			} else {
+				skippedRun = ret
+			}
+
+			app.GetLogger().Info(fmt.Sprintf("workflow #%s's run #%s is skipped: %s", workflow.Id, skippedRun.Id, reason))
+			resp = &dtos.WorkflowStartRunResp{RunId: skippedRun.Id}
+			return nil
+		}
+
+		workflowRun := &domain.WorkflowRun{
+			WorkflowId: workflow.Id,
+			Status:     domain.WorkflowRunStatusTypePending,
+			Trigger:    req.RunTrigger,
+			Payload:    req.RunPayload,
+			DryRun:     req.DryRun,
+			StartedAt:  time.Now(),
+			Graph:      workflow.GraphContent.Clone(),
+		}
+		if ret, err := s.workflowRunRepo.Save(ctx, workflowRun); err != nil {
+			return err
+		} else {
+			workflowRun = ret
+		}
+
+		if err := s.dispatcher.Start(ctx, workflowRun.Id); err != nil {
+			return err
+		}
+
+		resp = &dtos.WorkflowStartRunResp{RunId: workflowRun.Id}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
-	if resp, err := s.workflowRunRepo.Save(ctx, workflowRun); err != nil {
+
+	return resp, nil
+}
+
+func (s *WorkflowService) TriggerRunByWebhook(ctx context.Context, req *dtos.WorkflowTriggerByWebhookReq) (*dtos.WorkflowTriggerByWebhookResp, error) {
+	workflow, err := s.workflowRepo.GetById(ctx, req.WorkflowId)
+	if err != nil {
 		return nil, err
-	} else {
-		workflowRun = resp
 	}
 
-	if err := s.dispatcher.Start(ctx, workflowRun.Id); err != nil {
+	if workflow.Trigger != domain.WorkflowTriggerTypeWebhook || !workflow.Enabled {
+		return nil, domain.ErrRecordNotFound
+	} else if workflow.TriggerWebhookSecret == "" {
+		return nil, fmt.Errorf("workflow webhook secret is not configured")
+	}
+
+	// 校验签名，签名内容为 "{timestamp}.{payload}"
+	if err := verifyWebhookSignature(workflow.TriggerWebhookSecret, req.Timestamp, req.Signature, req.Payload); err != nil {
+		return nil, domain.NewError(401, err.Error())
+	}
+
+	payload := make(map[string]any)
+	if len(req.Payload) > 0 {
+		if err := json.Unmarshal(req.Payload, &payload); err != nil {
+			return nil, domain.NewError(400, "invalid payload: it must be a JSON object")
+		}
+	}
+
+	resp, err := s.StartRun(ctx, &dtos.WorkflowStartRunReq{
+		WorkflowId: workflow.Id,
+		RunTrigger: domain.WorkflowTriggerTypeWebhook,
+		RunPayload: payload,
+	})
+	if err != nil {
 		return nil, err
 	}
 
-	return &dtos.WorkflowStartRunResp{RunId: workflowRun.Id}, nil
+	return &dtos.WorkflowTriggerByWebhookResp{RunId: resp.RunId}, nil
 }
 
 func (s *WorkflowService) CancelRun(ctx context.Context, req *dtos.WorkflowCancelRunReq) (*dtos.WorkflowCancelRunResp, error) {
@@ -119,8 +213,8 @@ func (s *WorkflowService) CancelRun(ctx context.Context, req *dtos.WorkflowCance
 		return nil, err
 	} else if workflowRun.WorkflowId != workflow.Id {
 		return nil, fmt.Errorf("workflow run not found")
-	} else if workflowRun.Status != domain.WorkflowRunStatusTypePending && workflowRun.Status != domain.WorkflowRunStatusTypeProcessing {
-		return nil, fmt.Errorf("workflow run is not pending or processing")
+	} else if workflowRun.Status != domain.WorkflowRunStatusTypePending && workflowRun.Status != domain.WorkflowRunStatusTypeProcessing && workflowRun.Status != domain.WorkflowRunStatusTypeWaiting {
+		return nil, fmt.Errorf("workflow run is not pending, processing or waiting")
 	}
 
 	if err := s.dispatcher.Cancel(ctx, workflowRun.Id); err != nil {
@@ -130,16 +224,199 @@ func (s *WorkflowService) CancelRun(ctx context.Context, req *dtos.WorkflowCance
 	return &dtos.WorkflowCancelRunResp{}, nil
 }
 
+func (s *WorkflowService) ResumeRun(ctx context.Context, req *dtos.WorkflowResumeRunReq) (*dtos.WorkflowResumeRunResp, error) {
+	workflow, err := s.workflowRepo.GetById(ctx, req.WorkflowId)
+	if err != nil {
+		return nil, err
+	}
+
+	failedRun, err := s.workflowRunRepo.GetById(ctx, req.RunId)
+	if err != nil {
+		return nil, err
+	} else if failedRun.WorkflowId != workflow.Id {
+		return nil, domain.ErrRecordNotFound
+	} else if (failedRun.Status != domain.WorkflowRunStatusTypeFailed && failedRun.Status != domain.WorkflowRunStatusTypeTimedOut) || failedRun.Checkpoint == nil {
+		return nil, domain.NewError(400, "workflow run is not failed or cannot be resumed")
+	} else if failedRun.Graph == nil {
+		return nil, domain.NewError(400, "workflow run graph is empty")
+	}
+
+	// 失败节点将重新执行，因此需清除其作用域内的变量（如审批状态）
+	// 新的运行拥有完整的超时时间，因此需清除已耗费的执行时间
+	checkpoint := *failedRun.Checkpoint
+	checkpoint.ElapsedMilli = 0
+	checkpoint.Variables = lo.Filter(checkpoint.Variables, func(v *domain.WorkflowRunCheckpointVariable, _ int) bool {
+		return checkpoint.NodeId == "" || v.Scope != checkpoint.NodeId
+	})
+
+	// 新建运行实体，沿用失败运行的工作流快照及执行断点，以便从失败节点处继续执行
+	workflowRun := &domain.WorkflowRun{
+		WorkflowId: workflow.Id,
+		Status:     domain.WorkflowRunStatusTypePending,
+		Trigger:    domain.WorkflowTriggerTypeManual,
+		Payload:    failedRun.Payload,
+		DryRun:     failedRun.DryRun,
+		StartedAt:  time.Now(),
+		Graph:      failedRun.Graph.Clone(),
+		Checkpoint: &checkpoint,
+	}
+	err = s.dispatcher.WithTriggerLock(func() error {
+		if activeRuns, err := s.listActiveRuns(ctx, workflow.Id); err != nil {
+			return err
+		} else if len(activeRuns) > 0 {
+			return fmt.Errorf("workflow is already pending, processing or waiting")
+		}
+
+		if ret, err := s.workflowRunRepo.Save(ctx, workflowRun); err != nil {
+			return err
+		} else {
+			workflowRun = ret
+		}
+
+		return s.dispatcher.Start(ctx, workflowRun.Id)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &dtos.WorkflowResumeRunResp{RunId: workflowRun.Id}, nil
+}
+
+func (s *WorkflowService) ApproveRun(ctx context.Context, req *dtos.WorkflowApproveRunReq) (*dtos.WorkflowApproveRunResp, error) {
+	workflowRun, err := s.workflowRunRepo.GetById(ctx, req.RunId)
+	if err != nil {
+		return nil, err
+	} else if workflowRun.WorkflowId != req.WorkflowId {
+		return nil, domain.ErrRecordNotFound
+	}
+
+	approval, ok := engine.GetPendingApproval(workflowRun.Checkpoint)
+	if !ok || workflowRun.Status != domain.WorkflowRunStatusTypeWaiting {
+		return nil, domain.NewError(400, "workflow run is not waiting for approval")
+	}
+
+	// 未经管理员鉴权时，需校验审批通知中携带的令牌
+	if !req.Authorized {
+		if req.Token == "" || subtle.ConstantTimeCompare([]byte(req.Token), []byte(approval.Token)) != 1 {
+			return nil, domain.NewError(401, "invalid approval token")
+		}
+	}
+
+	if !approval.ExpiresAt.IsZero() && time.Now().After(approval.ExpiresAt) {
+		if err := s.resumeWaitingRun(ctx, workflowRun, engine.ApprovalStatusExpired, ""); err != nil {
+			return nil, err
+		}
+		return nil, domain.NewError(400, "the approval has expired")
+	}
+
+	status := engine.ApprovalStatusRejected
+	if req.Approved {
+		status = engine.ApprovalStatusApproved
+	}
+	if err := s.resumeWaitingRun(ctx, workflowRun, status, req.Comment); err != nil {
+		return nil, err
+	}
+
+	return &dtos.WorkflowApproveRunResp{}, nil
+}
+
 func (s *WorkflowService) Shutdown(ctx context.Context) {
 	s.dispatcher.Shutdown(ctx)
 }
 
+// 根据工作流的并发策略处理未结束的运行。
+// 返回值非空时表示本次触发应被跳过，其值为跳过原因。
+// 调用方须持有调度器的触发锁。
+func (s *WorkflowService) applyConcurrencyPolicy(ctx context.Context, workflow *domain.Workflow) (string, error) {
+	activeRuns, err := s.listActiveRuns(ctx, workflow.Id)
+	if err != nil {
+		return "", err
+	} else if len(activeRuns) == 0 {
+		return "", nil
+	}
+
+	switch workflow.ConcurrencyPolicy {
+	case domain.WorkflowConcurrencyPolicyTypeAllow:
+		return "", nil
+
+	case domain.WorkflowConcurrencyPolicyTypeQueueOne:
+		if lo.ContainsBy(activeRuns, func(r *domain.WorkflowRun) bool { return r.Status == domain.WorkflowRunStatusTypePending }) {
+			return "another run is already queued", nil
+		}
+		return "", nil
+
+	case domain.WorkflowConcurrencyPolicyTypeCancelPrevious:
+		for _, activeRun := range activeRuns {
+			if err := s.dispatcher.CancelWithReason(ctx, activeRun.Id, "canceled by a newer run"); err != nil {
+				return "", err
+			}
+		}
+		return "", nil
+
+	default:
+		// 未设置时与 skip 相同
+		return "the previous run has not finished yet", nil
+	}
+}
+
+func (s *WorkflowService) listActiveRuns(ctx context.Context, workflowId string) ([]*domain.WorkflowRun, error) {
+	return s.workflowRunRepo.ListWithExprs(ctx, dbx.HashExp{
+		"workflowRef": workflowId,
+		"status": []any{
+			domain.WorkflowRunStatusTypePending.String(),
+			domain.WorkflowRunStatusTypeProcessing.String(),
+			domain.WorkflowRunStatusTypeWaiting.String(),
+		},
+	})
+}
+
+func (s *WorkflowService) resumeWaitingRun(ctx context.Context, workflowRun *domain.WorkflowRun, status engine.ApprovalStatus, comment string) error {
+	engine.ResolvePendingApproval(workflowRun.Checkpoint, status, comment)
+
+	// 审批、驳回、超时可能同时发生（超时检查运行在另一个服务实例中），仅当运行仍处于等待状态时才更新，以免重复恢复执行
+	workflowRun.Status = domain.WorkflowRunStatusTypePending
+	if matched, err := s.workflowRunRepo.UpdateStatusIfMatched(ctx, workflowRun, domain.WorkflowRunStatusTypeWaiting); err != nil {
+		return err
+	} else if !matched {
+		return errApprovalResolved
+	}
+
+	return s.dispatcher.Start(ctx, workflowRun.Id)
+}
+
+func (s *WorkflowService) expireWaitingRuns(ctx context.Context) error {
+	workflowRuns, err := s.workflowRunRepo.ListWithExprs(ctx, dbx.HashExp{"status": domain.WorkflowRunStatusTypeWaiting.String()})
+	if err != nil {
+		app.GetLogger().Error("failed to list waiting workflow runs", slog.Any("error", err))
+		return err
+	}
+
+	for _, workflowRun := range workflowRuns {
+		approval, ok := engine.GetPendingApproval(workflowRun.Checkpoint)
+		if !ok || approval.ExpiresAt.IsZero() || time.Now().Before(approval.ExpiresAt) {
+			continue
+		}
+
+		// 超时后恢复执行，由审批节点报告失败，以便被 TryCatch 节点捕获
+		if err := s.resumeWaitingRun(ctx, workflowRun, engine.ApprovalStatusExpired, ""); err != nil {
+			if errors.Is(err, errApprovalResolved) {
+				continue
+			}
+
+			app.GetLogger().Error(fmt.Sprintf("failed to expire workflow run #%s", workflowRun.Id), slog.Any("error", err))
+		}
+	}
+
+	return nil
+}
+
 func (s *WorkflowService) cleanupHistoryRuns(ctx context.Context) error {
 	globalSettingsForPersistence := settings.GetGlobalSettingsForPersistence()
 	if globalSettingsForPersistence.WorkflowRunsRetentionMaxDays != 0 {
 		ret, err := s.workflowRunRepo.DeleteWithExprs(ctx,
 			dbx.NewExp(fmt.Sprintf("status!='%s'", domain.WorkflowRunStatusTypePending)),
 			dbx.NewExp(fmt.Sprintf("status!='%s'", domain.WorkflowRunStatusTypeProcessing)),
+			dbx.NewExp(fmt.Sprintf("status!='%s'", domain.WorkflowRunStatusTypeWaiting)),
 			dbx.NewExp(fmt.Sprintf("endedAt<DATETIME('now', '-%d days')", globalSettingsForPersistence.WorkflowRunsRetentionMaxDays)),
 		)
 		if err != nil {
@@ -8,6 +8,10 @@ import (
 	"github.com/certimate-go/certimate/internal/domain"
 )
 
+type accessRepository interface {
+	ListWithExprs(ctx context.Context, exprs ...dbx.Expression) ([]*domain.Access, error)
+}
+
 type workflowRepository interface {
 	ListEnabledScheduled(ctx context.Context) ([]*domain.Workflow, error)
 	GetById(ctx context.Context, id string) (*domain.Workflow, error)
@@ -15,8 +19,10 @@ type workflowRepository interface {
 }
 
 type workflowRunRepository interface {
+	ListWithExprs(ctx context.Context, exprs ...dbx.Expression) ([]*domain.WorkflowRun, error)
 	GetById(ctx context.Context, id string) (*domain.WorkflowRun, error)
 	Save(ctx context.Context, workflowRun *domain.WorkflowRun) (*domain.WorkflowRun, error)
 	SaveWithCascading(ctx context.Context, workflowRun *domain.WorkflowRun) (*domain.WorkflowRun, error)
+	UpdateStatusIfMatched(ctx context.Context, workflowRun *domain.WorkflowRun, expectedStatus domain.WorkflowRunStatusType) (bool, error)
 	DeleteWithExprs(ctx context.Context, exprs ...dbx.Expression) (int, error)
 }
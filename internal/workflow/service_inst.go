@@ -14,6 +14,7 @@ var (
 func thisSvcInst() *WorkflowService {
 	thisSvcOnce.Do(func() {
 		thisSvc = NewWorkflowService(
+			repository.NewAccessRepository(),
 			repository.NewWorkflowRepository(),
 			repository.NewWorkflowRunRepository(),
 		)
@@ -0,0 +1,138 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pocketbase/dbx"
+
+	"github.com/certimate-go/certimate/internal/domain"
+	"github.com/certimate-go/certimate/internal/domain/dtos"
+	"github.com/certimate-go/certimate/internal/workflow/dispatcher"
+)
+
+type mockDispatcher struct {
+	dispatcher.WorkflowDispatcher
+
+	triggerMtx sync.Mutex
+	startedMtx sync.Mutex
+	started    []string
+}
+
+func (d *mockDispatcher) Start(ctx context.Context, runId string) error {
+	d.startedMtx.Lock()
+	defer d.startedMtx.Unlock()
+
+	d.started = append(d.started, runId)
+	return nil
+}
+
+func (d *mockDispatcher) WithTriggerLock(fn func() error) error {
+	d.triggerMtx.Lock()
+	defer d.triggerMtx.Unlock()
+
+	return fn()
+}
+
+type mockWorkflowRepository struct {
+	workflowRepository
+
+	workflow *domain.Workflow
+}
+
+func (r *mockWorkflowRepository) GetById(ctx context.Context, id string) (*domain.Workflow, error) {
+	if r.workflow.Id != id {
+		return nil, domain.ErrRecordNotFound
+	}
+	return r.workflow, nil
+}
+
+type mockWorkflowRunRepository struct {
+	workflowRunRepository
+
+	mtx  sync.Mutex
+	runs []*domain.WorkflowRun
+}
+
+func (r *mockWorkflowRunRepository) ListWithExprs(ctx context.Context, exprs ...dbx.Expression) ([]*domain.WorkflowRun, error) {
+	r.mtx.Lock()
+	activeRuns := make([]*domain.WorkflowRun, 0)
+	for _, run := range r.runs {
+		if slices.Contains([]domain.WorkflowRunStatusType{domain.WorkflowRunStatusTypePending, domain.WorkflowRunStatusTypeProcessing, domain.WorkflowRunStatusTypeWaiting}, run.Status) {
+			activeRuns = append(activeRuns, run)
+		}
+	}
+	r.mtx.Unlock()
+
+	// 放大查询与写入之间的时间窗口，以便暴露竞态
+	time.Sleep(5 * time.Millisecond)
+	return activeRuns, nil
+}
+
+func (r *mockWorkflowRunRepository) Save(ctx context.Context, workflowRun *domain.WorkflowRun) (*domain.WorkflowRun, error) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	workflowRun.Id = fmt.Sprintf("run_%d", len(r.runs)+1)
+	r.runs = append(r.runs, workflowRun)
+	return workflowRun, nil
+}
+
+func TestStartRunConcurrently(t *testing.T) {
+	workflowRepo := &mockWorkflowRepository{
+		workflow: &domain.Workflow{
+			Meta: domain.Meta{Id: "wf_1"},
+			GraphContent: &domain.WorkflowGraph{
+				Nodes: []*domain.WorkflowNode{
+					{Id: "start", Type: domain.WorkflowNodeTypeStart},
+					{Id: "end", Type: domain.WorkflowNodeTypeEnd},
+				},
+			},
+			ConcurrencyPolicy: domain.WorkflowConcurrencyPolicyTypeSkip,
+		},
+	}
+	workflowRunRepo := &mockWorkflowRunRepository{}
+	dispatcher := &mockDispatcher{}
+
+	// 与实际运行时一致，定时任务、路由等各自持有一个服务实例，但共享同一个调度器
+	services := make([]*WorkflowService, 3)
+	for i := range services {
+		services[i] = &WorkflowService{dispatcher: dispatcher, workflowRepo: workflowRepo, workflowRunRepo: workflowRunRepo}
+	}
+
+	const triggers = 20
+	var wg sync.WaitGroup
+	var errsMtx sync.Mutex
+	var errs []error
+	for i := 0; i < triggers; i++ {
+		wg.Add(1)
+		go func(srv *WorkflowService) {
+			defer wg.Done()
+
+			_, err := srv.StartRun(context.Background(), &dtos.WorkflowStartRunReq{
+				WorkflowId: "wf_1",
+				RunTrigger: domain.WorkflowTriggerTypeManual,
+			})
+			if err != nil {
+				errsMtx.Lock()
+				errs = append(errs, err)
+				errsMtx.Unlock()
+			}
+		}(services[i%len(services)])
+	}
+	wg.Wait()
+
+	if len(dispatcher.started) != 1 {
+		t.Errorf("expected 1 run to be started, got %d", len(dispatcher.started))
+	}
+	if len(workflowRunRepo.runs) != 1 {
+		t.Errorf("expected 1 run to be saved, got %d", len(workflowRunRepo.runs))
+	}
+	if len(errs) != triggers-1 {
+		t.Errorf("expected %d triggers to be rejected, got %d", triggers-1, len(errs))
+	}
+}
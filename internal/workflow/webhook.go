@@ -0,0 +1,67 @@
+package workflow
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pocketbase/pocketbase/tools/security"
+)
+
+const webhookSignatureTolerance = 5 * time.Minute
+
+func generateWebhookSecret() string {
+	return security.RandomString(40)
+}
+
+func signWebhookPayload(secret string, timestamp string, payload []byte) string {
+	return hex.EncodeToString(computeWebhookMAC(secret, timestamp, payload))
+}
+
+func computeWebhookMAC(secret string, timestamp string, payload []byte) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
+
+func verifyWebhookSignature(secret string, timestamp string, signature string, payload []byte) error {
+	if timestamp == "" {
+		return errors.New("missing webhook timestamp")
+	}
+	if signature == "" {
+		return errors.New("missing webhook signature")
+	}
+
+	// 时间戳为 Unix 秒，用于防止重放攻击
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return errors.New("invalid webhook timestamp")
+	}
+
+	diff := time.Since(time.Unix(unix, 0))
+	if diff < 0 {
+		diff = -diff
+	}
+	if diff > webhookSignatureTolerance {
+		return errors.New("webhook timestamp is out of tolerance")
+	}
+
+	// 比较解码后的 MAC 而非十六进制字符串，并使用常量时间比较以防止时序攻击
+	actual, err := hex.DecodeString(strings.TrimPrefix(strings.ToLower(strings.TrimSpace(signature)), "sha256="))
+	if err != nil {
+		return errors.New("invalid webhook signature")
+	}
+
+	expected := computeWebhookMAC(secret, timestamp, payload)
+	if !hmac.Equal(expected, actual) {
+		return errors.New("invalid webhook signature")
+	}
+
+	return nil
+}
@@ -0,0 +1,117 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/certimate-go/certimate/internal/domain"
+	"github.com/certimate-go/certimate/internal/domain/dtos"
+)
+
+func TestVerifyWebhookSignature(t *testing.T) {
+	const secret = "my-webhook-secret"
+	payload := []byte(`{"ref":"refs/heads/main"}`)
+
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+	stale := strconv.FormatInt(time.Now().Add(-webhookSignatureTolerance-time.Minute).Unix(), 10)
+	future := strconv.FormatInt(time.Now().Add(webhookSignatureTolerance+time.Minute).Unix(), 10)
+	recent := strconv.FormatInt(time.Now().Add(-webhookSignatureTolerance+time.Minute).Unix(), 10)
+
+	testCases := []struct {
+		name      string
+		timestamp string
+		signature string
+		payload   []byte
+		wantErr   bool
+	}{
+		{name: "valid", timestamp: now, signature: signWebhookPayload(secret, now, payload), payload: payload},
+		{name: "valid with prefix", timestamp: now, signature: "sha256=" + strings.ToUpper(signWebhookPayload(secret, now, payload)), payload: payload},
+		{name: "valid within tolerance", timestamp: recent, signature: signWebhookPayload(secret, recent, payload), payload: payload},
+		{name: "wrong secret", timestamp: now, signature: signWebhookPayload("other-secret", now, payload), payload: payload, wantErr: true},
+		{name: "tampered payload", timestamp: now, signature: signWebhookPayload(secret, now, payload), payload: []byte(`{"ref":"refs/heads/dev"}`), wantErr: true},
+		{name: "tampered timestamp", timestamp: recent, signature: signWebhookPayload(secret, now, payload), payload: payload, wantErr: true},
+		{name: "truncated signature", timestamp: now, signature: signWebhookPayload(secret, now, payload)[:32], payload: payload, wantErr: true},
+		{name: "malformed signature", timestamp: now, signature: "not-a-hex-string", payload: payload, wantErr: true},
+		{name: "missing signature", timestamp: now, signature: "", payload: payload, wantErr: true},
+		{name: "missing timestamp", timestamp: "", signature: signWebhookPayload(secret, "", payload), payload: payload, wantErr: true},
+		{name: "malformed timestamp", timestamp: "yesterday", signature: signWebhookPayload(secret, "yesterday", payload), payload: payload, wantErr: true},
+		{name: "replayed", timestamp: stale, signature: signWebhookPayload(secret, stale, payload), payload: payload, wantErr: true},
+		{name: "from the future", timestamp: future, signature: signWebhookPayload(secret, future, payload), payload: payload, wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := verifyWebhookSignature(secret, tc.timestamp, tc.signature, tc.payload)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("expected error: %v, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestTriggerRunByWebhook(t *testing.T) {
+	const secret = "my-webhook-secret"
+	payload := []byte(`{"ref":"refs/heads/main"}`)
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+
+	testCases := []struct {
+		name      string
+		timestamp string
+		signature string
+		wantCode  int
+	}{
+		{name: "valid", timestamp: now, signature: signWebhookPayload(secret, now, payload)},
+		{name: "bad signature", timestamp: now, signature: signWebhookPayload("other-secret", now, payload), wantCode: 401},
+		{name: "missing headers", wantCode: 401},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			workflowRepo := &mockWorkflowRepository{
+				workflow: &domain.Workflow{
+					Meta:                 domain.Meta{Id: "wf_1"},
+					Trigger:              domain.WorkflowTriggerTypeWebhook,
+					TriggerWebhookSecret: secret,
+					Enabled:              true,
+					GraphContent: &domain.WorkflowGraph{
+						Nodes: []*domain.WorkflowNode{
+							{Id: "start", Type: domain.WorkflowNodeTypeStart},
+							{Id: "end", Type: domain.WorkflowNodeTypeEnd},
+						},
+					},
+				},
+			}
+			workflowRunRepo := &mockWorkflowRunRepository{}
+			dispatcher := &mockDispatcher{}
+			srv := &WorkflowService{dispatcher: dispatcher, workflowRepo: workflowRepo, workflowRunRepo: workflowRunRepo}
+
+			_, err := srv.TriggerRunByWebhook(context.Background(), &dtos.WorkflowTriggerByWebhookReq{
+				WorkflowId: "wf_1",
+				Timestamp:  tc.timestamp,
+				Signature:  tc.signature,
+				Payload:    payload,
+			})
+			if tc.wantCode == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if len(workflowRunRepo.runs) != 1 || workflowRunRepo.runs[0].Payload["ref"] != "refs/heads/main" {
+					t.Errorf("expected 1 run to be saved with the payload, got %v", workflowRunRepo.runs)
+				}
+				return
+			}
+
+			var derr *domain.Error
+			if !errors.As(err, &derr) || derr.Code != tc.wantCode {
+				t.Errorf("expected error code %d, got %v", tc.wantCode, err)
+			}
+			if len(dispatcher.started) != 0 || len(workflowRunRepo.runs) != 0 {
+				t.Errorf("expected no run to be started")
+			}
+		})
+	}
+}
@@ -14,7 +14,9 @@ import (
 	"github.com/spf13/pflag"
 
 	"github.com/certimate-go/certimate/cmd"
+	"github.com/certimate-go/certimate/internal/acmedns"
 	"github.com/certimate-go/certimate/internal/app"
+	"github.com/certimate-go/certimate/internal/encryption"
 	"github.com/certimate-go/certimate/internal/rest/routes"
 	"github.com/certimate-go/certimate/internal/scheduler"
 	"github.com/certimate-go/certimate/internal/settings"
@@ -36,10 +38,21 @@ func main() {
 		Automigrate: strings.HasPrefix(os.Args[0], os.TempDir()),
 	})
 
+	pb.RootCmd.AddCommand(cmd.NewCertCommand(pb))
+	pb.RootCmd.AddCommand(cmd.NewEncryptionCommand(pb))
 	pb.RootCmd.AddCommand(cmd.NewInternalCommand(pb))
+	pb.RootCmd.AddCommand(cmd.NewNotifyCommand(pb))
 	pb.RootCmd.AddCommand(cmd.NewVersionCommand(pb))
 	pb.RootCmd.AddCommand(cmd.NewWinscCommand(pb))
 
+	// 独立命令无需初始化 PocketBase（即不会读写数据库），直接执行即可
+	if cmd.IsStandaloneCommand(os.Args[1]) {
+		if err := pb.RootCmd.Execute(); err != nil {
+			os.Exit(1)
+		}
+		return
+	}
+
 	isServeCmd := os.Args[1] == "serve"
 
 	if isServeCmd {
@@ -69,8 +82,10 @@ func main() {
 		})
 
 		pb.OnServe().BindFunc(func(e *core.ServeEvent) error {
+			encryption.Setup()
 			scheduler.Setup()
 			workflow.Setup()
+			acmedns.Setup()
 			routes.BindRouter(e.Router)
 
 			if err := e.Next(); err != nil {
@@ -84,6 +99,7 @@ func main() {
 		pb.OnTerminate().BindFunc(func(e *core.TerminateEvent) error {
 			if pb.IsBootstrapped() {
 				workflow.Teardown()
+				acmedns.Teardown()
 			}
 
 			return e.Next()
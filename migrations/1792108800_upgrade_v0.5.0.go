@@ -0,0 +1,951 @@
+package migrations
+
+import (
+	"errors"
+	"maps"
+	"slices"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+	"github.com/pocketbase/pocketbase/tools/types"
+
+	"github.com/certimate-go/certimate/internal/domain"
+	"github.com/certimate-go/certimate/internal/encryption"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		tracer := NewTracer("v0.5.0")
+		tracer.Printf("go ...")
+
+		// update collection `workflow`
+		//   - modify field `trigger` candidates
+		//   - add field `triggerWebhookSecret`
+		//   - modify field `lastRunStatus` candidates
+		{
+			collection, err := app.FindCollectionByNameOrId("tovyif5ax6j62ur")
+			if err != nil {
+				return err
+			}
+
+			if err := collection.Fields.AddMarshaledJSONAt(3, []byte(`{
+				"hidden": false,
+				"id": "vqoajwjq",
+				"maxSelect": 1,
+				"name": "trigger",
+				"presentable": false,
+				"required": false,
+				"system": false,
+				"type": "select",
+				"values": [
+					"manual",
+					"scheduled",
+					"webhook"
+				]
+			}`)); err != nil {
+				return err
+			}
+
+			if err := collection.Fields.AddMarshaledJSONAt(5, []byte(`{
+				"autogeneratePattern": "",
+				"hidden": false,
+				"id": "text2547214304",
+				"max": 0,
+				"min": 0,
+				"name": "triggerWebhookSecret",
+				"pattern": "",
+				"presentable": false,
+				"primaryKey": false,
+				"required": false,
+				"system": false,
+				"type": "text"
+			}`)); err != nil {
+				return err
+			}
+
+			if err := collection.Fields.AddMarshaledJSONAt(12, []byte(`{
+				"hidden": false,
+				"id": "zivdxh23",
+				"maxSelect": 1,
+				"name": "lastRunStatus",
+				"presentable": false,
+				"required": false,
+				"system": false,
+				"type": "select",
+				"values": [
+					"pending",
+					"processing",
+					"waiting",
+					"succeeded",
+					"failed",
+					"canceled",
+					"skipped",
+					"timedout"
+				]
+			}`)); err != nil {
+				return err
+			}
+
+			if err := app.Save(collection); err != nil {
+				return err
+			}
+
+			tracer.Printf("collection '%s' updated", collection.Name)
+		}
+
+		// update collection `workflow_run`
+		//   - modify field `status` candidates
+		//   - modify field `trigger` candidates
+		//   - add field `payload`
+		//   - add field `dryRun`
+		//   - add field `checkpoint`
+		//   - add field `reason`
+		{
+			collection, err := app.FindCollectionByNameOrId("qjp8lygssgwyqyz")
+			if err != nil {
+				return err
+			}
+
+			if err := collection.Fields.AddMarshaledJSONAt(2, []byte(`{
+				"hidden": false,
+				"id": "qldmh0tw",
+				"maxSelect": 1,
+				"name": "status",
+				"presentable": false,
+				"required": false,
+				"system": false,
+				"type": "select",
+				"values": [
+					"pending",
+					"processing",
+					"waiting",
+					"succeeded",
+					"failed",
+					"canceled",
+					"skipped",
+					"timedout"
+				]
+			}`)); err != nil {
+				return err
+			}
+
+			if err := collection.Fields.AddMarshaledJSONAt(3, []byte(`{
+				"hidden": false,
+				"id": "jlroa3fk",
+				"maxSelect": 1,
+				"name": "trigger",
+				"presentable": false,
+				"required": false,
+				"system": false,
+				"type": "select",
+				"values": [
+					"manual",
+					"scheduled",
+					"webhook"
+				]
+			}`)); err != nil {
+				return err
+			}
+
+			if err := collection.Fields.AddMarshaledJSONAt(4, []byte(`{
+				"hidden": false,
+				"id": "json1110206997",
+				"maxSize": 0,
+				"name": "payload",
+				"presentable": false,
+				"required": false,
+				"system": false,
+				"type": "json"
+			}`)); err != nil {
+				return err
+			}
+
+			if err := collection.Fields.AddMarshaledJSONAt(5, []byte(`{
+				"hidden": false,
+				"id": "bool553159524",
+				"name": "dryRun",
+				"presentable": false,
+				"required": false,
+				"system": false,
+				"type": "bool"
+			}`)); err != nil {
+				return err
+			}
+
+			if err := collection.Fields.AddMarshaledJSONAt(10, []byte(`{
+				"hidden": false,
+				"id": "json251721662",
+				"maxSize": 0,
+				"name": "checkpoint",
+				"presentable": false,
+				"required": false,
+				"system": false,
+				"type": "json"
+			}`)); err != nil {
+				return err
+			}
+
+			if err := collection.Fields.AddMarshaledJSONAt(11, []byte(`{
+				"autogeneratePattern": "",
+				"hidden": false,
+				"id": "text1001949196",
+				"max": 0,
+				"min": 0,
+				"name": "reason",
+				"pattern": "",
+				"presentable": false,
+				"primaryKey": false,
+				"required": false,
+				"system": false,
+				"type": "text"
+			}`)); err != nil {
+				return err
+			}
+
+			if err := app.Save(collection); err != nil {
+				return err
+			}
+
+			tracer.Printf("collection '%s' updated", collection.Name)
+		}
+
+		// create collection `discovered_certificate`
+		{
+			jsonData := `[
+				{
+					"createRule": null,
+					"deleteRule": null,
+					"fields": [
+						{
+							"autogeneratePattern": "[a-z0-9]{15}",
+							"hidden": false,
+							"id": "text3208210256",
+							"max": 15,
+							"min": 15,
+							"name": "id",
+							"pattern": "^[a-z0-9]+$",
+							"presentable": false,
+							"primaryKey": true,
+							"required": true,
+							"system": true,
+							"type": "text"
+						},
+						{
+							"autogeneratePattern": "",
+							"hidden": false,
+							"id": "text3475444733",
+							"max": 0,
+							"min": 0,
+							"name": "host",
+							"pattern": "",
+							"presentable": false,
+							"primaryKey": false,
+							"required": false,
+							"system": false,
+							"type": "text"
+						},
+						{
+							"autogeneratePattern": "",
+							"hidden": false,
+							"id": "text223244161",
+							"max": 0,
+							"min": 0,
+							"name": "address",
+							"pattern": "",
+							"presentable": false,
+							"primaryKey": false,
+							"required": false,
+							"system": false,
+							"type": "text"
+						},
+						{
+							"hidden": false,
+							"id": "number1133600204",
+							"max": null,
+							"min": null,
+							"name": "port",
+							"onlyInt": true,
+							"presentable": false,
+							"required": false,
+							"system": false,
+							"type": "number"
+						},
+						{
+							"hidden": false,
+							"id": "select3368074316",
+							"maxSelect": 1,
+							"name": "protocol",
+							"presentable": false,
+							"required": false,
+							"system": false,
+							"type": "select",
+							"values": [
+								"tls",
+								"smtp",
+								"imap",
+								"pop3",
+								"ftp"
+							]
+						},
+						{
+							"autogeneratePattern": "",
+							"hidden": false,
+							"id": "text2377368216",
+							"max": 0,
+							"min": 0,
+							"name": "serverName",
+							"pattern": "",
+							"presentable": false,
+							"primaryKey": false,
+							"required": false,
+							"system": false,
+							"type": "text"
+						},
+						{
+							"autogeneratePattern": "",
+							"hidden": false,
+							"id": "text2069360702",
+							"max": 0,
+							"min": 0,
+							"name": "serialNumber",
+							"pattern": "",
+							"presentable": false,
+							"primaryKey": false,
+							"required": false,
+							"system": false,
+							"type": "text"
+						},
+						{
+							"autogeneratePattern": "",
+							"hidden": false,
+							"id": "text2876278798",
+							"max": 0,
+							"min": 0,
+							"name": "subjectName",
+							"pattern": "",
+							"presentable": false,
+							"primaryKey": false,
+							"required": false,
+							"system": false,
+							"type": "text"
+						},
+						{
+							"autogeneratePattern": "",
+							"hidden": false,
+							"id": "text2024450266",
+							"max": 0,
+							"min": 0,
+							"name": "subjectAltNames",
+							"pattern": "",
+							"presentable": false,
+							"primaryKey": false,
+							"required": false,
+							"system": false,
+							"type": "text"
+						},
+						{
+							"autogeneratePattern": "",
+							"hidden": false,
+							"id": "text2678583873",
+							"max": 0,
+							"min": 0,
+							"name": "issuerName",
+							"pattern": "",
+							"presentable": false,
+							"primaryKey": false,
+							"required": false,
+							"system": false,
+							"type": "text"
+						},
+						{
+							"autogeneratePattern": "",
+							"hidden": false,
+							"id": "text2542032574",
+							"max": 0,
+							"min": 0,
+							"name": "issuerOrg",
+							"pattern": "",
+							"presentable": false,
+							"primaryKey": false,
+							"required": false,
+							"system": false,
+							"type": "text"
+						},
+						{
+							"autogeneratePattern": "",
+							"hidden": false,
+							"id": "text4164403445",
+							"max": 0,
+							"min": 0,
+							"name": "keyAlgorithm",
+							"pattern": "",
+							"presentable": false,
+							"primaryKey": false,
+							"required": false,
+							"system": false,
+							"type": "text"
+						},
+						{
+							"hidden": false,
+							"id": "date4009297485",
+							"max": "",
+							"min": "",
+							"name": "validityNotBefore",
+							"presentable": false,
+							"required": false,
+							"system": false,
+							"type": "date"
+						},
+						{
+							"hidden": false,
+							"id": "date1213553643",
+							"max": "",
+							"min": "",
+							"name": "validityNotAfter",
+							"presentable": false,
+							"required": false,
+							"system": false,
+							"type": "date"
+						},
+						{
+							"autogeneratePattern": "",
+							"hidden": false,
+							"id": "text563927626",
+							"max": 100000,
+							"min": 0,
+							"name": "certificate",
+							"pattern": "",
+							"presentable": false,
+							"primaryKey": false,
+							"required": false,
+							"system": false,
+							"type": "text"
+						},
+						{
+							"hidden": false,
+							"id": "number2292655801",
+							"max": null,
+							"min": null,
+							"name": "chainLength",
+							"onlyInt": true,
+							"presentable": false,
+							"required": false,
+							"system": false,
+							"type": "number"
+						},
+						{
+							"cascadeDelete": false,
+							"collectionId": "4szxr9x43tpj6np",
+							"hidden": false,
+							"id": "relation3544285838",
+							"maxSelect": 1,
+							"minSelect": 0,
+							"name": "certificateRef",
+							"presentable": false,
+							"required": false,
+							"system": false,
+							"type": "relation"
+						},
+						{
+							"hidden": false,
+							"id": "date835380011",
+							"max": "",
+							"min": "",
+							"name": "firstSeenAt",
+							"presentable": false,
+							"required": false,
+							"system": false,
+							"type": "date"
+						},
+						{
+							"hidden": false,
+							"id": "date1925370682",
+							"max": "",
+							"min": "",
+							"name": "lastSeenAt",
+							"presentable": false,
+							"required": false,
+							"system": false,
+							"type": "date"
+						},
+						{
+							"hidden": false,
+							"id": "autodate2990389176",
+							"name": "created",
+							"onCreate": true,
+							"onUpdate": false,
+							"presentable": false,
+							"system": false,
+							"type": "autodate"
+						},
+						{
+							"hidden": false,
+							"id": "autodate3332085495",
+							"name": "updated",
+							"onCreate": true,
+							"onUpdate": true,
+							"presentable": false,
+							"system": false,
+							"type": "autodate"
+						}
+					],
+					"id": "pbc_987736826",
+					"indexes": [
+						"CREATE INDEX ` + "`" + `idx_Hq3vN8xKcd` + "`" + ` ON ` + "`" + `discovered_certificate` + "`" + ` (` + "`" + `host` + "`" + `, ` + "`" + `port` + "`" + `, ` + "`" + `serverName` + "`" + `)",
+						"CREATE INDEX ` + "`" + `idx_Rk2mZp7WsT` + "`" + ` ON ` + "`" + `discovered_certificate` + "`" + ` (` + "`" + `serialNumber` + "`" + `)",
+						"CREATE INDEX ` + "`" + `idx_Ue9bLf4QyA` + "`" + ` ON ` + "`" + `discovered_certificate` + "`" + ` (` + "`" + `certificateRef` + "`" + `)"
+					],
+					"listRule": null,
+					"name": "discovered_certificate",
+					"system": false,
+					"type": "base",
+					"updateRule": null,
+					"viewRule": null
+				}
+			]`
+
+			if err := app.ImportCollectionsByMarshaledJSON([]byte(jsonData), false); err != nil {
+				return err
+			}
+
+			tracer.Printf("collection 'discovered_certificate' created")
+		}
+
+		// update collection `certificate`
+		//   - add field `expiryAlertedDays`
+		{
+			collection, err := app.FindCollectionByNameOrId("4szxr9x43tpj6np")
+			if err != nil {
+				return err
+			}
+
+			if err := collection.Fields.AddMarshaledJSONAt(21, []byte(`{
+				"hidden": false,
+				"id": "number3354393033",
+				"max": null,
+				"min": null,
+				"name": "expiryAlertedDays",
+				"onlyInt": true,
+				"presentable": false,
+				"required": false,
+				"system": false,
+				"type": "number"
+			}`)); err != nil {
+				return err
+			}
+
+			if err := app.Save(collection); err != nil {
+				return err
+			}
+
+			tracer.Printf("collection '%s' updated", collection.Name)
+		}
+
+		// update collection `certificate`
+		//   - add field `predecessorRef`
+		//   - add field `replacedByRef`
+		//   - migrate field `predecessorRef` and `replacedByRef`
+		{
+			collection, err := app.FindCollectionByNameOrId("4szxr9x43tpj6np")
+			if err != nil {
+				return err
+			}
+
+			if err := collection.Fields.AddMarshaledJSONAt(22, []byte(`{
+				"cascadeDelete": false,
+				"collectionId": "4szxr9x43tpj6np",
+				"hidden": false,
+				"id": "relation581614950",
+				"maxSelect": 1,
+				"minSelect": 0,
+				"name": "predecessorRef",
+				"presentable": false,
+				"required": false,
+				"system": false,
+				"type": "relation"
+			}`)); err != nil {
+				return err
+			}
+
+			if err := collection.Fields.AddMarshaledJSONAt(23, []byte(`{
+				"cascadeDelete": false,
+				"collectionId": "4szxr9x43tpj6np",
+				"hidden": false,
+				"id": "relation3894705031",
+				"maxSelect": 1,
+				"minSelect": 0,
+				"name": "replacedByRef",
+				"presentable": false,
+				"required": false,
+				"system": false,
+				"type": "relation"
+			}`)); err != nil {
+				return err
+			}
+
+			if err := app.Save(collection); err != nil {
+				return err
+			}
+
+			// 同一工作流节点先后生成的证书，按生成时间依次建立替换关系
+			type certificateRow struct {
+				Id             string         `db:"id"`
+				Created        types.DateTime `db:"created"`
+				WorkflowRef    string         `db:"workflowRef"`
+				WorkflowNodeId string         `db:"workflowNodeId"`
+				PredecessorRef string         `db:"predecessorRef"`
+				ReplacedByRef  string         `db:"replacedByRef"`
+			}
+			rows := make([]*certificateRow, 0)
+			if err := app.DB().
+				Select("id", "created", "workflowRef", "workflowNodeId", "predecessorRef", "replacedByRef").
+				From(collection.Name).
+				Where(dbx.NewExp("workflowRef!='' AND workflowNodeId!=''")).
+				All(&rows); err != nil {
+				return err
+			}
+
+			certificates := make([]*domain.Certificate, 0, len(rows))
+			for _, row := range rows {
+				certificates = append(certificates, &domain.Certificate{
+					Meta:           domain.Meta{Id: row.Id, CreatedAt: row.Created.Time()},
+					WorkflowId:     row.WorkflowRef,
+					WorkflowNodeId: row.WorkflowNodeId,
+					PredecessorId:  row.PredecessorRef,
+					ReplacedById:   row.ReplacedByRef,
+				})
+			}
+
+			for _, certificate := range domain.LinkCertificateLineage(certificates) {
+				if _, err := app.DB().Update(collection.Name, dbx.Params{"predecessorRef": certificate.PredecessorId, "replacedByRef": certificate.ReplacedById}, dbx.HashExp{"id": certificate.Id}).Execute(); err != nil {
+					return err
+				}
+
+				tracer.Printf("record #%s in collection '%s' updated", certificate.Id, collection.Name)
+			}
+
+			tracer.Printf("collection '%s' updated", collection.Name)
+		}
+
+		// create collection `certificate_deployment`
+		{
+			jsonData := `[
+				{
+					"createRule": null,
+					"deleteRule": null,
+					"fields": [
+						{
+							"autogeneratePattern": "[a-z0-9]{15}",
+							"hidden": false,
+							"id": "text3208210256",
+							"max": 15,
+							"min": 15,
+							"name": "id",
+							"pattern": "^[a-z0-9]+$",
+							"presentable": false,
+							"primaryKey": true,
+							"required": true,
+							"system": true,
+							"type": "text"
+						},
+						{
+							"cascadeDelete": true,
+							"collectionId": "4szxr9x43tpj6np",
+							"hidden": false,
+							"id": "relation3544285838",
+							"maxSelect": 1,
+							"minSelect": 0,
+							"name": "certificateRef",
+							"presentable": false,
+							"required": false,
+							"system": false,
+							"type": "relation"
+						},
+						{
+							"autogeneratePattern": "",
+							"hidden": false,
+							"id": "text2069360702",
+							"max": 0,
+							"min": 0,
+							"name": "serialNumber",
+							"pattern": "",
+							"presentable": false,
+							"primaryKey": false,
+							"required": false,
+							"system": false,
+							"type": "text"
+						},
+						{
+							"autogeneratePattern": "",
+							"hidden": false,
+							"id": "text2462348188",
+							"max": 0,
+							"min": 0,
+							"name": "provider",
+							"pattern": "",
+							"presentable": false,
+							"primaryKey": false,
+							"required": false,
+							"system": false,
+							"type": "text"
+						},
+						{
+							"cascadeDelete": false,
+							"collectionId": "4yzbv8urny5ja1e",
+							"hidden": false,
+							"id": "relation3791287190",
+							"maxSelect": 1,
+							"minSelect": 0,
+							"name": "providerAccessRef",
+							"presentable": false,
+							"required": false,
+							"system": false,
+							"type": "relation"
+						},
+						{
+							"hidden": false,
+							"id": "json4748194",
+							"maxSize": 0,
+							"name": "providerConfig",
+							"presentable": false,
+							"required": false,
+							"system": false,
+							"type": "json"
+						},
+						{
+							"autogeneratePattern": "",
+							"hidden": false,
+							"id": "text3239191327",
+							"max": 0,
+							"min": 0,
+							"name": "targetKey",
+							"pattern": "",
+							"presentable": false,
+							"primaryKey": false,
+							"required": false,
+							"system": false,
+							"type": "text"
+						},
+						{
+							"hidden": false,
+							"id": "bool3123996619",
+							"name": "isCurrent",
+							"presentable": false,
+							"required": false,
+							"system": false,
+							"type": "bool"
+						},
+						{
+							"cascadeDelete": false,
+							"collectionId": "tovyif5ax6j62ur",
+							"hidden": false,
+							"id": "relation1713344969",
+							"maxSelect": 1,
+							"minSelect": 0,
+							"name": "workflowRef",
+							"presentable": false,
+							"required": false,
+							"system": false,
+							"type": "relation"
+						},
+						{
+							"cascadeDelete": false,
+							"collectionId": "qjp8lygssgwyqyz",
+							"hidden": false,
+							"id": "relation4066040873",
+							"maxSelect": 1,
+							"minSelect": 0,
+							"name": "workflowRunRef",
+							"presentable": false,
+							"required": false,
+							"system": false,
+							"type": "relation"
+						},
+						{
+							"autogeneratePattern": "",
+							"hidden": false,
+							"id": "text901285913",
+							"max": 0,
+							"min": 0,
+							"name": "workflowNodeId",
+							"pattern": "",
+							"presentable": false,
+							"primaryKey": false,
+							"required": false,
+							"system": false,
+							"type": "text"
+						},
+						{
+							"hidden": false,
+							"id": "autodate2990389176",
+							"name": "created",
+							"onCreate": true,
+							"onUpdate": false,
+							"presentable": false,
+							"system": false,
+							"type": "autodate"
+						},
+						{
+							"hidden": false,
+							"id": "autodate3332085495",
+							"name": "updated",
+							"onCreate": true,
+							"onUpdate": true,
+							"presentable": false,
+							"system": false,
+							"type": "autodate"
+						}
+					],
+					"id": "pbc_3991939279",
+					"indexes": [
+						"CREATE INDEX ` + "`" + `idx_Wc5tNj8PxE` + "`" + ` ON ` + "`" + `certificate_deployment` + "`" + ` (` + "`" + `certificateRef` + "`" + `)",
+						"CREATE INDEX ` + "`" + `idx_Bd3qKm6VrY` + "`" + ` ON ` + "`" + `certificate_deployment` + "`" + ` (` + "`" + `serialNumber` + "`" + `)",
+						"CREATE INDEX ` + "`" + `idx_Gh7zRs2LuN` + "`" + ` ON ` + "`" + `certificate_deployment` + "`" + ` (` + "`" + `targetKey` + "`" + `, ` + "`" + `isCurrent` + "`" + `)"
+					],
+					"listRule": null,
+					"name": "certificate_deployment",
+					"system": false,
+					"type": "base",
+					"updateRule": null,
+					"viewRule": null
+				}
+			]`
+
+			if err := app.ImportCollectionsByMarshaledJSON([]byte(jsonData), false); err != nil {
+				return err
+			}
+
+			tracer.Printf("collection 'certificate_deployment' created")
+		}
+
+		// update collection `access`
+		//   - add field `managedBy`
+		{
+			collection, err := app.FindCollectionByNameOrId("4yzbv8urny5ja1e")
+			if err != nil {
+				return err
+			}
+
+			if err := collection.Fields.AddMarshaledJSONAt(5, []byte(`{
+				"autogeneratePattern": "",
+				"hidden": false,
+				"id": "text3437695664",
+				"max": 0,
+				"min": 0,
+				"name": "managedBy",
+				"pattern": "",
+				"presentable": false,
+				"primaryKey": false,
+				"required": false,
+				"system": false,
+				"type": "text"
+			}`)); err != nil {
+				return err
+			}
+
+			if err := app.Save(collection); err != nil {
+				return err
+			}
+
+			tracer.Printf("collection '%s' updated", collection.Name)
+		}
+
+		// update collection `workflow`
+		//   - add field `managedBy`
+		//   - add field `concurrencyPolicy`
+		//   - add field `timeout`
+		{
+			collection, err := app.FindCollectionByNameOrId("tovyif5ax6j62ur")
+			if err != nil {
+				return err
+			}
+
+			if err := collection.Fields.AddMarshaledJSONAt(14, []byte(`{
+				"autogeneratePattern": "",
+				"hidden": false,
+				"id": "text3437695664",
+				"max": 0,
+				"min": 0,
+				"name": "managedBy",
+				"pattern": "",
+				"presentable": false,
+				"primaryKey": false,
+				"required": false,
+				"system": false,
+				"type": "text"
+			}`)); err != nil {
+				return err
+			}
+
+			if err := collection.Fields.AddMarshaledJSONAt(15, []byte(`{
+				"hidden": false,
+				"id": "select180804865",
+				"maxSelect": 1,
+				"name": "concurrencyPolicy",
+				"presentable": false,
+				"required": false,
+				"system": false,
+				"type": "select",
+				"values": [
+					"allow",
+					"skip",
+					"queue-one",
+					"cancel-previous"
+				]
+			}`)); err != nil {
+				return err
+			}
+
+			if err := collection.Fields.AddMarshaledJSONAt(16, []byte(`{
+				"hidden": false,
+				"id": "number2168550802",
+				"max": null,
+				"min": 0,
+				"name": "timeout",
+				"onlyInt": true,
+				"presentable": false,
+				"required": false,
+				"system": false,
+				"type": "number"
+			}`)); err != nil {
+				return err
+			}
+
+			if err := app.Save(collection); err != nil {
+				return err
+			}
+
+			tracer.Printf("collection '%s' updated", collection.Name)
+		}
+
+		// migrate sensitive fields
+		//   - encrypt field `config` of collection `access`
+		//   - encrypt field `privateKey` of collection `certificate`
+		//   - encrypt field `privateKey` of collection `acme_accounts`
+		{
+			env, err := encryption.GetEnvelope()
+			if err != nil {
+				return err
+			}
+
+			if env == nil {
+				tracer.Printf("encryption master key is not configured, skip encrypting sensitive fields")
+			} else {
+				for _, collectionName := range slices.Sorted(maps.Keys(encryption.EncryptedFields)) {
+					count, err := encryption.ReencryptCollection(app, collectionName, encryption.EncryptedFields[collectionName], env, env)
+					if err != nil {
+						return err
+					}
+
+					tracer.Printf("collection '%s' encrypted, %d record(s) affected", collectionName, count)
+				}
+			}
+		}
+
+		tracer.Printf("done")
+		return nil
+	}, func(app core.App) error {
+		return errors.ErrUnsupported
+	})
+}
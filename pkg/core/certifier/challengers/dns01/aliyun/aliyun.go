@@ -1,9 +1,14 @@
 package aliyun
 
 import (
+	"context"
 	"fmt"
 	"time"
 
+	aliopen "github.com/alibabacloud-go/darabonba-openapi/v2/client"
+	openapiutil "github.com/alibabacloud-go/darabonba-openapi/v2/utils"
+	"github.com/alibabacloud-go/tea/dara"
+	"github.com/alibabacloud-go/tea/tea"
 	"github.com/go-acme/lego/v5/providers/dns/alidns"
 
 	"github.com/certimate-go/certimate/pkg/core"
@@ -16,6 +21,12 @@ type ChallengerConfig struct {
 	DnsTTL                int    `json:"dnsTTL,omitempty"`
 }
 
+type challenger struct {
+	*alidns.DNSProvider
+
+	config *ChallengerConfig
+}
+
 func NewChallenger(config *ChallengerConfig) (core.ACMEChallenger, error) {
 	if config == nil {
 		return nil, fmt.Errorf("the configuration of the acme challenge provider is nil")
@@ -36,5 +47,46 @@ func NewChallenger(config *ChallengerConfig) (core.ACMEChallenger, error) {
 		return nil, err
 	}
 
-	return provider, nil
+	return &challenger{DNSProvider: provider, config: config}, nil
 }
+
+func (c *challenger) Validate(ctx context.Context) error {
+	client := new(aliopen.Client)
+	if err := client.Init(&aliopen.Config{
+		Endpoint:        tea.String("alidns.aliyuncs.com"),
+		AccessKeyId:     tea.String(c.config.AccessKeyId),
+		AccessKeySecret: tea.String(c.config.AccessKeySecret),
+	}); err != nil {
+		return fmt.Errorf("failed to create sdk client: %w", err)
+	}
+
+	// 仅查询一条域名记录，用于校验 AccessKey 是否有效且具备云解析 DNS 的读权限
+	// REF: https://help.aliyun.com/zh/dns/api-alidns-2015-01-09-describedomains
+	req := &openapiutil.OpenApiRequest{
+		Query: openapiutil.Query(map[string]interface{}{
+			"PageNumber": tea.Int64(1),
+			"PageSize":   tea.Int64(1),
+		}),
+	}
+	params := &openapiutil.Params{
+		Action:      dara.String("DescribeDomains"),
+		Version:     dara.String("2015-01-09"),
+		Protocol:    dara.String("HTTPS"),
+		Pathname:    dara.String("/"),
+		Method:      dara.String("POST"),
+		AuthType:    dara.String("AK"),
+		Style:       dara.String("RPC"),
+		ReqBodyType: dara.String("formData"),
+		BodyType:    dara.String("json"),
+	}
+	if _, err := client.CallApiWithCtx(ctx, params, req, &dara.RuntimeOptions{}); err != nil {
+		return fmt.Errorf("failed to execute sdk request 'alidns.DescribeDomains': %w", err)
+	}
+
+	return nil
+}
+
+var (
+	_ core.ACMEChallenger = (*challenger)(nil)
+	_ core.Validator      = (*challenger)(nil)
+)
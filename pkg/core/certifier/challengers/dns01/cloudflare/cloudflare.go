@@ -1,12 +1,14 @@
 package cloudflare
 
 import (
+	"context"
 	"fmt"
 	"time"
 
 	"github.com/go-acme/lego/v5/providers/dns/cloudflare"
 
 	"github.com/certimate-go/certimate/pkg/core"
+	cfsdk "github.com/certimate-go/certimate/pkg/sdk3rd/cloudflare"
 )
 
 type ChallengerConfig struct {
@@ -16,6 +18,12 @@ type ChallengerConfig struct {
 	DnsTTL                int    `json:"dnsTTL,omitempty"`
 }
 
+type challenger struct {
+	*cloudflare.DNSProvider
+
+	config *ChallengerConfig
+}
+
 func NewChallenger(config *ChallengerConfig) (core.ACMEChallenger, error) {
 	if config == nil {
 		return nil, fmt.Errorf("the configuration of the acme challenge provider is nil")
@@ -38,5 +46,41 @@ func NewChallenger(config *ChallengerConfig) (core.ACMEChallenger, error) {
 		return nil, err
 	}
 
-	return provider, nil
+	return &challenger{DNSProvider: provider, config: config}, nil
+}
+
+func (c *challenger) Validate(ctx context.Context) error {
+	if err := verifyApiToken(ctx, c.config.ApiToken); err != nil {
+		return err
+	}
+
+	// 配置了区域令牌时，解析记录使用 ApiToken 读写、区域使用 ApiTokenForZone 查询，因此两者均需校验
+	if c.config.ApiTokenForZone != "" {
+		if err := verifyApiToken(ctx, c.config.ApiTokenForZone); err != nil {
+			return fmt.Errorf("invalid api token for zone: %w", err)
+		}
+	}
+
+	return nil
 }
+
+func verifyApiToken(ctx context.Context, apiToken string) error {
+	client, err := cfsdk.NewClient(cfsdk.WithApiToken(apiToken))
+	if err != nil {
+		return err
+	}
+
+	verifyResp, err := client.UserTokensVerifyWithContext(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to execute sdk request 'UserTokens.Verify': %w", err)
+	} else if verifyResp.Result == nil || verifyResp.Result.Status != "active" {
+		return fmt.Errorf("the api token is not active")
+	}
+
+	return nil
+}
+
+var (
+	_ core.ACMEChallenger = (*challenger)(nil)
+	_ core.Validator      = (*challenger)(nil)
+)
@@ -0,0 +1,176 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/go-acme/lego/v5/challenge"
+	"github.com/go-acme/lego/v5/challenge/tlsalpn01"
+	"github.com/go-acme/lego/v5/log"
+
+	"github.com/certimate-go/certimate/internal/tools/ssh"
+	xssh "github.com/certimate-go/certimate/pkg/utils/ssh"
+)
+
+var _ challenge.Provider = (*TLSALPNProvider)(nil)
+
+type Config struct {
+	ssh.Config
+
+	UseSCP         bool
+	CertPath       string
+	KeyPath        string
+	PresentCommand string
+	CleanupCommand string
+}
+
+func NewDefaultConfig() *Config {
+	defaultCfg := ssh.NewDefaultConfig()
+
+	return &Config{
+		Config: *defaultCfg,
+		UseSCP: false,
+	}
+}
+
+type TLSALPNProvider struct {
+	config *Config
+}
+
+func NewTLSALPNProviderConfig(config *Config) (*TLSALPNProvider, error) {
+	if config == nil {
+		return nil, fmt.Errorf("the configuration of the acme challenge provider is nil")
+	}
+
+	if config.CertPath == "" {
+		return nil, fmt.Errorf("ssh: certificate path must be set")
+	}
+
+	if config.KeyPath == "" {
+		return nil, fmt.Errorf("ssh: private key path must be set")
+	}
+
+	return &TLSALPNProvider{
+		config: config,
+	}, nil
+}
+
+func (p *TLSALPNProvider) Present(ctx context.Context, domain, token, keyAuth string) error {
+	certPEM, keyPEM, err := tlsalpn01.ChallengeBlocks(domain, keyAuth)
+	if err != nil {
+		return fmt.Errorf("ssh: failed to generate challenge certificate: %w", err)
+	}
+
+	client, err := p.createSshClient()
+	if err != nil {
+		return fmt.Errorf("ssh: failed to create SSH client: %w", err)
+	}
+
+	log.Info("ssh: ssh connected")
+	defer func() {
+		client.Close()
+		log.Info("ssh: ssh closed")
+	}()
+
+	certPath := replacePathVariables(p.config.CertPath, domain)
+	if err := xssh.WriteRemote(client.RawClient(), certPath, certPEM, p.config.UseSCP); err != nil {
+		return fmt.Errorf("ssh: failed to write certificate file for TLS-ALPN challenge: %w", err)
+	}
+
+	keyPath := replacePathVariables(p.config.KeyPath, domain)
+	if err := xssh.WriteRemote(client.RawClient(), keyPath, keyPEM, p.config.UseSCP); err != nil {
+		return fmt.Errorf("ssh: failed to write private key file for TLS-ALPN challenge: %w", err)
+	}
+
+	log.Info("ssh: challenge certificate uploaded", slog.String("certPath", certPath), slog.String("keyPath", keyPath))
+
+	if p.config.PresentCommand != "" {
+		command := replaceCommandVariables(p.config.PresentCommand, domain, certPath, keyPath)
+		stdout, stderr, err := xssh.RunCommand(client.RawClient(), command)
+		log.Debug("ssh: present command executed", slog.String("stdout", stdout), slog.String("stderr", stderr))
+		if err != nil {
+			return fmt.Errorf("ssh: failed to execute present command: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (p *TLSALPNProvider) CleanUp(ctx context.Context, domain, token, keyAuth string) error {
+	client, err := p.createSshClient()
+	if err != nil {
+		return fmt.Errorf("ssh: failed to create SSH client: %w", err)
+	}
+
+	log.Info("ssh: ssh connected")
+	defer func() {
+		client.Close()
+		log.Info("ssh: ssh closed")
+	}()
+
+	certPath := replacePathVariables(p.config.CertPath, domain)
+	keyPath := replacePathVariables(p.config.KeyPath, domain)
+
+	if p.config.CleanupCommand != "" {
+		command := replaceCommandVariables(p.config.CleanupCommand, domain, certPath, keyPath)
+		stdout, stderr, err := xssh.RunCommand(client.RawClient(), command)
+		log.Debug("ssh: cleanup command executed", slog.String("stdout", stdout), slog.String("stderr", stderr))
+		if err != nil {
+			return fmt.Errorf("ssh: failed to execute cleanup command: %w", err)
+		}
+	}
+
+	// 删除质询证书文件
+	if err := xssh.RemoveRemote(client.RawClient(), certPath, p.config.UseSCP); err != nil {
+		return fmt.Errorf("ssh: failed to remove certificate file after TLS-ALPN challenge: %w", err)
+	}
+	if err := xssh.RemoveRemote(client.RawClient(), keyPath, p.config.UseSCP); err != nil {
+		return fmt.Errorf("ssh: failed to remove private key file after TLS-ALPN challenge: %w", err)
+	}
+
+	log.Info("ssh: challenge certificate removed", slog.String("certPath", certPath), slog.String("keyPath", keyPath))
+
+	return nil
+}
+
+func (p *TLSALPNProvider) createSshClient() (*ssh.Client, error) {
+	clientCfg := ssh.NewDefaultConfig()
+	clientCfg.Host = p.config.Host
+	clientCfg.Port = p.config.Port
+	clientCfg.AuthMethod = ssh.AuthMethodType(p.config.AuthMethod)
+	clientCfg.Username = p.config.Username
+	clientCfg.Password = p.config.Password
+	clientCfg.Key = p.config.Key
+	clientCfg.KeyPassphrase = p.config.KeyPassphrase
+	for _, jumpServer := range p.config.JumpServers {
+		jumpServerCfg := ssh.NewServerConfig()
+		jumpServerCfg.Host = jumpServer.Host
+		jumpServerCfg.Port = jumpServer.Port
+		jumpServerCfg.AuthMethod = ssh.AuthMethodType(jumpServer.AuthMethod)
+		jumpServerCfg.Username = jumpServer.Username
+		jumpServerCfg.Password = jumpServer.Password
+		jumpServerCfg.Key = jumpServer.Key
+		jumpServerCfg.KeyPassphrase = jumpServer.KeyPassphrase
+		clientCfg.JumpServers = append(clientCfg.JumpServers, *jumpServerCfg)
+	}
+
+	client, err := ssh.NewClient(clientCfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return client, nil
+}
+
+func replacePathVariables(path string, domain string) string {
+	return strings.ReplaceAll(path, "${CERTIMATE_CHALLENGER_CMDVAR_DOMAIN}", strings.ReplaceAll(domain, "*", "_"))
+}
+
+func replaceCommandVariables(command string, domain string, certPath string, keyPath string) string {
+	command = strings.ReplaceAll(command, "${CERTIMATE_CHALLENGER_CMDVAR_DOMAIN}", domain)
+	command = strings.ReplaceAll(command, "${CERTIMATE_CHALLENGER_CMDVAR_CERTIFICATE_PATH}", certPath)
+	command = strings.ReplaceAll(command, "${CERTIMATE_CHALLENGER_CMDVAR_PRIVATEKEY_PATH}", keyPath)
+	return command
+}
@@ -0,0 +1,90 @@
+package ssh
+
+import (
+	"fmt"
+
+	"github.com/certimate-go/certimate/internal/tools/ssh"
+	"github.com/certimate-go/certimate/pkg/core"
+	"github.com/certimate-go/certimate/pkg/core/certifier/challengers/tlsalpn01/ssh/internal"
+)
+
+type ServerConfig struct {
+	// SSH 主机。
+	SshHost string `json:"sshHost"`
+	// SSH 端口。
+	// 零值时默认值 22。
+	SshPort int32 `json:"sshPort,omitempty"`
+	// SSH 认证方式。
+	// 可取值 "none"、"password"、"key"。
+	// 零值时根据有无密码或私钥字段决定。
+	SshAuthMethod string `json:"sshAuthMethod,omitempty"`
+	// SSH 登录用户名。
+	// 零值时默认值 "root"。
+	SshUsername string `json:"sshUsername,omitempty"`
+	// SSH 登录密码。
+	SshPassword string `json:"sshPassword,omitempty"`
+	// SSH 登录私钥。
+	SshKey string `json:"sshKey,omitempty"`
+	// SSH 登录私钥口令。
+	SshKeyPassphrase string `json:"sshKeyPassphrase,omitempty"`
+}
+
+type ChallengerConfig struct {
+	ServerConfig
+
+	// 跳板机配置数组。
+	JumpServers []ServerConfig `json:"jumpServers,omitempty"`
+	// 是否回退使用 SCP。
+	UseSCP bool `json:"useSCP,omitempty"`
+	// 质询证书文件路径。
+	// 支持变量 "${CERTIMATE_CHALLENGER_CMDVAR_DOMAIN}"，将被替换为待验证的域名。
+	CertPath string `json:"certPath"`
+	// 质询证书私钥文件路径。
+	// 支持变量 "${CERTIMATE_CHALLENGER_CMDVAR_DOMAIN}"，将被替换为待验证的域名。
+	KeyPath string `json:"keyPath"`
+	// 上传质询证书后执行的命令，通常用于使 ALPN "acme-tls/1" 应答生效（如重载 Web 服务器）。
+	// 支持变量 "${CERTIMATE_CHALLENGER_CMDVAR_DOMAIN}"、"${CERTIMATE_CHALLENGER_CMDVAR_CERTIFICATE_PATH}"、"${CERTIMATE_CHALLENGER_CMDVAR_PRIVATEKEY_PATH}"。
+	PresentCommand string `json:"presentCommand,omitempty"`
+	// 质询完成后执行的命令，通常用于恢复原有配置。
+	// 支持的变量同 [ChallengerConfig.PresentCommand]。
+	CleanupCommand string `json:"cleanupCommand,omitempty"`
+}
+
+func NewChallenger(config *ChallengerConfig) (core.ACMEChallenger, error) {
+	if config == nil {
+		return nil, fmt.Errorf("the configuration of the acme challenge provider is nil")
+	}
+
+	providerConfig := internal.NewDefaultConfig()
+	providerConfig.Host = config.SshHost
+	providerConfig.Port = int(config.SshPort)
+	providerConfig.AuthMethod = ssh.AuthMethodType(config.SshAuthMethod)
+	providerConfig.Username = config.SshUsername
+	providerConfig.Password = config.SshPassword
+	providerConfig.Key = config.SshKey
+	providerConfig.KeyPassphrase = config.SshKeyPassphrase
+	for _, jumpServer := range config.JumpServers {
+		jumpServerCfg := ssh.ServerConfig{
+			Host:          jumpServer.SshHost,
+			Port:          int(jumpServer.SshPort),
+			AuthMethod:    ssh.AuthMethodType(jumpServer.SshAuthMethod),
+			Username:      jumpServer.SshUsername,
+			Password:      jumpServer.SshPassword,
+			Key:           jumpServer.SshKey,
+			KeyPassphrase: jumpServer.SshKeyPassphrase,
+		}
+		providerConfig.JumpServers = append(providerConfig.JumpServers, jumpServerCfg)
+	}
+	providerConfig.UseSCP = config.UseSCP
+	providerConfig.CertPath = config.CertPath
+	providerConfig.KeyPath = config.KeyPath
+	providerConfig.PresentCommand = config.PresentCommand
+	providerConfig.CleanupCommand = config.CleanupCommand
+
+	provider, err := internal.NewTLSALPNProviderConfig(providerConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return provider, nil
+}
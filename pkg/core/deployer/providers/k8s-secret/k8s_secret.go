@@ -52,7 +52,10 @@ type Deployer struct {
 	logger *slog.Logger
 }
 
-var _ Provider = (*Deployer)(nil)
+var (
+	_ Provider       = (*Deployer)(nil)
+	_ core.Validator = (*Deployer)(nil)
+)
 
 func NewDeployer(config *DeployerConfig) (*Deployer, error) {
 	if config == nil {
@@ -206,6 +209,40 @@ func (d *Deployer) Deploy(ctx context.Context, certPEM, privkeyPEM string) (*Dep
 	return &DeployResult{}, nil
 }
 
+func (d *Deployer) Validate(ctx context.Context) error {
+	if d.config.Namespace == "" {
+		return fmt.Errorf("config `namespace` is required")
+	}
+	if d.config.SecretName == "" {
+		return fmt.Errorf("config `secretName` is required")
+	}
+
+	// 连接到 Kubernetes
+	client, err := createK8sClient(d.config.KubeConfig)
+	if err != nil {
+		return fmt.Errorf("failed to create kubernetes client: %w", err)
+	}
+
+	// 获取 Secret 实例，以校验访问权限
+	// Secret 不存在时部署会自动创建，因此不视为错误
+	secretGetResp := client.Get().
+		Namespace(d.config.Namespace).
+		Resource("secrets").
+		Name(d.config.SecretName).
+		VersionedParams(&meta.GetOptions{}, meta.ParameterCodec).
+		Do(ctx)
+	d.logger.Debug("kubernetes operate 'Secrets.Get'", slog.String("namespace", d.config.Namespace), slog.Any("secret", d.config.SecretName))
+	if err := secretGetResp.Error(); err != nil {
+		if !k8serrs.IsNotFound(err) {
+			return fmt.Errorf("failed to get kubernetes secret: %w", err)
+		}
+
+		d.logger.Info("kubernetes secret not found, it will be created on deployment", slog.String("namespace", d.config.Namespace), slog.Any("secret", d.config.SecretName))
+	}
+
+	return nil
+}
+
 func createK8sClient(kubeConfig string) (*rest.RESTClient, error) {
 	var config *rest.Config
 	var err error
@@ -84,7 +84,10 @@ type Deployer struct {
 	logger *slog.Logger
 }
 
-var _ Provider = (*Deployer)(nil)
+var (
+	_ Provider       = (*Deployer)(nil)
+	_ core.Validator = (*Deployer)(nil)
+)
 
 func NewDeployer(config *DeployerConfig) (*Deployer, error) {
 	if config == nil {
@@ -112,8 +115,8 @@ func (d *Deployer) Deploy(ctx context.Context, certPEM, privkeyPEM string) (*Dep
 		return nil, fmt.Errorf("failed to extract certs: %w", err)
 	}
 
-	// 连接到 SSH
-	sshClient, err := createSshClient(*d.config)
+	// 连接到 SSH，上下文取消（如节点执行超时）时将断开连接
+	sshClient, err := createSshClient(ctx, *d.config)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create SSH client: %w", err)
 	}
@@ -249,7 +252,19 @@ func (d *Deployer) Deploy(ctx context.Context, certPEM, privkeyPEM string) (*Dep
 	return &DeployResult{}, nil
 }
 
-func createSshClient(config DeployerConfig) (*ssh.Client, error) {
+func (d *Deployer) Validate(ctx context.Context) error {
+	// 仅尝试连接到 SSH，以校验服务器地址及登录凭据
+	sshClient, err := createSshClient(ctx, *d.config)
+	if err != nil {
+		return fmt.Errorf("failed to create SSH client: %w", err)
+	}
+	defer sshClient.Close()
+	d.logger.Info("ssh connected")
+
+	return nil
+}
+
+func createSshClient(ctx context.Context, config DeployerConfig) (*ssh.Client, error) {
 	clientCfg := ssh.NewDefaultConfig()
 	clientCfg.Host = config.SshHost
 	clientCfg.Port = int(config.SshPort)
@@ -270,7 +285,7 @@ func createSshClient(config DeployerConfig) (*ssh.Client, error) {
 		clientCfg.JumpServers = append(clientCfg.JumpServers, *jumpServerCfg)
 	}
 
-	client, err := ssh.NewClient(clientCfg)
+	client, err := ssh.NewClientWithContext(ctx, clientCfg)
 	if err != nil {
 		return nil, err
 	}
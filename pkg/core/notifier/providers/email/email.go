@@ -47,7 +47,10 @@ type Notifier struct {
 	logger *slog.Logger
 }
 
-var _ Provider = (*Notifier)(nil)
+var (
+	_ Provider       = (*Notifier)(nil)
+	_ core.Validator = (*Notifier)(nil)
+)
 
 func NewNotifier(config *NotifierConfig) (*Notifier, error) {
 	if config == nil {
@@ -69,14 +72,7 @@ func (n *Notifier) SetLogger(logger *slog.Logger) {
 }
 
 func (n *Notifier) Notify(ctx context.Context, subject string, message string) (*NotifyResult, error) {
-	clientCfg := smtp.NewDefaultConfig()
-	clientCfg.Host = n.config.SmtpHost
-	clientCfg.Port = int(n.config.SmtpPort)
-	clientCfg.Username = n.config.Username
-	clientCfg.Password = n.config.Password
-	clientCfg.UseSsl = n.config.SmtpTls
-	clientCfg.SkipTlsVerify = n.config.AllowInsecureConnections
-	client, err := smtp.NewClient(clientCfg)
+	client, err := createSmtpClient(*n.config)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create SMTP client: %w", err)
 	}
@@ -108,3 +104,28 @@ func (n *Notifier) Notify(ctx context.Context, subject string, message string) (
 
 	return &NotifyResult{}, nil
 }
+
+func (n *Notifier) Validate(ctx context.Context) error {
+	// 仅尝试连接到 SMTP 服务器并登录，以校验服务器地址及登录凭据
+	client, err := createSmtpClient(*n.config)
+	if err != nil {
+		return fmt.Errorf("failed to create SMTP client: %w", err)
+	}
+
+	if err := client.Verify(ctx); err != nil {
+		return fmt.Errorf("failed to connect to SMTP server: %w", err)
+	}
+
+	return nil
+}
+
+func createSmtpClient(config NotifierConfig) (*smtp.Client, error) {
+	clientCfg := smtp.NewDefaultConfig()
+	clientCfg.Host = config.SmtpHost
+	clientCfg.Port = int(config.SmtpPort)
+	clientCfg.Username = config.Username
+	clientCfg.Password = config.Password
+	clientCfg.UseSsl = config.SmtpTls
+	clientCfg.SkipTlsVerify = config.AllowInsecureConnections
+	return smtp.NewClient(clientCfg)
+}
@@ -29,7 +29,10 @@ type Notifier struct {
 	httpClient *resty.Client
 }
 
-var _ Provider = (*Notifier)(nil)
+var (
+	_ Provider       = (*Notifier)(nil)
+	_ core.Validator = (*Notifier)(nil)
+)
 
 func NewNotifier(config *NotifierConfig) (*Notifier, error) {
 	if config == nil {
@@ -74,3 +77,25 @@ func (n *Notifier) Notify(ctx context.Context, subject string, message string) (
 
 	return &NotifyResult{}, nil
 }
+
+func (n *Notifier) Validate(ctx context.Context) error {
+	// REF: https://docs.slack.dev/reference/methods/auth.test
+	// 凭据无效时 Slack 仍会返回 200 状态码，需检查响应中的 "ok" 字段
+	var result struct {
+		Ok    bool   `json:"ok"`
+		Error string `json:"error"`
+	}
+	req := n.httpClient.R().
+		SetContext(ctx).
+		SetResult(&result)
+	resp, err := req.Post("https://slack.com/api/auth.test")
+	if err != nil {
+		return fmt.Errorf("slack api error: failed to send request: %w", err)
+	} else if resp.IsError() {
+		return fmt.Errorf("slack api error: unexpected status code: %d (resp: %s)", resp.StatusCode(), resp.String())
+	} else if !result.Ok {
+		return fmt.Errorf("slack api error: %s", result.Error)
+	}
+
+	return nil
+}
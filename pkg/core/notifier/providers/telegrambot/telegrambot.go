@@ -29,7 +29,10 @@ type Notifier struct {
 	httpClient *resty.Client
 }
 
-var _ Provider = (*Notifier)(nil)
+var (
+	_ Provider       = (*Notifier)(nil)
+	_ core.Validator = (*Notifier)(nil)
+)
 
 func NewNotifier(config *NotifierConfig) (*Notifier, error) {
 	if config == nil {
@@ -72,3 +75,17 @@ func (n *Notifier) Notify(ctx context.Context, subject string, message string) (
 
 	return &NotifyResult{}, nil
 }
+
+func (n *Notifier) Validate(ctx context.Context) error {
+	// REF: https://core.telegram.org/bots/api#getme
+	req := n.httpClient.R().
+		SetContext(ctx)
+	resp, err := req.Get(fmt.Sprintf("https://api.telegram.org/bot%s/getMe", n.config.BotToken))
+	if err != nil {
+		return fmt.Errorf("telegram api error: failed to send request: %w", err)
+	} else if resp.IsError() {
+		return fmt.Errorf("telegram api error: unexpected status code: %d (resp: %s)", resp.StatusCode(), resp.String())
+	}
+
+	return nil
+}
@@ -1,9 +1,25 @@
 ﻿package core
 
 import (
+	"context"
 	"log/slog"
 )
 
 type LoggerSetter interface {
 	SetLogger(logger *slog.Logger)
 }
+
+// 表示可校验授权凭据的提供商的抽象类型接口。
+// 这是一个可选接口，可与 [Deployer]、[Certmgr]、[Notifier]、[ACMEChallenger] 组合实现。
+// 部署器实现时还可校验部署目标（如目标资源是否存在），用于试运行时预检部署。
+type Validator interface {
+	// 校验授权凭据是否有效。
+	// 实现时应仅调用开销较低的只读接口，不得产生任何变更。
+	//
+	// 入参：
+	//   - ctx：上下文。
+	//
+	// 出参：
+	//   - err: 错误。
+	Validate(ctx context.Context) (_err error)
+}
@@ -0,0 +1,32 @@
+package cloudflare
+
+import (
+	"context"
+	"net/http"
+)
+
+type UserTokensVerifyResponse struct {
+	sdkResponseBase
+
+	Result *UserToken `json:"result,omitempty"`
+}
+
+func (c *Client) UserTokensVerify() (*UserTokensVerifyResponse, error) {
+	return c.UserTokensVerifyWithContext(context.Background())
+}
+
+func (c *Client) UserTokensVerifyWithContext(ctx context.Context) (*UserTokensVerifyResponse, error) {
+	httpreq, err := c.newRequest(http.MethodGet, "/user/tokens/verify")
+	if err != nil {
+		return nil, err
+	} else {
+		httpreq.SetContext(ctx)
+	}
+
+	result := &UserTokensVerifyResponse{}
+	if _, err := c.doRequestWithResult(httpreq, result); err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
@@ -20,3 +20,10 @@ type CustomCertificate struct {
 	UploadedOn         string           `json:"uploaded_on"`
 	ModifiedOn         string           `json:"modified_on"`
 }
+
+type UserToken struct {
+	ID        string `json:"id"`
+	Status    string `json:"status"`
+	NotBefore string `json:"not_before"`
+	ExpiresOn string `json:"expires_on"`
+}
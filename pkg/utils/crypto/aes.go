@@ -12,6 +12,8 @@ import (
 type AESCryptor interface {
 	CBCEncrypt(data []byte) ([]byte, error)
 	CBCDecrypt(cipher []byte) ([]byte, error)
+	GCMEncrypt(data []byte) ([]byte, error)
+	GCMDecrypt(cipher []byte) ([]byte, error)
 }
 
 type aesCryptor struct {
@@ -60,6 +62,46 @@ func (c *aesCryptor) CBCDecrypt(ciphertext []byte) ([]byte, error) {
 	return c.pkcs7Unpadding(ciphertext), nil
 }
 
+func (c *aesCryptor) GCMEncrypt(data []byte) ([]byte, error) {
+	block, err := aes.NewCipher(c.key)
+	if err != nil {
+		return nil, err
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	return gcm.Seal(nonce, nonce, data, nil), nil
+}
+
+func (c *aesCryptor) GCMDecrypt(ciphertext []byte) ([]byte, error) {
+	block, err := aes.NewCipher(c.key)
+	if err != nil {
+		return nil, err
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ciphertext) < gcm.NonceSize() {
+		return nil, fmt.Errorf("ciphertext too short")
+	}
+
+	nonce := ciphertext[:gcm.NonceSize()]
+	ciphertext = ciphertext[gcm.NonceSize():]
+
+	return gcm.Open(nil, nonce, ciphertext, nil)
+}
+
 func (c *aesCryptor) pkcs7Padding(data []byte, blockSize int) []byte {
 	padding := blockSize - (len(data) % blockSize)
 	padText := bytes.Repeat([]byte{byte(padding)}, padding)
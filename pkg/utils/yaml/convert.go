@@ -0,0 +1,64 @@
+package yaml
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"gopkg.in/yaml.v3"
+)
+
+// 将 JSON 格式的数据转换为 YAML 格式。
+// 与先反序列化再序列化的方式不同，该函数会保留原始数据中键的顺序。
+//
+// 入参：
+//   - data: JSON 格式的数据。
+//
+// 出参：
+//   - YAML 格式的数据。
+//   - 错误。
+func FromJSON(data []byte) ([]byte, error) {
+	// JSON 是 YAML 的子集，可直接解析为 YAML 节点树
+	node := &yaml.Node{}
+	if err := yaml.Unmarshal(data, node); err != nil {
+		return nil, err
+	}
+
+	// 清除 JSON 的流式风格和引号风格，以输出块式风格的 YAML
+	var resetStyle func(n *yaml.Node)
+	resetStyle = func(n *yaml.Node) {
+		n.Style = 0
+		for _, child := range n.Content {
+			resetStyle(child)
+		}
+	}
+	resetStyle(node)
+
+	buf := &bytes.Buffer{}
+	encoder := yaml.NewEncoder(buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(node); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// 将 YAML 格式的数据转换为 JSON 格式。
+//
+// 入参：
+//   - data: YAML 格式的数据。
+//
+// 出参：
+//   - JSON 格式的数据。
+//   - 错误。
+func ToJSON(data []byte) ([]byte, error) {
+	var temp any
+	if err := yaml.Unmarshal(data, &temp); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(temp)
+}
@@ -0,0 +1,40 @@
+package yaml_test
+
+import (
+	"testing"
+
+	xyaml "github.com/certimate-go/certimate/pkg/utils/yaml"
+)
+
+func TestConvert(t *testing.T) {
+	t.Run("FromJSON", func(t *testing.T) {
+		data, err := xyaml.FromJSON([]byte(`{"version":"v1","name":"true","graph":{"nodes":[{"id":"a","data":{"wait":1}}]},"tags":[]}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expected := "version: v1\n" +
+			"name: \"true\"\n" +
+			"graph:\n" +
+			"  nodes:\n" +
+			"    - id: a\n" +
+			"      data:\n" +
+			"        wait: 1\n" +
+			"tags: []\n"
+		if string(data) != expected {
+			t.Errorf("expected:\n%s\nactual:\n%s", expected, string(data))
+		}
+	})
+
+	t.Run("ToJSON", func(t *testing.T) {
+		data, err := xyaml.ToJSON([]byte("name: \"true\"\nenabled: true\nnodes:\n  - id: a\n"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expected := `{"enabled":true,"name":"true","nodes":[{"id":"a"}]}`
+		if string(data) != expected {
+			t.Errorf("expected: %s, actual: %s", expected, string(data))
+		}
+	})
+}
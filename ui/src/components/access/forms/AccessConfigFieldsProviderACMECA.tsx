@@ -27,6 +27,16 @@ const AccessConfigFormFieldsProviderACMECA = () => {
         <Input placeholder={t("access.form.acmeca_endpoint.placeholder")} />
       </Form.Item>
 
+      <Form.Item
+        name={[parentNamePath, "stagingEndpoint"]}
+        initialValue={initialValues.stagingEndpoint}
+        label={t("access.form.acmeca_staging_endpoint.label")}
+        rules={[formRule]}
+        tooltip={<span dangerouslySetInnerHTML={{ __html: t("access.form.acmeca_staging_endpoint.tooltip") }}></span>}
+      >
+        <Input allowClear placeholder={t("access.form.acmeca_staging_endpoint.placeholder")} />
+      </Form.Item>
+
       <Form.Item name={[parentNamePath, "eabKid"]} initialValue={initialValues.eabKid} label={t("access.form.acmeca_eab_kid.label")} rules={[formRule]}>
         <Input allowClear autoComplete="new-password" placeholder={t("access.form.acmeca_eab_kid.placeholder")} />
       </Form.Item>
@@ -54,6 +64,7 @@ const getSchema = ({ i18n = getI18n() }: { i18n: ReturnType<typeof getI18n> }) =
 
   return z.object({
     endpoint: z.url({ protocol: z.core.regexes.httpProtocol }),
+    stagingEndpoint: z.url({ protocol: z.core.regexes.httpProtocol }).or(z.literal("")).nullish(),
     eabKid: z.string().nullish(),
     eabHmacKey: z.string().nullish(),
   });